@@ -0,0 +1,204 @@
+// Package api serves the user and workout operations of a flexcreek store as a versioned JSON API
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/auth"
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+// page size used when a list request doesn't ask for one, and the most a request can ask for
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// defining the store operations the api requires
+type UserStore interface {
+	CreateUser(ctx context.Context, username string) (int, error)
+	GetUserByID(ctx context.Context, id int) (*flexcreek.User, error)
+	GetAllUsers(ctx context.Context) ([]*flexcreek.User, error)
+	DeleteUser(ctx context.Context, id int) error
+}
+
+type WorkoutStore interface {
+	CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error)
+	GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error)
+	ListWorkouts(ctx context.Context, f flexcreek.WorkoutFilter, userID int) ([]*flexcreek.Workout, error)
+	UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error
+	DeleteWorkout(ctx context.Context, id int, userID int) error
+}
+
+type Store interface {
+	UserStore
+	WorkoutStore
+}
+
+// operations needed to authenticate requests and issue tokens
+type AuthStore interface {
+	auth.TokenStore
+	GetUserByUsername(ctx context.Context, username string) (*flexcreek.User, error)
+	GetPasswordHash(ctx context.Context, id int) (string, error)
+	CreateAPIToken(ctx context.Context, t *flexcreek.APIToken) (int, error)
+}
+
+// Server is an http.Handler exposing the /api/v1 routes
+type Server struct {
+	store     Store
+	authStore AuthStore // nil when authentication is disabled
+	mux       *http.ServeMux
+}
+
+// NewServer builds the api over s. when a is non-nil every route except token issuance and the
+// OpenAPI document requires a bearer token, and the acting user comes from the token
+func NewServer(s Store, a AuthStore) *Server {
+	srv := &Server{
+		store:     s,
+		authStore: a,
+		mux:       http.NewServeMux(),
+	}
+	srv.routes()
+
+	return srv
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/v1/openapi.json", s.handleOpenAPI)
+
+	s.handle("GET /api/v1/users", auth.ScopeAdmin, s.handleListUsers)
+	s.handle("POST /api/v1/users", auth.ScopeAdmin, s.handleCreateUser)
+	s.handle("GET /api/v1/users/{userID}", auth.ScopeWorkoutsRead, s.handleGetUser)
+	s.handle("DELETE /api/v1/users/{userID}", auth.ScopeAdmin, s.handleDeleteUser)
+
+	s.handle("GET /api/v1/users/{userID}/workouts", auth.ScopeWorkoutsRead, s.handleListWorkouts)
+	s.handle("POST /api/v1/users/{userID}/workouts", auth.ScopeWorkoutsWrite, s.handleCreateWorkout)
+	s.handle("GET /api/v1/users/{userID}/workouts/{workoutID}", auth.ScopeWorkoutsRead, s.handleGetWorkout)
+	s.handle("PUT /api/v1/users/{userID}/workouts/{workoutID}", auth.ScopeWorkoutsWrite, s.handleUpdateWorkout)
+	s.handle("DELETE /api/v1/users/{userID}/workouts/{workoutID}", auth.ScopeWorkoutsWrite, s.handleDeleteWorkout)
+
+	if s.authStore == nil {
+		return
+	}
+
+	s.mux.HandleFunc("POST /api/v1/tokens", s.handleCreateToken)
+
+	//the same operations for the token's own user, without repeating the ID in the path
+	s.handle("GET /api/v1/me", auth.ScopeWorkoutsRead, s.handleGetUser)
+	s.handle("GET /api/v1/me/workouts", auth.ScopeWorkoutsRead, s.handleListWorkouts)
+	s.handle("POST /api/v1/me/workouts", auth.ScopeWorkoutsWrite, s.handleCreateWorkout)
+	s.handle("GET /api/v1/me/workouts/{workoutID}", auth.ScopeWorkoutsRead, s.handleGetWorkout)
+	s.handle("PUT /api/v1/me/workouts/{workoutID}", auth.ScopeWorkoutsWrite, s.handleUpdateWorkout)
+	s.handle("DELETE /api/v1/me/workouts/{workoutID}", auth.ScopeWorkoutsWrite, s.handleDeleteWorkout)
+}
+
+// registers a route, wrapping it in authentication and a scope check when auth is enabled
+func (s *Server) handle(pattern string, scope string, h http.HandlerFunc) {
+	if s.authStore == nil {
+		s.mux.HandleFunc(pattern, h)
+		return
+	}
+
+	s.mux.Handle(pattern, auth.Middleware(s.authStore)(auth.RequireScope(scope, h)))
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocument)
+}
+
+// response helpers
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api: encoding response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
+
+// maps storage errors onto status codes, hiding anything unexpected behind a 500
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, flexcreek.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, flexcreek.ErrConflict):
+		writeError(w, http.StatusConflict, "already exists")
+	default:
+		log.Printf("api: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// request helpers
+
+// parses a positive integer path parameter, writing a 400 if it's invalid
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid "+name)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// resolves the user a request acts on. with auth enabled that's the token's owner: /me routes use it
+// directly, and a user ID in the path has to match it unless the token has the admin scope
+func (s *Server) userID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	p, authenticated := auth.PrincipalFrom(r.Context())
+	if !authenticated {
+		return pathID(w, r, "userID")
+	}
+
+	if r.PathValue("userID") == "" {
+		return p.UserID, true
+	}
+
+	id, ok := pathID(w, r, "userID")
+	if !ok {
+		return 0, false
+	}
+
+	if id != p.UserID && !p.HasScope(auth.ScopeAdmin) {
+		writeError(w, http.StatusForbidden, "token can't access other users")
+		return 0, false
+	}
+
+	return id, true
+}
+
+// decodes a json request body, writing a 400 if it's malformed
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ekholme/flexcreek/sqlite"
+)
+
+// a migrated sqlite database of its own for each test
+func newTestStorage(t *testing.T) *sqlite.Storage {
+	t.Helper()
+
+	s, err := sqlite.Open(filepath.Join(t.TempDir(), "flexcreek.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// sends a request to h, with body as json when it isn't empty and token as a bearer token when set
+func do(t *testing.T, h http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+// checks the status code and decodes the json body into v, when v isn't nil
+func expect(t *testing.T, w *httptest.ResponseRecorder, status int, v any) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status %d, want %d: %s", w.Code, status, w.Body)
+	}
+
+	if v == nil {
+		return
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type %q, want application/json", ct)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %s: %v", w.Body, err)
+	}
+}
+
+// checks for an error response carrying msg
+func expectError(t *testing.T, w *httptest.ResponseRecorder, status int, msg string) {
+	t.Helper()
+
+	var res errorResponse
+	expect(t, w, status, &res)
+	if !strings.Contains(res.Error, msg) {
+		t.Errorf("error %q, want it to mention %q", res.Error, msg)
+	}
+}
+
+// creates a user over the api and returns its id
+func createUser(t *testing.T, h http.Handler, username string) int {
+	t.Helper()
+
+	var u userResponse
+	expect(t, do(t, h, "POST", "/api/v1/users", `{"username": "`+username+`"}`, ""), http.StatusCreated, &u)
+
+	return u.ID
+}
+
+// creates a workout over the api and returns it
+func createWorkout(t *testing.T, h http.Handler, userPath, body string) workoutResponse {
+	t.Helper()
+
+	var wo workoutResponse
+	expect(t, do(t, h, "POST", userPath+"/workouts", body, ""), http.StatusCreated, &wo)
+
+	return wo
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	srv := NewServer(newTestStorage(t), nil)
+
+	var doc map[string]any
+	expect(t, do(t, srv, "GET", "/api/v1/openapi.json", "", ""), http.StatusOK, &doc)
+	if doc["openapi"] == nil || doc["paths"] == nil {
+		t.Errorf("the document has no openapi version or paths: %v", doc)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	srv := NewServer(newTestStorage(t), nil)
+
+	var u userResponse
+	expect(t, do(t, srv, "POST", "/api/v1/users", `{"username": " alice "}`, ""), http.StatusCreated, &u)
+	if u.ID == 0 || u.UUID == "" || u.Username != "alice" || u.CreatedAt.IsZero() {
+		t.Errorf("created %+v, want alice with an id, uuid and created time", u)
+	}
+
+	expectError(t, do(t, srv, "POST", "/api/v1/users", `{"username": "alice"}`, ""), http.StatusConflict, "already exists")
+	expectError(t, do(t, srv, "POST", "/api/v1/users", `{"username": "  "}`, ""), http.StatusBadRequest, "username is required")
+	expectError(t, do(t, srv, "POST", "/api/v1/users", `{"name": "bob"}`, ""), http.StatusBadRequest, "invalid request body")
+	expectError(t, do(t, srv, "POST", "/api/v1/users", `{"username":`, ""), http.StatusBadRequest, "invalid request body")
+	bob := createUser(t, srv, "bob")
+
+	var list struct {
+		Users []userResponse `json:"users"`
+	}
+	expect(t, do(t, srv, "GET", "/api/v1/users", "", ""), http.StatusOK, &list)
+	if len(list.Users) != 2 || list.Users[0].Username != "alice" || list.Users[1].Username != "bob" {
+		t.Errorf("listed %+v, want alice and bob", list.Users)
+	}
+
+	var got userResponse
+	expect(t, do(t, srv, "GET", "/api/v1/users/"+itoa(u.ID), "", ""), http.StatusOK, &got)
+	if got != u {
+		t.Errorf("got %+v, want %+v", got, u)
+	}
+
+	expectError(t, do(t, srv, "GET", "/api/v1/users/999", "", ""), http.StatusNotFound, "not found")
+	expectError(t, do(t, srv, "GET", "/api/v1/users/abc", "", ""), http.StatusBadRequest, "invalid userID")
+	expectError(t, do(t, srv, "GET", "/api/v1/users/0", "", ""), http.StatusBadRequest, "invalid userID")
+
+	expect(t, do(t, srv, "DELETE", "/api/v1/users/"+itoa(bob), "", ""), http.StatusNoContent, nil)
+	expectError(t, do(t, srv, "GET", "/api/v1/users/"+itoa(bob), "", ""), http.StatusNotFound, "not found")
+	expectError(t, do(t, srv, "DELETE", "/api/v1/users/"+itoa(bob), "", ""), http.StatusNotFound, "not found")
+}
+
+func TestWorkouts(t *testing.T) {
+	srv := NewServer(newTestStorage(t), nil)
+	alice := "/api/v1/users/" + itoa(createUser(t, srv, "alice"))
+	bob := "/api/v1/users/" + itoa(createUser(t, srv, "bob"))
+
+	w := do(t, srv, "POST", alice+"/workouts", `{
+		"short_description": " KB ABC ",
+		"long_description": "5 rounds",
+		"workout_date": "2026-03-01",
+		"start_time": "2026-03-01T07:30:00-05:00",
+		"duration_minutes": 40,
+		"rpe": 8,
+		"session_load": 320
+	}`, "")
+	var wo workoutResponse
+	expect(t, w, http.StatusCreated, &wo)
+	if loc := w.Header().Get("Location"); loc != alice+"/workouts/"+itoa(wo.ID) {
+		t.Errorf("location %q, want the new workout's url", loc)
+	}
+	if wo.UUID == "" || wo.ShortDescription != "KB ABC" || wo.LongDescription != "5 rounds" || wo.WorkoutDate != "2026-03-01" ||
+		wo.DurationMinutes != 40 || wo.RPE != 8 || wo.SessionLoad != 320 || wo.CreatedAt.IsZero() {
+		t.Errorf("created %+v", wo)
+	}
+	if wo.StartTime == nil || wo.StartTime.Format("15:04 -07:00") != "07:30 -05:00" {
+		t.Errorf("start time %v, want 07:30 at -05:00", wo.StartTime)
+	}
+
+	//the json keys are part of the contract
+	var raw map[string]any
+	expect(t, do(t, srv, "GET", alice+"/workouts/"+itoa(wo.ID), "", ""), http.StatusOK, &raw)
+	for _, key := range []string{"id", "uuid", "user_id", "short_description", "long_description", "workout_date", "start_time", "duration_minutes", "rpe", "session_load", "created_at"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("the workout has no %q: %v", key, raw)
+		}
+	}
+
+	//a workout with no start time leaves it out
+	plain := createWorkout(t, srv, alice, `{"short_description": "Easy run", "workout_date": "2026-03-02"}`)
+	raw = nil
+	expect(t, do(t, srv, "GET", alice+"/workouts/"+itoa(plain.ID), "", ""), http.StatusOK, &raw)
+	if _, ok := raw["start_time"]; ok {
+		t.Errorf("a workout without a start time has one: %v", raw)
+	}
+
+	var updated workoutResponse
+	expect(t, do(t, srv, "PUT", alice+"/workouts/"+itoa(wo.ID), `{"short_description": "KB ABC heavy", "workout_date": "2026-03-03", "rpe": 9}`, ""), http.StatusOK, &updated)
+	if updated.ID != wo.ID || updated.UUID != wo.UUID || updated.ShortDescription != "KB ABC heavy" || updated.WorkoutDate != "2026-03-03" || updated.RPE != 9 || updated.StartTime != nil {
+		t.Errorf("updated to %+v", updated)
+	}
+
+	//bob's view of alice's workout is the same as a workout that doesn't exist
+	for _, path := range []string{bob + "/workouts/" + itoa(wo.ID), alice + "/workouts/999"} {
+		expectError(t, do(t, srv, "GET", path, "", ""), http.StatusNotFound, "not found")
+		expectError(t, do(t, srv, "PUT", path, `{"short_description": "mine", "workout_date": "2026-03-03"}`, ""), http.StatusNotFound, "not found")
+		expectError(t, do(t, srv, "DELETE", path, "", ""), http.StatusNotFound, "not found")
+	}
+	expectError(t, do(t, srv, "GET", alice+"/workouts/abc", "", ""), http.StatusBadRequest, "invalid workoutID")
+	expectError(t, do(t, srv, "POST", "/api/v1/users/999/workouts", `{"short_description": "Easy run", "workout_date": "2026-03-02"}`, ""), http.StatusNotFound, "not found")
+
+	expect(t, do(t, srv, "DELETE", alice+"/workouts/"+itoa(wo.ID), "", ""), http.StatusNoContent, nil)
+	expectError(t, do(t, srv, "GET", alice+"/workouts/"+itoa(wo.ID), "", ""), http.StatusNotFound, "not found")
+}
+
+func TestWorkoutValidation(t *testing.T) {
+	srv := NewServer(newTestStorage(t), nil)
+	alice := "/api/v1/users/" + itoa(createUser(t, srv, "alice"))
+	existing := createWorkout(t, srv, alice, `{"short_description": "Easy run", "workout_date": "2026-03-01"}`)
+
+	tests := []struct {
+		name string
+		body string
+		msg  string
+	}{
+		{"no description", `{"workout_date": "2026-03-01"}`, "short_description is required"},
+		{"blank description", `{"short_description": "  ", "workout_date": "2026-03-01"}`, "short_description is required"},
+		{"no date", `{"short_description": "Easy run"}`, "workout_date must be a YYYY-MM-DD date"},
+		{"us date", `{"short_description": "Easy run", "workout_date": "03/01/2026"}`, "workout_date must be a YYYY-MM-DD date"},
+		{"negative duration", `{"short_description": "Easy run", "workout_date": "2026-03-01", "duration_minutes": -1}`, "duration_minutes can't be negative"},
+		{"rpe over 10", `{"short_description": "Easy run", "workout_date": "2026-03-01", "rpe": 11}`, "rpe must be between 1 and 10"},
+		{"negative rpe", `{"short_description": "Easy run", "workout_date": "2026-03-01", "rpe": -1}`, "rpe must be between 1 and 10"},
+		{"negative load", `{"short_description": "Easy run", "workout_date": "2026-03-01", "session_load": -5}`, "session_load can't be negative"},
+		{"unknown field", `{"short_description": "Easy run", "workout_date": "2026-03-01", "distance": 5}`, "invalid request body"},
+		{"wrong type", `{"short_description": "Easy run", "workout_date": "2026-03-01", "rpe": "hard"}`, "invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectError(t, do(t, srv, "POST", alice+"/workouts", tt.body, ""), http.StatusBadRequest, tt.msg)
+			expectError(t, do(t, srv, "PUT", alice+"/workouts/"+itoa(existing.ID), tt.body, ""), http.StatusBadRequest, tt.msg)
+		})
+	}
+
+	//nothing was created or changed along the way
+	var list workoutListResponse
+	expect(t, do(t, srv, "GET", alice+"/workouts", "", ""), http.StatusOK, &list)
+	if len(list.Workouts) != 1 || list.Workouts[0] != existing {
+		t.Errorf("after the rejected requests the workouts are %+v, want just %+v", list.Workouts, existing)
+	}
+}
+
+func TestListWorkouts(t *testing.T) {
+	srv := NewServer(newTestStorage(t), nil)
+	alice := "/api/v1/users/" + itoa(createUser(t, srv, "alice"))
+	bob := "/api/v1/users/" + itoa(createUser(t, srv, "bob"))
+
+	for _, w := range []struct{ desc, date string }{
+		{"Easy run", "2026-03-01"},
+		{"KB swings", "2026-03-02"},
+		{"Long run", "2026-03-03"},
+		{"100% effort", "2026-03-04"},
+		{"Rest day walk", "2026-03-05"},
+	} {
+		createWorkout(t, srv, alice, `{"short_description": "`+w.desc+`", "workout_date": "`+w.date+`"}`)
+	}
+	createWorkout(t, srv, bob, `{"short_description": "Bob's run", "workout_date": "2026-03-03"}`)
+
+	tests := []struct {
+		query  string
+		want   []string
+		limit  int
+		offset int
+	}{
+		{"", []string{"Rest day walk", "100% effort", "Long run", "KB swings", "Easy run"}, defaultLimit, 0},
+		{"?limit=2", []string{"Rest day walk", "100% effort"}, 2, 0},
+		{"?limit=2&offset=2", []string{"Long run", "KB swings"}, 2, 2},
+		{"?limit=2&offset=4", []string{"Easy run"}, 2, 4},
+		{"?offset=10", nil, defaultLimit, 10},
+		{"?from=2026-03-02&to=2026-03-03", []string{"Long run", "KB swings"}, defaultLimit, 0},
+		{"?from=2026-03-04", []string{"Rest day walk", "100% effort"}, defaultLimit, 0},
+		{"?to=2026-03-01", []string{"Easy run"}, defaultLimit, 0},
+		{"?q=RUN", []string{"Long run", "Easy run"}, defaultLimit, 0},
+		{"?q=100%25", []string{"100% effort"}, defaultLimit, 0},
+		{"?q=%25", []string{"100% effort"}, defaultLimit, 0},
+		{"?q=_", nil, defaultLimit, 0},
+		{"?q=run&from=2026-03-02&limit=1", []string{"Long run"}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var list workoutListResponse
+			expect(t, do(t, srv, "GET", alice+"/workouts"+tt.query, "", ""), http.StatusOK, &list)
+
+			var got []string
+			for _, w := range list.Workouts {
+				got = append(got, w.ShortDescription)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("listed %v, want %v", got, tt.want)
+			}
+			if list.Limit != tt.limit || list.Offset != tt.offset {
+				t.Errorf("limit %d offset %d, want %d and %d", list.Limit, list.Offset, tt.limit, tt.offset)
+			}
+		})
+	}
+
+	//an empty page is an empty list, not null
+	w := do(t, srv, "GET", alice+"/workouts?offset=10", "", "")
+	if !strings.Contains(w.Body.String(), `"workouts":[]`) {
+		t.Errorf("an empty page is %s, want an empty workouts list", w.Body)
+	}
+
+	for query, msg := range map[string]string{
+		"?limit=0":                       "limit must be between 1 and 500",
+		"?limit=501":                     "limit must be between 1 and 500",
+		"?limit=ten":                     "limit must be between 1 and 500",
+		"?offset=-1":                     "offset must be a non-negative integer",
+		"?from=yesterday":                "from must be a YYYY-MM-DD date",
+		"?to=2026-3-1":                   "to must be a YYYY-MM-DD date",
+		"?from=2026-03-03&to=2026-03-02": "to can't be before from",
+	} {
+		expectError(t, do(t, srv, "GET", alice+"/workouts"+query, "", ""), http.StatusBadRequest, msg)
+	}
+
+	expectError(t, do(t, srv, "GET", "/api/v1/users/999/workouts", "", ""), http.StatusNotFound, "not found")
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
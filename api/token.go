@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/auth"
+)
+
+// token lifetimes for tokens issued over the api
+const (
+	defaultTokenDays = 90
+	maxTokenDays     = 365
+)
+
+type createTokenRequest struct {
+	Username      string   `json:"username"`
+	Password      string   `json:"password"`
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type tokenResponse struct {
+	ID        int       `json:"id"`
+	Token     string    `json:"token"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// exchanges a username and password for a new api token.
+// admin tokens can only be created from the command line
+func (s *Server) handleCreateToken(w http.ResponseWriter, r *http.Request) {
+	var req createTokenRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultTokenDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > maxTokenDays {
+		writeError(w, http.StatusBadRequest, "expires_in_days must be between 1 and 365")
+		return
+	}
+
+	if len(req.Scopes) == 0 {
+		req.Scopes = []string{auth.ScopeWorkoutsRead, auth.ScopeWorkoutsWrite}
+	}
+	scopes, err := auth.ParseScopes(strings.Join(req.Scopes, " "))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, sc := range scopes {
+		if sc == auth.ScopeAdmin {
+			writeError(w, http.StatusForbidden, "admin tokens can't be issued over the api")
+			return
+		}
+	}
+
+	u, err := s.authStore.GetUserByUsername(r.Context(), req.Username)
+	if errors.Is(err, flexcreek.ErrNotFound) {
+		//same response, and the same bcrypt work, as a bad password so usernames can't be probed
+		err := auth.CheckPassword("", req.Password)
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	hash, err := s.authStore.GetPasswordHash(r.Context(), u.ID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if err := auth.CheckPassword(hash, req.Password); err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	plaintext, tokenHash, err := auth.GenerateToken()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	t := flexcreek.APIToken{
+		UserID:    u.ID,
+		Name:      strings.TrimSpace(req.Name),
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		ExpiresAt: time.Now().UTC().AddDate(0, 0, req.ExpiresInDays),
+	}
+
+	id, err := s.authStore.CreateAPIToken(r.Context(), &t)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, tokenResponse{
+		ID:        id,
+		Token:     plaintext,
+		Name:      t.Name,
+		Scopes:    t.Scopes,
+		ExpiresAt: t.ExpiresAt,
+	})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/ekholme/flexcreek/auth"
+	"github.com/ekholme/flexcreek/sqlite"
+)
+
+// an authenticated server with alice, whose password is correct-horse, and bob
+func newAuthServer(t *testing.T) (srv *Server, s *sqlite.Storage, alice int, bob int) {
+	t.Helper()
+	s = newTestStorage(t)
+	ctx := context.Background()
+
+	var err error
+	if alice, err = s.CreateUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if bob, err = s.CreateUser(ctx, "bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := auth.HashPassword("correct-horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPasswordHash(ctx, alice, hash); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewServer(s, s), s, alice, bob
+}
+
+// issues alice a token with scopes over the api
+func issueToken(t *testing.T, srv *Server, scopes string) tokenResponse {
+	t.Helper()
+
+	var tok tokenResponse
+	body := `{"username": "alice", "password": "correct-horse", "name": "laptop", "scopes": ` + scopes + `}`
+	expect(t, do(t, srv, "POST", "/api/v1/tokens", body, ""), http.StatusCreated, &tok)
+
+	return tok
+}
+
+func TestCreateToken(t *testing.T) {
+	srv, s, alice, _ := newAuthServer(t)
+
+	tok := issueToken(t, srv, `[]`)
+	if tok.ID == 0 || tok.Name != "laptop" || tok.ExpiresAt.IsZero() {
+		t.Errorf("issued %+v", tok)
+	}
+	if len(tok.Scopes) != 2 || tok.Scopes[0] != auth.ScopeWorkoutsRead || tok.Scopes[1] != auth.ScopeWorkoutsWrite {
+		t.Errorf("a token asked for with no scopes has %v, want read and write", tok.Scopes)
+	}
+
+	//only the hash is kept
+	stored, err := s.GetAPITokenByHash(context.Background(), auth.HashToken(tok.Token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ID != tok.ID || stored.UserID != alice || stored.TokenHash == tok.Token {
+		t.Errorf("stored %+v for the issued token", stored)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		msg    string
+	}{
+		{"wrong password", `{"username": "alice", "password": "wrong-horse", "name": "laptop"}`, http.StatusUnauthorized, "invalid"},
+		{"unknown user", `{"username": "mallory", "password": "correct-horse", "name": "laptop"}`, http.StatusUnauthorized, "invalid"},
+		{"no password set", `{"username": "bob", "password": "", "name": "laptop"}`, http.StatusUnauthorized, "invalid"},
+		{"no name", `{"username": "alice", "password": "correct-horse"}`, http.StatusBadRequest, "name is required"},
+		{"too long", `{"username": "alice", "password": "correct-horse", "name": "laptop", "expires_in_days": 366}`, http.StatusBadRequest, "expires_in_days must be between 1 and 365"},
+		{"unknown scope", `{"username": "alice", "password": "correct-horse", "name": "laptop", "scopes": ["root"]}`, http.StatusBadRequest, "unknown scope"},
+		{"admin", `{"username": "alice", "password": "correct-horse", "name": "laptop", "scopes": ["admin"]}`, http.StatusForbidden, "admin tokens can't be issued"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectError(t, do(t, srv, "POST", "/api/v1/tokens", tt.body, ""), tt.status, tt.msg)
+		})
+	}
+}
+
+func TestTokenRoutes(t *testing.T) {
+	srv, _, alice, bob := newAuthServer(t)
+	rw := issueToken(t, srv, `["workouts:read", "workouts:write"]`).Token
+	ro := issueToken(t, srv, `["workouts:read"]`).Token
+
+	expectError(t, do(t, srv, "GET", "/api/v1/me", "", ""), http.StatusUnauthorized, "missing bearer token")
+
+	var me userResponse
+	expect(t, do(t, srv, "GET", "/api/v1/me", "", ro), http.StatusOK, &me)
+	if me.ID != alice || me.Username != "alice" {
+		t.Errorf("/me is %+v, want alice", me)
+	}
+
+	var wo workoutResponse
+	expect(t, do(t, srv, "POST", "/api/v1/me/workouts", `{"short_description": "Easy run", "workout_date": "2026-03-01"}`, rw), http.StatusCreated, &wo)
+	if wo.UserID != alice {
+		t.Errorf("a workout posted to /me belongs to %d, want alice", wo.UserID)
+	}
+
+	var list workoutListResponse
+	expect(t, do(t, srv, "GET", "/api/v1/me/workouts", "", ro), http.StatusOK, &list)
+	if len(list.Workouts) != 1 || list.Workouts[0].ID != wo.ID {
+		t.Errorf("alice's workouts are %+v, want the one posted", list.Workouts)
+	}
+	expect(t, do(t, srv, "GET", "/api/v1/me/workouts/"+itoa(wo.ID), "", ro), http.StatusOK, nil)
+	expect(t, do(t, srv, "GET", "/api/v1/users/"+itoa(alice)+"/workouts/"+itoa(wo.ID), "", ro), http.StatusOK, nil)
+
+	//a read only token can't write, and no token reaches another user or the admin routes
+	expectError(t, do(t, srv, "PUT", "/api/v1/me/workouts/"+itoa(wo.ID), `{"short_description": "Long run", "workout_date": "2026-03-01"}`, ro), http.StatusForbidden, "workouts:write")
+	expectError(t, do(t, srv, "DELETE", "/api/v1/me/workouts/"+itoa(wo.ID), "", ro), http.StatusForbidden, "workouts:write")
+	expectError(t, do(t, srv, "GET", "/api/v1/users/"+itoa(bob), "", rw), http.StatusForbidden, "other users")
+	expectError(t, do(t, srv, "GET", "/api/v1/users/"+itoa(bob)+"/workouts", "", rw), http.StatusForbidden, "other users")
+	expectError(t, do(t, srv, "GET", "/api/v1/users", "", rw), http.StatusForbidden, "admin")
+	expectError(t, do(t, srv, "POST", "/api/v1/users", `{"username": "mallory"}`, rw), http.StatusForbidden, "admin")
+
+	expect(t, do(t, srv, "PUT", "/api/v1/me/workouts/"+itoa(wo.ID), `{"short_description": "Long run", "workout_date": "2026-03-01"}`, rw), http.StatusOK, nil)
+	expect(t, do(t, srv, "DELETE", "/api/v1/me/workouts/"+itoa(wo.ID), "", rw), http.StatusNoContent, nil)
+	expectError(t, do(t, srv, "GET", "/api/v1/me/workouts/"+itoa(wo.ID), "", ro), http.StatusNotFound, "not found")
+
+	//the document describing the api needs no token
+	expect(t, do(t, srv, "GET", "/api/v1/openapi.json", "", ""), http.StatusOK, nil)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+type userResponse struct {
+	ID        int       `json:"id"`
+	UUID      string    `json:"uuid"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createUserRequest struct {
+	Username string `json:"username"`
+}
+
+func newUserResponse(u *flexcreek.User) userResponse {
+	return userResponse{
+		ID:        u.ID,
+		UUID:      u.UUID,
+		Username:  u.Username,
+		CreatedAt: u.CreatedAt,
+	}
+}
+
+func (s *Server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.store.GetAllUsers(r.Context())
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	resp := make([]userResponse, len(users))
+	for i, u := range users {
+		resp[i] = newUserResponse(u)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"users": resp})
+}
+
+func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" {
+		writeError(w, http.StatusBadRequest, "username is required")
+		return
+	}
+
+	id, err := s.store.CreateUser(r.Context(), req.Username)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	u, err := s.store.GetUserByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newUserResponse(u))
+}
+
+func (s *Server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	u, err := s.store.GetUserByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newUserResponse(u))
+}
+
+func (s *Server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.store.DeleteUser(r.Context(), id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
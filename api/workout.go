@@ -0,0 +1,298 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const dateLayout = "2006-01-02"
+
+type workoutResponse struct {
+	ID               int        `json:"id"`
+	UUID             string     `json:"uuid"`
+	UserID           int        `json:"user_id"`
+	ShortDescription string     `json:"short_description"`
+	LongDescription  string     `json:"long_description"`
+	WorkoutDate      string     `json:"workout_date"`
+	StartTime        *time.Time `json:"start_time,omitempty"`
+	DurationMinutes  int        `json:"duration_minutes"`
+	RPE              int        `json:"rpe"`
+	SessionLoad      float64    `json:"session_load"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+type workoutListResponse struct {
+	Workouts []workoutResponse `json:"workouts"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+}
+
+// body for both creating and replacing a workout
+type workoutRequest struct {
+	ShortDescription string     `json:"short_description"`
+	LongDescription  string     `json:"long_description"`
+	WorkoutDate      string     `json:"workout_date"`
+	StartTime        *time.Time `json:"start_time"` // optional
+	DurationMinutes  int        `json:"duration_minutes"`
+	RPE              int        `json:"rpe"`
+	SessionLoad      float64    `json:"session_load"`
+}
+
+func newWorkoutResponse(w *flexcreek.Workout) workoutResponse {
+	res := workoutResponse{
+		ID:               w.ID,
+		UUID:             w.UUID,
+		UserID:           w.UserID,
+		ShortDescription: w.ShortDescription,
+		LongDescription:  w.LongDescription,
+		WorkoutDate:      w.WorkoutDate.Format(dateLayout),
+		DurationMinutes:  w.DurationMinutes,
+		RPE:              w.RPE,
+		SessionLoad:      w.SessionLoad,
+		CreatedAt:        w.CreatedAt,
+	}
+	if !w.StartTime.IsZero() {
+		res.StartTime = &w.StartTime
+	}
+	return res
+}
+
+// validates the request and converts it into a workout owned by userID
+func (req workoutRequest) toWorkout(userID int) (*flexcreek.Workout, string) {
+	if strings.TrimSpace(req.ShortDescription) == "" {
+		return nil, "short_description is required"
+	}
+
+	date, err := time.Parse(dateLayout, req.WorkoutDate)
+	if err != nil {
+		return nil, "workout_date must be a YYYY-MM-DD date"
+	}
+
+	if req.DurationMinutes < 0 {
+		return nil, "duration_minutes can't be negative"
+	}
+
+	if req.RPE < 0 || req.RPE > 10 {
+		return nil, "rpe must be between 1 and 10, or 0 when not recorded"
+	}
+
+	if req.SessionLoad < 0 {
+		return nil, "session_load can't be negative"
+	}
+
+	w := &flexcreek.Workout{
+		UserID:           userID,
+		ShortDescription: strings.TrimSpace(req.ShortDescription),
+		LongDescription:  req.LongDescription,
+		WorkoutDate:      date,
+		DurationMinutes:  req.DurationMinutes,
+		RPE:              req.RPE,
+		SessionLoad:      req.SessionLoad,
+	}
+	if req.StartTime != nil {
+		w.StartTime = *req.StartTime
+	}
+
+	return w, ""
+}
+
+// parses the pagination, date and text filter query parameters
+func parseWorkoutFilter(r *http.Request) (flexcreek.WorkoutFilter, string) {
+	q := r.URL.Query()
+	f := flexcreek.WorkoutFilter{Limit: defaultLimit}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			return f, "limit must be between 1 and " + strconv.Itoa(maxLimit)
+		}
+		f.Limit = n
+	}
+
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, "offset must be a non-negative integer"
+		}
+		f.Offset = n
+	}
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.From, err = time.Parse(dateLayout, v); err != nil {
+			return f, "from must be a YYYY-MM-DD date"
+		}
+	}
+
+	if v := q.Get("to"); v != "" {
+		if f.To, err = time.Parse(dateLayout, v); err != nil {
+			return f, "to must be a YYYY-MM-DD date"
+		}
+	}
+
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return f, "to can't be before from"
+	}
+
+	f.Query = strings.TrimSpace(q.Get("q"))
+
+	return f, ""
+}
+
+// checks the user exists so unknown users 404 rather than returning an empty list
+func (s *Server) requireUser(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return 0, false
+	}
+
+	if _, err := s.store.GetUserByID(r.Context(), userID); err != nil {
+		writeStoreError(w, err)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+func (s *Server) handleListWorkouts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	f, msg := parseWorkoutFilter(r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	workouts, err := s.store.ListWorkouts(r.Context(), f, userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	resp := workoutListResponse{
+		Workouts: make([]workoutResponse, len(workouts)),
+		Limit:    f.Limit,
+		Offset:   f.Offset,
+	}
+	for i, wo := range workouts {
+		resp.Workouts[i] = newWorkoutResponse(wo)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateWorkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req workoutRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	wo, msg := req.toWorkout(userID)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	id, err := s.store.CreateWorkout(r.Context(), wo)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	created, err := s.store.GetWorkoutByID(r.Context(), id, userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", r.URL.Path+"/"+strconv.Itoa(id))
+	writeJSON(w, http.StatusCreated, newWorkoutResponse(created))
+}
+
+func (s *Server) handleGetWorkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	wo, err := s.store.GetWorkoutByID(r.Context(), id, userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newWorkoutResponse(wo))
+}
+
+func (s *Server) handleUpdateWorkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	var req workoutRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	wo, msg := req.toWorkout(userID)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	wo.ID = id
+
+	if err := s.store.UpdateWorkout(r.Context(), wo); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	updated, err := s.store.GetWorkoutByID(r.Context(), id, userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newWorkoutResponse(updated))
+}
+
+func (s *Server) handleDeleteWorkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := s.userID(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	if err := s.store.DeleteWorkout(r.Context(), id, userID); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
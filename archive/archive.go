@@ -0,0 +1,156 @@
+// Package archive reads and writes portable JSON exports of flexcreek data.
+//
+// rows are keyed by their UUIDs rather than integer IDs, so an export can be imported into any
+// database and importing the same export twice updates the rows it created the first time
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// FormatVersion is bumped whenever the document layout changes in a way older readers can't handle
+const FormatVersion = 1
+
+type Document struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Users      []User    `json:"users"`
+}
+
+type User struct {
+	UUID           string    `json:"uuid"`
+	Username       string    `json:"username"`
+	CreatedAt      time.Time `json:"created_at"`
+	WeightUnit     string    `json:"weight_unit"`
+	DistanceUnit   string    `json:"distance_unit"`
+	FirstDayOfWeek int       `json:"first_day_of_week"`
+	DateFormat     string    `json:"date_format"`
+	TimeZone       string    `json:"time_zone"`
+	Workouts       []Workout `json:"workouts"`
+}
+
+type Workout struct {
+	UUID             string    `json:"uuid"`
+	ShortDescription string    `json:"short_description"`
+	LongDescription  string    `json:"long_description"`
+	WorkoutDate      string    `json:"workout_date"`         // YYYY-MM-DD
+	StartTime        string    `json:"start_time,omitempty"` // RFC 3339, with the offset the workout started in
+	DurationMinutes  int       `json:"duration_minutes"`
+	RPE              int       `json:"rpe"`
+	SessionLoad      float64   `json:"session_load"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Start parses StartTime, returning the zero time when there isn't one
+func (w Workout) Start() (time.Time, error) {
+	if w.StartTime == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, w.StartTime)
+}
+
+func formatStartTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// Store is what Export reads from
+type Store interface {
+	GetAllUsers(ctx context.Context) ([]*flexcreek.User, error)
+	ListWorkouts(ctx context.Context, f flexcreek.WorkoutFilter, userID int) ([]*flexcreek.Workout, error)
+}
+
+// Export builds a document holding every user and their workouts. trashed rows are left out
+func Export(ctx context.Context, s Store) (*Document, error) {
+	users, err := s.GetAllUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Version: FormatVersion, ExportedAt: time.Now().UTC(), Users: []User{}}
+	for _, u := range users {
+		workouts, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{}, u.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		p := u.Preferences
+		au := User{
+			UUID:           u.UUID,
+			Username:       u.Username,
+			CreatedAt:      u.CreatedAt.UTC(),
+			WeightUnit:     p.WeightUnit,
+			DistanceUnit:   p.DistanceUnit,
+			FirstDayOfWeek: int(p.FirstDayOfWeek),
+			DateFormat:     p.DateFormat,
+			TimeZone:       p.TimeZone,
+			Workouts:       []Workout{},
+		}
+
+		for _, w := range workouts {
+			au.Workouts = append(au.Workouts, Workout{
+				UUID:             w.UUID,
+				ShortDescription: w.ShortDescription,
+				LongDescription:  w.LongDescription,
+				WorkoutDate:      w.WorkoutDate.Format("2006-01-02"),
+				StartTime:        formatStartTime(w.StartTime),
+				DurationMinutes:  w.DurationMinutes,
+				RPE:              w.RPE,
+				SessionLoad:      w.SessionLoad,
+				CreatedAt:        w.CreatedAt.UTC(),
+			})
+		}
+
+		doc.Users = append(doc.Users, au)
+	}
+
+	return doc, nil
+}
+
+func Write(w io.Writer, doc *Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// Read decodes a document, rejecting ones from a newer format or with rows missing a UUID
+func Read(r io.Reader) (*Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Version < 1 || doc.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", doc.Version)
+	}
+
+	for _, u := range doc.Users {
+		if u.UUID == "" {
+			return nil, fmt.Errorf("user %q has no uuid", u.Username)
+		}
+
+		for _, w := range u.Workouts {
+			if w.UUID == "" {
+				return nil, fmt.Errorf("workout %q of user %q has no uuid", w.ShortDescription, u.Username)
+			}
+			if _, err := time.Parse("2006-01-02", w.WorkoutDate); err != nil {
+				return nil, fmt.Errorf("workout %s has an invalid date %q", w.UUID, w.WorkoutDate)
+			}
+			if _, err := w.Start(); err != nil {
+				return nil, fmt.Errorf("workout %s has an invalid start time %q", w.UUID, w.StartTime)
+			}
+		}
+	}
+
+	return &doc, nil
+}
@@ -0,0 +1,149 @@
+package archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		err  string
+	}{
+		{
+			name: "a user with a workout",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"uuid": "w1", "workout_date": "2026-03-01", "start_time": "2026-03-01T07:30:00-05:00"}]}]}`,
+		},
+		{
+			name: "no start time",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"uuid": "w1", "workout_date": "2026-03-01"}]}]}`,
+		},
+		{
+			name: "no users",
+			doc:  `{"version": 1, "users": []}`,
+		},
+		{
+			name: "no version",
+			doc:  `{"users": []}`,
+			err:  "unsupported archive version 0",
+		},
+		{
+			name: "a version from the future",
+			doc:  `{"version": 2, "users": []}`,
+			err:  "unsupported archive version 2",
+		},
+		{
+			name: "a negative version",
+			doc:  `{"version": -1, "users": []}`,
+			err:  "unsupported archive version -1",
+		},
+		{
+			name: "a user without a uuid",
+			doc:  `{"version": 1, "users": [{"username": "alice"}]}`,
+			err:  `user "alice" has no uuid`,
+		},
+		{
+			name: "a workout without a uuid",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"short_description": "Run", "workout_date": "2026-03-01"}]}]}`,
+			err:  `workout "Run" of user "alice" has no uuid`,
+		},
+		{
+			name: "a workout without a date",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"uuid": "w1"}]}]}`,
+			err:  `workout w1 has an invalid date ""`,
+		},
+		{
+			name: "a date in another layout",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"uuid": "w1", "workout_date": "03/01/2026"}]}]}`,
+			err:  `workout w1 has an invalid date "03/01/2026"`,
+		},
+		{
+			name: "a date that doesn't exist",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"uuid": "w1", "workout_date": "2026-02-30"}]}]}`,
+			err:  `workout w1 has an invalid date "2026-02-30"`,
+		},
+		{
+			name: "a start time without an offset",
+			doc:  `{"version": 1, "users": [{"uuid": "u1", "username": "alice", "workouts": [{"uuid": "w1", "workout_date": "2026-03-01", "start_time": "2026-03-01T07:30:00"}]}]}`,
+			err:  `workout w1 has an invalid start time "2026-03-01T07:30:00"`,
+		},
+		{
+			name: "not json",
+			doc:  `version: 1`,
+			err:  "invalid character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Read(strings.NewReader(tt.doc))
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if doc.Version != FormatVersion {
+					t.Errorf("read version %d", doc.Version)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got %v, want an error saying %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	start := time.Date(2026, 3, 1, 7, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	in := &Document{
+		Version:    FormatVersion,
+		ExportedAt: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+		Users: []User{{
+			UUID:           "u1",
+			Username:       "alice",
+			WeightUnit:     "lb",
+			DistanceUnit:   "mi",
+			FirstDayOfWeek: 0,
+			DateFormat:     "01/02/2006",
+			TimeZone:       "America/New_York",
+			Workouts: []Workout{
+				{UUID: "w1", ShortDescription: "Run", WorkoutDate: "2026-03-01", StartTime: formatStartTime(start), DurationMinutes: 40, RPE: 6, SessionLoad: 240},
+				{UUID: "w2", ShortDescription: "Lift", LongDescription: "5x5\nsquat", WorkoutDate: "2026-03-01"},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	out, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(out.Users) != 1 || len(out.Users[0].Workouts) != 2 {
+		t.Fatalf("read back %+v", out)
+	}
+	u := out.Users[0]
+	if u.UUID != "u1" || u.WeightUnit != "lb" || u.DateFormat != "01/02/2006" || u.TimeZone != "America/New_York" {
+		t.Errorf("read back user %+v", u)
+	}
+	if w := u.Workouts[1]; w.UUID != "w2" || w.LongDescription != "5x5\nsquat" || w.StartTime != "" {
+		t.Errorf("read back workout %+v", w)
+	}
+
+	got, err := u.Workouts[0].Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := got.Zone(); !got.Equal(start) || offset != -5*60*60 {
+		t.Errorf("the start time came back as %v, want %v in the same offset", got, start)
+	}
+	if got, err := u.Workouts[1].Start(); err != nil || !got.IsZero() {
+		t.Errorf("a missing start time came back as %v, %v", got, err)
+	}
+}
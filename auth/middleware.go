@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+type TokenStore interface {
+	GetAPITokenByHash(ctx context.Context, hash string) (*flexcreek.APIToken, error)
+	TouchAPIToken(ctx context.Context, id int, t time.Time) error
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID  int
+	TokenID int
+	Scopes  []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored on ctx by the middleware, if any
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Middleware authenticates requests carrying an "Authorization: Bearer <token>" header.
+// the token's owner becomes the request's principal, so handlers never trust a user ID from the request itself
+func Middleware(store TokenStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plaintext, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, "missing bearer token")
+				return
+			}
+
+			t, err := store.GetAPITokenByHash(r.Context(), HashToken(plaintext))
+			if errors.Is(err, flexcreek.ErrNotFound) {
+				unauthorized(w, "invalid token")
+				return
+			}
+			if err != nil {
+				log.Printf("auth: looking up token: %v", err)
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+
+			now := time.Now()
+			if t.Expired(now) {
+				unauthorized(w, "token expired")
+				return
+			}
+
+			//failing to record usage shouldn't fail the request
+			if err := store.TouchAPIToken(r.Context(), t.ID, now); err != nil {
+				log.Printf("auth: recording token use: %v", err)
+			}
+
+			p := Principal{UserID: t.UserID, TokenID: t.ID, Scopes: t.Scopes}
+			ctx := flexcreek.WithActor(WithPrincipal(r.Context(), p), t.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope rejects requests whose principal wasn't granted scope. the admin scope satisfies any scope.
+// requests with no principal at all are passed through, leaving authentication to Middleware
+func RequireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := PrincipalFrom(r.Context()); ok && !p.HasScope(scope) && !p.HasScope(ScopeAdmin) {
+			writeError(w, http.StatusForbidden, "token is missing the "+scope+" scope")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="flexcreek"`)
+	writeError(w, http.StatusUnauthorized, msg)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// fakeTokens holds tokens by hash. revoking one deletes it, as DeleteAPIToken does
+type fakeTokens struct {
+	tokens  map[string]*flexcreek.APIToken
+	touched map[int]time.Time
+	err     error
+}
+
+func (s *fakeTokens) GetAPITokenByHash(ctx context.Context, hash string) (*flexcreek.APIToken, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	t, ok := s.tokens[hash]
+	if !ok {
+		return nil, flexcreek.ErrNotFound
+	}
+
+	return t, nil
+}
+
+func (s *fakeTokens) TouchAPIToken(ctx context.Context, id int, t time.Time) error {
+	s.touched[id] = t
+	return nil
+}
+
+// issues a token to the fake store and returns its plaintext
+func (s *fakeTokens) issue(t *testing.T, tok flexcreek.APIToken) string {
+	t.Helper()
+
+	plaintext, hash, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok.TokenHash = hash
+	s.tokens[hash] = &tok
+
+	return plaintext
+}
+
+func TestMiddleware(t *testing.T) {
+	store := &fakeTokens{tokens: map[string]*flexcreek.APIToken{}, touched: map[int]time.Time{}}
+	valid := store.issue(t, flexcreek.APIToken{ID: 1, UserID: 7, Scopes: []string{ScopeWorkoutsRead}})
+	expired := store.issue(t, flexcreek.APIToken{ID: 2, UserID: 7, ExpiresAt: time.Now().Add(-time.Minute)})
+	revoked := store.issue(t, flexcreek.APIToken{ID: 3, UserID: 7})
+	delete(store.tokens, HashToken(revoked))
+
+	var got Principal
+	var actor int
+	h := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = PrincipalFrom(r.Context())
+		actor, _ = flexcreek.ActorFrom(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+		msg    string
+	}{
+		{"valid", "Bearer " + valid, http.StatusNoContent, ""},
+		{"lower case scheme", "bearer " + valid, http.StatusNoContent, ""},
+		{"missing", "", http.StatusUnauthorized, "missing bearer token"},
+		{"basic auth", "Basic YWxpY2U6aHVudGVyMg==", http.StatusUnauthorized, "missing bearer token"},
+		{"no token after the scheme", "Bearer ", http.StatusUnauthorized, "missing bearer token"},
+		{"no scheme", valid, http.StatusUnauthorized, "missing bearer token"},
+		{"unknown", "Bearer fct_nottherealone", http.StatusUnauthorized, "invalid token"},
+		{"the hash instead of the token", "Bearer " + HashToken(valid), http.StatusUnauthorized, "invalid token"},
+		{"revoked", "Bearer " + revoked, http.StatusUnauthorized, "invalid token"},
+		{"expired", "Bearer " + expired, http.StatusUnauthorized, "token expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, actor = Principal{}, 0
+			r := httptest.NewRequest("GET", "/api/v1/me", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+
+			if tt.status != http.StatusNoContent {
+				if !strings.Contains(w.Body.String(), tt.msg) {
+					t.Errorf("body %s, want it to say %q", w.Body, tt.msg)
+				}
+				if w.Header().Get("WWW-Authenticate") == "" {
+					t.Error("a 401 without a WWW-Authenticate header")
+				}
+				if got.UserID != 0 {
+					t.Errorf("the handler ran as %+v", got)
+				}
+				return
+			}
+
+			if got.UserID != 7 || got.TokenID != 1 || !got.HasScope(ScopeWorkoutsRead) {
+				t.Errorf("the principal is %+v, want user 7 with token 1", got)
+			}
+			if actor != 7 {
+				t.Errorf("the actor is %d, want the token's user", actor)
+			}
+		})
+	}
+
+	if _, ok := store.touched[1]; !ok {
+		t.Error("using a token didn't record when it was last used")
+	}
+	if _, ok := store.touched[2]; ok {
+		t.Error("an expired token was recorded as used")
+	}
+}
+
+func TestMiddlewareStoreError(t *testing.T) {
+	store := &fakeTokens{err: errors.New("database is locked")}
+	h := Middleware(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the handler ran without a token being checked")
+	}))
+
+	r := httptest.NewRequest("GET", "/api/v1/me", nil)
+	r.Header.Set("Authorization", "Bearer fct_whatever")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "locked") {
+		t.Errorf("status %d with %s, want a 500 that doesn't leak the error", w.Code, w.Body)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		scope     string
+		status    int
+	}{
+		{"has the scope", &Principal{Scopes: []string{ScopeWorkoutsRead}}, ScopeWorkoutsRead, http.StatusNoContent},
+		{"admin has every scope", &Principal{Scopes: []string{ScopeAdmin}}, ScopeWorkoutsWrite, http.StatusNoContent},
+		{"read can't write", &Principal{Scopes: []string{ScopeWorkoutsRead}}, ScopeWorkoutsWrite, http.StatusForbidden},
+		{"write can't read", &Principal{Scopes: []string{ScopeWorkoutsWrite}}, ScopeWorkoutsRead, http.StatusForbidden},
+		{"read and write aren't admin", &Principal{Scopes: []string{ScopeWorkoutsRead, ScopeWorkoutsWrite}}, ScopeAdmin, http.StatusForbidden},
+		{"no scopes", &Principal{}, ScopeWorkoutsRead, http.StatusForbidden},
+		//authentication is the middleware's job
+		{"no principal", nil, ScopeAdmin, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := RequireScope(tt.scope, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.principal != nil {
+				r = r.WithContext(WithPrincipal(r.Context(), *tt.principal))
+			}
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == http.StatusForbidden && !strings.Contains(w.Body.String(), tt.scope) {
+				t.Errorf("body %s, want it to name the %s scope", w.Body, tt.scope)
+			}
+		})
+	}
+}
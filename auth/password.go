@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minimum password length accepted by HashPassword
+const MinPasswordLength = 8
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
+)
+
+// what a password is compared against when there's no real hash to check, so a login for a user
+// without a password, or one that doesn't exist, takes as long as a wrong password
+var dummyHash = sync.OnceValue(func() []byte {
+	b, _ := bcrypt.GenerateFromPassword([]byte("not anyone's password"), bcrypt.DefaultCost)
+	return b
+})
+
+// HashPassword returns a bcrypt hash of the password suitable for storage
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+
+	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// CheckPassword compares a password against a stored hash.
+// users without a password set (an empty hash) can never log in, and passing an empty hash for an
+// unknown username keeps its response time the same as a known one's
+func CheckPassword(hash string, password string) error {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	return nil
+}
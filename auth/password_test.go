@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckPassword(hash, "correct horse"); err != nil {
+		t.Errorf("the right password was refused: %v", err)
+	}
+	if err := CheckPassword(hash, "wrong horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("a wrong password returned %v, want ErrInvalidCredentials", err)
+	}
+	if err := CheckPassword("", "correct horse"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("a user without a password logged in: %v", err)
+	}
+}
+
+func TestCheckPasswordWithoutHashStillHashes(t *testing.T) {
+	dummyHash()
+
+	//an unknown user costs a bcrypt comparison too, rather than returning straight away
+	start := time.Now()
+	CheckPassword("", "guess")
+	if elapsed := time.Since(start); elapsed < time.Millisecond {
+		t.Errorf("checking against no hash took %s, which gives away that there's no user", elapsed)
+	}
+}
@@ -0,0 +1,64 @@
+// Package auth handles password credentials, per-user API tokens and the http middleware
+// that authenticates requests with them
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// scopes a token can be granted
+const (
+	ScopeWorkoutsRead  = "workouts:read"
+	ScopeWorkoutsWrite = "workouts:write"
+	ScopeAdmin         = "admin" // manage every user, not just the token's owner
+)
+
+var AllScopes = []string{ScopeWorkoutsRead, ScopeWorkoutsWrite, ScopeAdmin}
+
+// prefix on every plaintext token so they're recognisable in configs and secret scanners
+const tokenPrefix = "fct_"
+
+// GenerateToken returns a new random plaintext token and the hash to store for it
+func GenerateToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	plaintext := tokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return plaintext, HashToken(plaintext), nil
+}
+
+// HashToken hashes a plaintext token for storage and lookup.
+// tokens are high entropy so a fast hash is enough, unlike passwords
+func HashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseScopes splits a comma or space separated scope list, rejecting unknown scopes
+func ParseScopes(s string) ([]string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+
+	var scopes []string
+	for _, f := range fields {
+		known := false
+		for _, scope := range AllScopes {
+			if f == scope {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scope %q", f)
+		}
+		scopes = append(scopes, f)
+	}
+
+	return scopes, nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateToken(t *testing.T) {
+	plaintext, hash, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(plaintext, tokenPrefix) || len(plaintext) < len(tokenPrefix)+40 {
+		t.Errorf("token %q, want the %s prefix and 32 random bytes", plaintext, tokenPrefix)
+	}
+	if hash != HashToken(plaintext) {
+		t.Errorf("the returned hash %s isn't HashToken of the plaintext", hash)
+	}
+	if strings.Contains(hash, plaintext) || len(hash) != 64 {
+		t.Errorf("hash %q, want 64 hex characters that don't contain the token", hash)
+	}
+
+	other, otherHash, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == plaintext || otherHash == hash {
+		t.Error("two tokens came out the same")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	//sha256, so it's stable across releases and the stored hashes keep working
+	if got, want := HashToken("fct_example"), "413fb40b86d91c997fa7a1179fc8d3258f108bf2c03c5a1d092381cdc227b397"; got != want {
+		t.Errorf("HashToken gave %q, want %q", got, want)
+	}
+	if HashToken("fct_a") == HashToken("fct_b") {
+		t.Error("different tokens hashed the same")
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		err  bool
+	}{
+		{"", nil, false},
+		{"workouts:read", []string{ScopeWorkoutsRead}, false},
+		{"workouts:read,workouts:write", []string{ScopeWorkoutsRead, ScopeWorkoutsWrite}, false},
+		{"workouts:read workouts:write, admin", []string{ScopeWorkoutsRead, ScopeWorkoutsWrite, ScopeAdmin}, false},
+		{"workouts:delete", nil, true},
+		{"workouts:read,ADMIN", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScopes(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseScopes(%q) error = %v, want error %v", tt.in, err, tt.err)
+			continue
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("ParseScopes(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
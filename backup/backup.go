@@ -0,0 +1,206 @@
+// Package backup manages timestamped snapshot files of the flexcreek database: taking them,
+// optionally gzipping them, rotating old ones out, and unpacking them again for a restore
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	filePrefix = "flexcreek-"
+	fileExt    = ".db"
+	gzipExt    = ".gz"
+
+	// timestamps are UTC so names sort in the order the snapshots were taken
+	timestampLayout = "20060102-150405"
+)
+
+// Snapshotter writes a consistent copy of the database to a new file
+type Snapshotter interface {
+	Snapshot(ctx context.Context, path string) error
+}
+
+type Options struct {
+	Compress bool
+	Label    string // optional, appended to the file name (e.g. "pre-migrate")
+}
+
+// Create snapshots the database into dir, returning the path of the new file.
+// names look like flexcreek-20261018-233000.db, with a .gz suffix when compressed
+func Create(ctx context.Context, s Snapshotter, dir string, opts Options) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := filePrefix + time.Now().UTC().Format(timestampLayout)
+	if opts.Label != "" {
+		name += "-" + opts.Label
+	}
+	path := filepath.Join(dir, name+fileExt)
+
+	if !opts.Compress {
+		if err := s.Snapshot(ctx, path); err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+
+	//snapshot to a hidden file first so a half written backup never looks like a real one
+	tmp := filepath.Join(dir, "."+name+fileExt+".tmp")
+	if err := s.Snapshot(ctx, tmp); err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	if err := compress(tmp, path+gzipExt); err != nil {
+		return "", err
+	}
+
+	return path + gzipExt, nil
+}
+
+func compress(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	zw := gzip.NewWriter(out)
+	zw.Name = strings.TrimSuffix(filepath.Base(dst), gzipExt)
+	if _, err := io.Copy(zw, in); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// List returns the backup files in dir, oldest first
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, filePrefix) {
+			continue
+		}
+		if strings.HasSuffix(name, fileExt) || strings.HasSuffix(name, fileExt+gzipExt) {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Rotate deletes all but the newest keep backups in dir, returning the paths it removed.
+// labelled snapshots (pre-migrate, pre-restore) are safety copies, so they're never counted or removed
+func Rotate(dir string, keep int) ([]string, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("must keep at least one backup")
+	}
+
+	all, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, p := range all {
+		if label(p) == "" {
+			paths = append(paths, p)
+		}
+	}
+
+	if len(paths) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, p := range paths[:len(paths)-keep] {
+		if err := os.Remove(p); err != nil {
+			return removed, err
+		}
+		removed = append(removed, p)
+	}
+
+	return removed, nil
+}
+
+// the label a backup was created with, or "" for a plain one
+func label(path string) string {
+	name := strings.TrimPrefix(filepath.Base(path), filePrefix)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, gzipExt), fileExt)
+	if len(name) <= len(timestampLayout) {
+		return ""
+	}
+
+	return strings.TrimPrefix(name[len(timestampLayout):], "-")
+}
+
+// Extract makes a backup usable as a database file. gzipped backups are unpacked to a temporary file,
+// which cleanup removes; anything else is returned as is
+func Extract(path string) (dbPath string, cleanup func(), err error) {
+	if !strings.HasSuffix(path, gzipExt) {
+		return path, func() {}, nil
+	}
+
+	in, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer in.Close()
+
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	out, err := os.CreateTemp("", "flexcreek-restore-*"+fileExt)
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.Remove(out.Name()) }
+
+	if _, err := io.Copy(out, zr); err != nil {
+		out.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := out.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return out.Name(), cleanup, nil
+}
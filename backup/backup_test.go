@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRotateKeepsSafetySnapshots(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"flexcreek-20260101-090000-pre-migrate-v3.db",
+		"flexcreek-20260102-090000.db",
+		"flexcreek-20260103-090000.db.gz",
+		"flexcreek-20260104-090000-pre-restore.db",
+		"flexcreek-20260105-090000.db",
+		"flexcreek-20260106-090000.db.gz",
+		"notes.txt",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := Rotate(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "flexcreek-20260102-090000.db"),
+		filepath.Join(dir, "flexcreek-20260103-090000.db.gz"),
+	}
+	if !slices.Equal(removed, want) {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+
+	left, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		filepath.Join(dir, "flexcreek-20260101-090000-pre-migrate-v3.db"),
+		filepath.Join(dir, "flexcreek-20260104-090000-pre-restore.db"),
+		filepath.Join(dir, "flexcreek-20260105-090000.db"),
+		filepath.Join(dir, "flexcreek-20260106-090000.db.gz"),
+	}
+	if !slices.Equal(left, want) {
+		t.Errorf("left %v, want %v", left, want)
+	}
+
+	//nothing more to do the second time round
+	if removed, err := Rotate(dir, 2); err != nil || len(removed) != 0 {
+		t.Errorf("rotating again removed %v (%v), want nothing", removed, err)
+	}
+}
@@ -0,0 +1,266 @@
+// Package changelog syncs users and workouts between devices through a shared directory.
+//
+// every local change to a row is recorded with the row's UUID and a hybrid logical clock timestamp.
+// exporting writes the changes another device hasn't seen into a bundle file under <dir>/<device ID>/,
+// and importing applies the other devices' bundles. when two devices change the same row the
+// change with the later timestamp wins, with the device ID breaking ties, so every device ends
+// up with the same result whatever order it imports in
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the kinds of row that are synced
+const (
+	EntityUser    = "user"
+	EntityWorkout = "workout"
+)
+
+// Change is the state of one row after it changed on a device
+type Change struct {
+	Entity string          `json:"entity"`
+	UUID   string          `json:"uuid"`
+	HLC    HLC             `json:"hlc"`
+	Device string          `json:"device"`
+	Data   json.RawMessage `json:"data,omitempty"` // a UserData or WorkoutData, or empty once the row has been purged
+}
+
+func (c Change) Purged() bool {
+	return len(c.Data) == 0
+}
+
+// Version identifies which write a row's current state came from
+type Version struct {
+	HLC    HLC
+	Device string
+}
+
+// Newer reports whether v wins over o under last-writer-wins
+func (v Version) Newer(o Version) bool {
+	if c := v.HLC.Compare(o.HLC); c != 0 {
+		return c > 0
+	}
+
+	return v.Device > o.Device
+}
+
+// UserData is the synced part of a user. passwords and tokens stay on each device
+type UserData struct {
+	Username       string     `json:"username"`
+	CreatedAt      time.Time  `json:"created_at"`
+	WeightUnit     string     `json:"weight_unit"`
+	DistanceUnit   string     `json:"distance_unit"`
+	FirstDayOfWeek int        `json:"first_day_of_week"`
+	DateFormat     string     `json:"date_format"`
+	TimeZone       string     `json:"time_zone"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+// WorkoutData is the synced part of a workout. the owner is referred to by UUID since row IDs differ between devices
+type WorkoutData struct {
+	UserUUID         string     `json:"user_uuid"`
+	ShortDescription string     `json:"short_description"`
+	LongDescription  string     `json:"long_description"`
+	WorkoutDate      string     `json:"workout_date"`
+	StartTime        string     `json:"start_time,omitempty"` // RFC 3339 with the offset it was recorded in
+	DurationMinutes  int        `json:"duration_minutes"`
+	RPE              int        `json:"rpe"`
+	SessionLoad      float64    `json:"session_load"`
+	CreatedAt        time.Time  `json:"created_at"`
+	DeletedAt        *time.Time `json:"deleted_at,omitempty"`
+	DeletedWithUser  bool       `json:"deleted_with_user,omitempty"` // trashed by deleting its user, so restoring the user brings it back
+}
+
+// Conflict is a row that was changed on this device and another one before either had seen the other's change
+type Conflict struct {
+	Entity  string
+	UUID    string
+	Summary string // the username or workout description, to help find the row
+	Local   Version
+	Remote  Version
+	Kept    string // "local" or "remote"
+	Reason  string // set when the remote change couldn't be applied at all
+}
+
+func (c Conflict) String() string {
+	local := "no local version"
+	if c.Local.Device != "" {
+		local = "local version " + c.Local.HLC.String() + " from " + c.Local.Device
+	}
+
+	s := fmt.Sprintf("%s %s (%s): %s, remote version %s from %s, kept %s",
+		c.Entity, c.UUID, c.Summary, local, c.Remote.HLC, c.Remote.Device, c.Kept)
+	if c.Reason != "" {
+		s += ": " + c.Reason
+	}
+
+	return s
+}
+
+// Store is the storage side of syncing
+type Store interface {
+	// DeviceID returns this database's device ID, creating one the first time
+	DeviceID(ctx context.Context) (string, error)
+	// RecordChanges turns rows changed since the last call into changelog entries
+	RecordChanges(ctx context.Context) error
+	// UnexportedChanges returns this device's changes that haven't been written to a bundle, oldest first
+	UnexportedChanges(ctx context.Context) ([]Change, error)
+	MarkExported(ctx context.Context, upTo HLC) error
+	// PeerWatermark returns the newest change already imported from a device
+	PeerWatermark(ctx context.Context, device string) (HLC, error)
+	// ApplyChanges merges changes from other devices, which arrive sorted oldest first
+	ApplyChanges(ctx context.Context, changes []Change) ([]Conflict, error)
+}
+
+type bundle struct {
+	Device  string   `json:"device"`
+	Changes []Change `json:"changes"`
+}
+
+// Export writes this device's unexported changes to a new bundle in dir, returning its path.
+// the path is empty when there was nothing to export
+func Export(ctx context.Context, s Store, dir string) (string, error) {
+	device, err := s.DeviceID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.RecordChanges(ctx); err != nil {
+		return "", err
+	}
+
+	changes, err := s.UnexportedChanges(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	deviceDir := filepath.Join(dir, device)
+	if err := os.MkdirAll(deviceDir, 0o755); err != nil {
+		return "", err
+	}
+
+	b, err := json.MarshalIndent(bundle{Device: device, Changes: changes}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	//bundles are named after their last change, so they sort in the order they were written.
+	//writing then renaming means other devices never read a partial bundle
+	last := changes[len(changes)-1].HLC
+	path := filepath.Join(deviceDir, last.String()+".json")
+	tmp := path + ".tmp"
+
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return path, s.MarkExported(ctx, last)
+}
+
+// Import applies every change in dir from other devices that hasn't been imported yet,
+// returning the number of changes read and any conflicts
+func Import(ctx context.Context, s Store, dir string) (int, []Conflict, error) {
+	device, err := s.DeviceID(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	//local edits need timestamps before they can be compared with incoming ones
+	if err := s.RecordChanges(ctx); err != nil {
+		return 0, nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var changes []Change
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == device {
+			continue
+		}
+
+		peerChanges, err := readPeer(ctx, s, filepath.Join(dir, e.Name()), e.Name())
+		if err != nil {
+			return 0, nil, err
+		}
+		changes = append(changes, peerChanges...)
+	}
+
+	if len(changes) == 0 {
+		return 0, nil, nil
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a := Version{changes[i].HLC, changes[i].Device}
+		b := Version{changes[j].HLC, changes[j].Device}
+		return b.Newer(a)
+	})
+
+	conflicts, err := s.ApplyChanges(ctx, changes)
+	return len(changes), conflicts, err
+}
+
+// reads a device's bundles, keeping only changes newer than the last import from it
+func readPeer(ctx context.Context, s Store, peerDir string, device string) ([]Change, error) {
+	watermark, err := s.PeerWatermark(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(peerDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, f := range files {
+		//a bundle named at or before the watermark has been imported in full
+		if last, err := ParseHLC(strings.TrimSuffix(filepath.Base(f), ".json")); err == nil && last.Compare(watermark) <= 0 {
+			continue
+		}
+
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		var bun bundle
+		if err := json.Unmarshal(b, &bun); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+
+		for _, c := range bun.Changes {
+			if c.Device != device {
+				return nil, fmt.Errorf("%s contains a change from device %s", f, c.Device)
+			}
+			if c.HLC.Compare(watermark) > 0 {
+				changes = append(changes, c)
+			}
+		}
+	}
+
+	return changes, nil
+}
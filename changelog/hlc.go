@@ -0,0 +1,99 @@
+package changelog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HLC is a hybrid logical clock timestamp: wall clock milliseconds, plus a counter that orders
+// events within the same millisecond or when the wall clock has gone backwards
+type HLC struct {
+	Wall    int64 // unix milliseconds
+	Logical int
+}
+
+// String formats the timestamp with fixed widths so the text sorts the same way the clock does
+func (h HLC) String() string {
+	return fmt.Sprintf("%015d.%05d", h.Wall, h.Logical)
+}
+
+func ParseHLC(s string) (HLC, error) {
+	wall, logical, ok := strings.Cut(s, ".")
+	if !ok {
+		return HLC{}, fmt.Errorf("invalid hlc %q", s)
+	}
+
+	w, err := strconv.ParseInt(wall, 10, 64)
+	if err != nil {
+		return HLC{}, fmt.Errorf("invalid hlc %q", s)
+	}
+
+	l, err := strconv.Atoi(logical)
+	if err != nil {
+		return HLC{}, fmt.Errorf("invalid hlc %q", s)
+	}
+
+	return HLC{Wall: w, Logical: l}, nil
+}
+
+func (h HLC) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *HLC) UnmarshalText(b []byte) error {
+	parsed, err := ParseHLC(string(b))
+	if err != nil {
+		return err
+	}
+
+	*h = parsed
+	return nil
+}
+
+// Compare returns -1, 0 or 1 as h is before, equal to or after o
+func (h HLC) Compare(o HLC) int {
+	switch {
+	case h.Wall < o.Wall:
+		return -1
+	case h.Wall > o.Wall:
+		return 1
+	case h.Logical < o.Logical:
+		return -1
+	case h.Logical > o.Logical:
+		return 1
+	}
+
+	return 0
+}
+
+func (h HLC) IsZero() bool {
+	return h.Wall == 0 && h.Logical == 0
+}
+
+// Clock hands out HLC timestamps that never go backwards, even if the wall clock does
+type Clock struct {
+	Last HLC
+}
+
+// Tick timestamps a local event that happened at t
+func (c *Clock) Tick(t time.Time) HLC {
+	wall := t.UnixMilli()
+
+	if wall > c.Last.Wall {
+		c.Last = HLC{Wall: wall}
+	} else {
+		c.Last.Logical++
+	}
+
+	return c.Last
+}
+
+// Observe moves the clock past a timestamp received from another device,
+// so anything recorded locally afterwards orders after it
+func (c *Clock) Observe(remote HLC) {
+	if remote.Compare(c.Last) > 0 {
+		c.Last = remote
+	}
+}
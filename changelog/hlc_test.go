@@ -0,0 +1,112 @@
+package changelog
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestClockNeverGoesBackwards(t *testing.T) {
+	start := time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC)
+
+	//the wall clock stands still, jumps back an hour (an NTP fix, a dead rtc battery) then catches up again
+	walls := []time.Time{
+		start,
+		start,
+		start.Add(-time.Hour),
+		start.Add(-time.Hour + time.Millisecond),
+		start.Add(time.Millisecond),
+		start.Add(time.Second),
+	}
+
+	var c Clock
+	var prev HLC
+	for i, wall := range walls {
+		h := c.Tick(wall)
+		if h.Compare(prev) <= 0 {
+			t.Errorf("tick %d at %v gave %v, which isn't after %v", i, wall, h, prev)
+		}
+		prev = h
+	}
+
+	if want := (HLC{Wall: start.Add(time.Second).UnixMilli()}); prev != want {
+		t.Errorf("once the wall clock had caught up the clock read %v, want %v", prev, want)
+	}
+}
+
+func TestClockObserve(t *testing.T) {
+	now := time.Date(2026, 3, 11, 9, 0, 0, 0, time.UTC)
+	c := Clock{}
+	c.Tick(now)
+
+	//a device whose clock runs an hour fast
+	remote := HLC{Wall: now.Add(time.Hour).UnixMilli(), Logical: 3}
+	c.Observe(remote)
+
+	if local := c.Tick(now.Add(time.Minute)); local.Compare(remote) <= 0 {
+		t.Errorf("a local change after seeing %v was stamped %v, want it ordered after", remote, local)
+	}
+
+	//an older remote timestamp doesn't move the clock back
+	last := c.Last
+	c.Observe(HLC{Wall: now.UnixMilli()})
+	if c.Last != last {
+		t.Errorf("observing an older timestamp moved the clock from %v to %v", last, c.Last)
+	}
+}
+
+func TestHLCTextSortsLikeCompare(t *testing.T) {
+	hlcs := []HLC{
+		{Wall: 1773219600000, Logical: 10},
+		{Wall: 999, Logical: 0},
+		{Wall: 1773219600000, Logical: 2},
+		{Wall: 1773219600001, Logical: 0},
+		{Logical: 1},
+	}
+
+	texts := make([]string, len(hlcs))
+	for i, h := range hlcs {
+		texts[i] = h.String()
+	}
+	sort.Strings(texts)
+	sort.Slice(hlcs, func(i, j int) bool { return hlcs[i].Compare(hlcs[j]) < 0 })
+
+	for i, text := range texts {
+		parsed, err := ParseHLC(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != hlcs[i] {
+			t.Errorf("position %d sorts as %v by text and %v by Compare", i, parsed, hlcs[i])
+		}
+	}
+
+	for _, bad := range []string{"", "12", "12.x", "x.12"} {
+		if _, err := ParseHLC(bad); err == nil {
+			t.Errorf("ParseHLC(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestVersionNewer(t *testing.T) {
+	tests := []struct {
+		name string
+		v, o Version
+		want bool
+	}{
+		{"later wall", Version{HLC{Wall: 2}, "a"}, Version{HLC{Wall: 1}, "b"}, true},
+		{"earlier wall", Version{HLC{Wall: 1}, "b"}, Version{HLC{Wall: 2}, "a"}, false},
+		{"later logical", Version{HLC{Wall: 1, Logical: 2}, "a"}, Version{HLC{Wall: 1, Logical: 1}, "b"}, true},
+		{"equal, higher device", Version{HLC{Wall: 1}, "b"}, Version{HLC{Wall: 1}, "a"}, true},
+		{"equal, lower device", Version{HLC{Wall: 1}, "a"}, Version{HLC{Wall: 1}, "b"}, false},
+		{"identical", Version{HLC{Wall: 1}, "a"}, Version{HLC{Wall: 1}, "a"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.v.Newer(tt.o); got != tt.want {
+				t.Errorf("%v.Newer(%v) = %v, want %v", tt.v, tt.o, got, tt.want)
+			}
+		})
+	}
+}
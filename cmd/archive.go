@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ekholme/flexcreek/archive"
+	"github.com/ekholme/flexcreek/sqlite"
+)
+
+// writes every user and workout to a json archive keyed by uuid
+func runExport(s archive.Store, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("o", "", "file to write the archive to (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	doc, err := archive.Export(context.Background(), s)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return archive.Write(os.Stdout, doc)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	if err := archive.Write(f, doc); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// adds the users and workouts in an archive, updating any that were imported before
+func runImport(s *sqlite.Storage, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: flexcreek import <archive file, or - for stdin>")
+	}
+
+	var r io.Reader = os.Stdin
+	if fs.Arg(0) != "-" {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	doc, err := archive.Read(r)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", fs.Arg(0), err)
+	}
+
+	users, workouts, err := s.ImportArchive(context.Background(), doc)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("imported %d users and %d workouts\n", users, workouts)
+	return nil
+}
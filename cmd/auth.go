@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/auth"
+	"golang.org/x/term"
+)
+
+// what the passwd and token commands need, which both storage backends provide
+type authStore interface {
+	GetUserByID(ctx context.Context, id int) (*flexcreek.User, error)
+	SetPasswordHash(ctx context.Context, id int, hash string) error
+	CreateAPIToken(ctx context.Context, t *flexcreek.APIToken) (int, error)
+	ListAPITokens(ctx context.Context, userID int) ([]*flexcreek.APIToken, error)
+	DeleteAPIToken(ctx context.Context, id int, userID int) error
+}
+
+// sets a user's password, typed without echo at a terminal or read from the first line of piped stdin
+func runPasswd(s authStore, args []string) error {
+	fs := flag.NewFlagSet("passwd", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID whose password to set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.SetPasswordHash(context.Background(), *userID, hash); err != nil {
+		return err
+	}
+
+	fmt.Println("password updated")
+	return nil
+}
+
+// prompts for a password on stderr. a terminal doesn't echo it back, piped input is read a line at a time
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(password, "\r\n"), nil
+}
+
+// manages api tokens: token create|list|revoke
+func runToken(s authStore, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: flexcreek token create|list|revoke [flags]")
+	}
+
+	switch args[0] {
+	case "create":
+		return runTokenCreate(s, args[1:])
+	case "list":
+		return runTokenList(s, args[1:])
+	case "revoke":
+		return runTokenRevoke(s, args[1:])
+	default:
+		return fmt.Errorf("unknown token command %q", args[0])
+	}
+}
+
+func runTokenCreate(s authStore, args []string) error {
+	fs := flag.NewFlagSet("token create", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID the token acts as")
+	name := fs.String("name", "", "label for the token, e.g. phone")
+	scopes := fs.String("scopes", auth.ScopeWorkoutsRead+","+auth.ScopeWorkoutsWrite, "comma separated scopes ("+strings.Join(auth.AllScopes, ", ")+")")
+	ttl := fs.Duration("ttl", 90*24*time.Hour, "how long the token is valid for, 0 for no expiry")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+
+	plaintext, hash, err := auth.GenerateToken()
+	if err != nil {
+		return err
+	}
+
+	t := flexcreek.APIToken{
+		UserID:    *userID,
+		Name:      *name,
+		TokenHash: hash,
+		Scopes:    parsed,
+	}
+	if *ttl > 0 {
+		t.ExpiresAt = time.Now().UTC().Add(*ttl)
+	}
+
+	ctx := context.Background()
+	if _, err := s.GetUserByID(ctx, *userID); err != nil {
+		return err
+	}
+
+	if _, err := s.CreateAPIToken(ctx, &t); err != nil {
+		return err
+	}
+
+	fmt.Println(plaintext)
+	fmt.Fprintln(os.Stderr, "store this token now, it won't be shown again")
+	return nil
+}
+
+func runTokenList(s authStore, args []string) error {
+	fs := flag.NewFlagSet("token list", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID whose tokens to list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tokens, err := s.ListAPITokens(context.Background(), *userID)
+	if err != nil {
+		return err
+	}
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "never"
+		}
+		return t.Local().Format("2006-01-02 15:04")
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSCOPES\tEXPIRES\tLAST USED")
+	for _, t := range tokens {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", t.ID, t.Name, strings.Join(t.Scopes, ","), formatTime(t.ExpiresAt), formatTime(t.LastUsedAt))
+	}
+
+	return tw.Flush()
+}
+
+func runTokenRevoke(s authStore, args []string) error {
+	fs := flag.NewFlagSet("token revoke", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID that owns the token")
+	id := fs.Int("id", 0, "ID of the token to revoke")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := s.DeleteAPIToken(context.Background(), *id, *userID); err != nil {
+		return err
+	}
+
+	fmt.Println("token revoked")
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/ekholme/flexcreek/backup"
+	"github.com/ekholme/flexcreek/sqlite"
+)
+
+// where snapshots are written unless -dir says otherwise
+const backupDir = "backups"
+
+// snapshots the database, then rotates out old snapshots
+func runBackup(s *sqlite.Storage, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	dir := fs.String("dir", backupDir, "directory to write the backup to")
+	compress := fs.Bool("gzip", false, "gzip the backup")
+	keep := fs.Int("keep", 0, "keep only this many of the newest backups in -dir (0 keeps them all, pre-migrate and pre-restore snapshots are always kept)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, err := backup.Create(context.Background(), s, *dir, backup.Options{Compress: *compress})
+	if err != nil {
+		return err
+	}
+	fmt.Println("wrote " + path)
+
+	if *keep > 0 {
+		removed, err := backup.Rotate(*dir, *keep)
+		for _, p := range removed {
+			fmt.Println("removed " + p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaces the database with a backup. the current database is backed up first,
+// and a backup from an older schema is migrated forward once it's restored
+func runRestore(s *sqlite.Storage, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	dir := fs.String("dir", backupDir, "directory for the backup taken of the current database first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: flexcreek restore [-dir dir] <backup file>")
+	}
+
+	ctx := context.Background()
+
+	path, cleanup, err := backup.Extract(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	version, err := sqlite.FileSchemaVersion(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	latest, err := sqlite.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if version > latest {
+		return fmt.Errorf("%s is at schema version %d but this build only knows up to %d; restore it with a newer flexcreek", fs.Arg(0), version, latest)
+	}
+
+	saved, err := backup.Create(ctx, s, *dir, backup.Options{Label: "pre-restore"})
+	if err != nil {
+		return fmt.Errorf("backing up the current database: %w", err)
+	}
+	fmt.Println("backed up the current database to " + saved)
+
+	if err := s.Restore(ctx, path); err != nil {
+		return err
+	}
+
+	if err := s.Migrate(ctx); err != nil {
+		return fmt.Errorf("migrating the restored database: %w", err)
+	}
+
+	fmt.Printf("restored %s (schema version %d, now %d)\n", fs.Arg(0), version, latest)
+	return nil
+}
+
+// applies pending migrations, taking a backup first if there's an existing database to protect
+func migrate(ctx context.Context, s *sqlite.Storage, dir string) error {
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	latest, err := sqlite.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	//a brand new database has nothing worth saving, but one made before versioning is still at 0
+	empty, err := s.IsEmpty(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !empty && current < latest {
+		path, err := backup.Create(ctx, s, dir, backup.Options{Label: fmt.Sprintf("pre-migrate-v%d", current)})
+		if err != nil {
+			return fmt.Errorf("backing up before migrating: %w", err)
+		}
+		log.Printf("backed up the database to %s before migrating from schema version %d to %d", path, current, latest)
+	}
+
+	return s.Migrate(ctx)
+}
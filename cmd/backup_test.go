@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ekholme/flexcreek/backup"
+	"github.com/ekholme/flexcreek/sqlite"
+)
+
+// the schema `make create-tables` made before migrations were versioned, so user_version is still 0
+const baselineSchema = `
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE workouts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    short_description TEXT NOT NULL,
+    long_description TEXT,
+    workout_date TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO users (username) VALUES ('alice');
+INSERT INTO workouts (user_id, short_description, workout_date) VALUES (1, 'Easy run', '2026-03-01');
+`
+
+func TestMigrateBacksUpUnversionedDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "flexcreek.db")
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(baselineSchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	dir := t.TempDir()
+	if err := migrate(ctx, s, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := backup.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || !strings.Contains(filepath.Base(backups[0]), "pre-migrate-v0") {
+		t.Fatalf("the backups are %v, want one pre-migrate-v0 snapshot", backups)
+	}
+
+	if _, err := s.GetUserByUsername(ctx, "alice"); err != nil {
+		t.Errorf("alice didn't survive the migration: %v", err)
+	}
+}
+
+func TestMigrateSkipsBackupOfNewDatabase(t *testing.T) {
+	s, err := sqlite.Open(filepath.Join(t.TempDir(), "flexcreek.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	dir := t.TempDir()
+	if err := migrate(context.Background(), s, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if backups, err := backup.List(dir); err != nil || len(backups) != 0 {
+		t.Errorf("a new database was backed up to %v (%v), want nothing", backups, err)
+	}
+}
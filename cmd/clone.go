@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// what the clone command needs, which both storage backends provide
+type cloneStore interface {
+	GetUserByID(ctx context.Context, id int) (*flexcreek.User, error)
+	GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error)
+	CloneWorkout(ctx context.Context, id int, userID int, date time.Time) (int, error)
+}
+
+// repeats one of a user's workouts on another day, today unless -date says otherwise
+func runClone(s cloneStore, args []string) error {
+	fs := flag.NewFlagSet("clone", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID the workout belongs to")
+	date := fs.String("date", "", "date for the copy: today, yesterday, a weekday or a date in the user's format (default today)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: flexcreek clone [-user N] [-date D] <workout id>")
+	}
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%q isn't a workout id", fs.Arg(0))
+	}
+
+	ctx := context.Background()
+	u, err := s.GetUserByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	prefs := u.Preferences
+
+	day := prefs.Today()
+	if *date != "" {
+		if day, err = prefs.ParseDay(*date); err != nil {
+			return fmt.Errorf("-date must be today, yesterday, a weekday or look like %s", prefs.FormatDate(prefs.Today()))
+		}
+	}
+
+	w, err := s.GetWorkoutByID(ctx, id, u.ID)
+	if errors.Is(err, flexcreek.ErrNotFound) {
+		return fmt.Errorf("user %d has no workout %d", u.ID, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	cloneID, err := s.CloneWorkout(ctx, w.ID, u.ID, day)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("logged workout %d: %s on %s, a copy of workout %d\n", cloneID, w.ShortDescription, prefs.FormatDate(day), w.ID)
+	return nil
+}
@@ -1,41 +1,180 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"log"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/postgres"
 	"github.com/ekholme/flexcreek/sqlite"
 	"github.com/ekholme/flexcreek/ui"
-	_ "modernc.org/sqlite"
 )
 
 const (
-	dsn               = "flexcreek.db"
+	defaultDSN        = "flexcreek.db"
 	workoutListLength = 10
 	testingID         = 1
 )
 
 func main() {
 
-	db, err := sql.Open("sqlite", dsn)
+	//FLEXCREEK_DB picks the database: a sqlite file, or a postgres:// url for a shared server
+	dsn := os.Getenv("FLEXCREEK_DB")
+	if dsn == "" {
+		dsn = defaultDSN
+	}
+
+	if postgres.IsDSN(dsn) {
+		if err := runPostgres(dsn, os.Args[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	storage, err := sqlite.Open(dsn)
 
 	if err != nil {
 		log.Fatalf("Couldn't open the database: %s", err)
 	}
 
-	defer db.Close()
+	defer storage.Close()
 
-	storage := sqlite.NewStorage(db)
-	// userModel := ui.NewUserModel(storage)
-	workoutModel := ui.NewWorkoutModel(storage, testingID, workoutListLength)
-	p := tea.NewProgram(workoutModel)
+	if err := migrate(context.Background(), storage, backupDir); err != nil {
+		log.Fatalf("Couldn't migrate the database: %s", err)
+	}
+
+	//subcommands -- running with no arguments starts the TUI
+	if len(os.Args) > 1 {
+		if err := runCommand(storage, os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v", err)
+	if err := runTUI(storage); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+}
+
+// starts the TUI, which is what running with no arguments does
+func runTUI(s flexcreek.Store) error {
+	cfg, err := ui.LoadConfig(ui.ConfigPath())
+	if err != nil {
+		return fmt.Errorf("couldn't read the config: %w", err)
+	}
+
+	_, err = tea.NewProgram(ui.NewRootModel(s, workoutListLength, cfg)).Run()
+	return err
+}
+
+// dispatches to the named subcommand
+func runCommand(s *sqlite.Storage, name string, args []string) error {
+	switch name {
+	case "migrate":
+		//migrations have already run by the time we get here, so just report where we ended up
+		v, err := s.SchemaVersion(context.Background())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database is at schema version %d\n", v)
+		return nil
+	case "load":
+		return runLoad(s, args)
+	case "log":
+		return runLog(s, args)
+	case "clone":
+		return runClone(s, args)
+	case "goal":
+		return runGoal(s, args)
+	case "prefs":
+		return runPrefs(s, args)
+	case "serve":
+		return runServe(s, args)
+	case "web":
+		return runWeb(s, args)
+	case "passwd":
+		return runPasswd(s, args)
+	case "token":
+		return runToken(s, args)
+	case "purge":
+		return runPurge(s, args)
+	case "backup":
+		return runBackup(s, args)
+	case "restore":
+		return runRestore(s, args)
+	case "sync":
+		return runSync(s, args)
+	case "export":
+		return runExport(s, args)
+	case "import":
+		return runImport(s, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
 
+// runs a subcommand, or the TUI, against a postgres server. everything goes through the shared
+// storage contract except the commands that work on the sqlite file directly
+func runPostgres(dsn string, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "backup", "restore", "sync", "import":
+			return fmt.Errorf("%s needs a sqlite database", args[0])
+		}
+	}
+
+	s, err := postgres.Open(dsn)
+	if err != nil {
+		return fmt.Errorf("couldn't connect to the database: %w", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	if err := s.Migrate(ctx); err != nil {
+		return fmt.Errorf("couldn't migrate the database: %w", err)
+	}
+
+	if len(args) == 0 {
+		return runTUI(s)
+	}
+
+	switch name := args[0]; name {
+	case "migrate":
+		v, err := s.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("database is at schema version %d\n", v)
+		return nil
+	case "load":
+		return runLoad(s, args[1:])
+	case "log":
+		return runLog(s, args[1:])
+	case "goal":
+		return runGoal(s, args[1:])
+	case "prefs":
+		return runPrefs(s, args[1:])
+	case "clone":
+		return runClone(s, args[1:])
+	case "serve":
+		return runServe(s, args[1:])
+	case "web":
+		return runWeb(s, args[1:])
+	case "passwd":
+		return runPasswd(s, args[1:])
+	case "token":
+		return runToken(s, args[1:])
+	case "purge":
+		return runPurge(s, args[1:])
+	case "export":
+		return runExport(s, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
 }
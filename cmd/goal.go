@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/goals"
+)
+
+// manages a user's goals: goal add|list|delete
+func runGoal(s flexcreek.Store, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: flexcreek goal add|list|delete [flags]")
+	}
+
+	switch args[0] {
+	case "add":
+		return runGoalAdd(s, args[1:])
+	case "list":
+		return runGoalList(s, args[1:])
+	case "delete":
+		return runGoalDelete(s, args[1:])
+	default:
+		return fmt.Errorf("unknown goal command %q", args[0])
+	}
+}
+
+// the goal is written out after the flags, e.g. goal add 4 sessions a week (see flexcreek.ParseGoal)
+func runGoalAdd(s flexcreek.Store, args []string) error {
+	fs := flag.NewFlagSet("goal add", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID the goal belongs to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return errors.New("usage: flexcreek goal add [-user N] <goal>, e.g. 4 sessions a week, run 500 km this year or deadlift 200 kg by june")
+	}
+
+	ctx := context.Background()
+	u, err := s.GetUserByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	g, err := flexcreek.ParseGoal(strings.Join(fs.Args(), " "), u.ID, u.Preferences)
+	if err != nil {
+		return err
+	}
+
+	id, err := s.CreateGoal(ctx, g)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("added goal %d: %s\n", id, g.Describe(u.Preferences))
+	return nil
+}
+
+// prints each goal's progress so far
+func runGoalList(s flexcreek.Store, args []string) error {
+	fs := flag.NewFlagSet("goal list", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID whose goals to list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	u, err := s.GetUserByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+	prefs := u.Preferences
+
+	gs, err := s.GetGoals(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+
+	progress, err := goals.NewService(s).Progress(ctx, gs, prefs.Today(), prefs)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tGOAL\tPROGRESS\tSTATUS\tUNTIL")
+	for _, p := range progress {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", p.Goal.ID, p.Goal.Describe(prefs), p.Summary(prefs), p.Status, prefs.FormatDate(p.To))
+	}
+
+	return tw.Flush()
+}
+
+func runGoalDelete(s flexcreek.Store, args []string) error {
+	fs := flag.NewFlagSet("goal delete", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID that owns the goal")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: flexcreek goal delete [-user N] <goal id>")
+	}
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%q isn't a goal id", fs.Arg(0))
+	}
+
+	err = s.DeleteGoal(context.Background(), id, *userID)
+	if errors.Is(err, flexcreek.ErrNotFound) {
+		return fmt.Errorf("user %d has no goal %d", *userID, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("goal deleted")
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/load"
+)
+
+// prints a daily training load report (ACWR and CTL/ATL/TSB) for a user
+func runLoad(s flexcreek.Store, args []string) error {
+	fs := flag.NewFlagSet("load", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID to report on")
+	days := fs.Int("days", 28, "number of days to report")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *days < 1 {
+		return errors.New("-days must be at least 1")
+	}
+
+	ctx := context.Background()
+	u, err := s.GetUserByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	report, err := load.NewService(s).Daily(ctx, u.Preferences.Today(), *days, u.ID)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "date\tload\tacute\tchronic\tACWR\tCTL\tATL\tTSB\t")
+	for _, d := range report {
+		flag := ""
+		if !d.InBand() {
+			flag = "!"
+		}
+		fmt.Fprintf(tw, "%s\t%.0f\t%.1f\t%.1f\t%.2f%s\t%.1f\t%.1f\t%.1f\t\n",
+			u.Preferences.FormatDate(d.Date), d.Load, d.Acute, d.Chronic, d.ACWR, flag, d.CTL, d.ATL, d.TSB)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(report) > 0 {
+		if w := report[len(report)-1].Warning(); w != "" {
+			fmt.Println("\nWarning: " + w)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/editor"
+)
+
+// what the log command needs, which both storage backends provide
+type logStore interface {
+	GetUserByID(ctx context.Context, id int) (*flexcreek.User, error)
+	CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error)
+}
+
+// logs a workout from flags, or with -edit writes it in $EDITOR starting from whatever the flags gave
+func runLog(s logStore, args []string) error {
+	fs := flag.NewFlagSet("log", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID to log the workout for")
+	edit := fs.Bool("edit", false, "write the workout in $EDITOR, under a YAML header for the other fields")
+	var d editor.Draft
+	fs.StringVar(&d.Title, "title", "", "short description (e.g. KB ABC)")
+	fs.StringVar(&d.Date, "date", "", "workout date in the user's date format (default today)")
+	fs.StringVar(&d.Start, "start", "", "start time as HH:MM (optional)")
+	fs.StringVar(&d.Duration, "duration", "", "duration in minutes (optional)")
+	fs.StringVar(&d.RPE, "rpe", "", "RPE 1-10 (optional)")
+	fs.StringVar(&d.Load, "load", "", "session load (optional, defaults to duration x RPE)")
+	fs.StringVar(&d.Notes, "notes", "", "long description, as markdown")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	u, err := s.GetUserByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if *edit {
+		if d.Date == "" {
+			d.Date = u.Preferences.FormatDate(u.Preferences.Today())
+		}
+
+		if text, err = editor.Edit(d.String()); err != nil {
+			return fmt.Errorf("the editor failed: %w", err)
+		}
+		if d, err = editor.ParseDraft(text); err != nil {
+			return keepDraft(text, err)
+		}
+	}
+
+	w, err := d.Workout(u.ID, u.Preferences)
+	switch {
+	case errors.Is(err, editor.ErrNoTitle) && *edit:
+		fmt.Println("nothing logged, the title was left empty")
+		return nil
+	case errors.Is(err, editor.ErrNoTitle):
+		return errors.New("a -title is required, or use -edit")
+	case err != nil && *edit:
+		//kept so nobody has to type a long session twice
+		return keepDraft(text, err)
+	case err != nil:
+		return err
+	}
+
+	id, err := s.CreateWorkout(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("logged workout %d: %s on %s\n", id, w.ShortDescription, u.Preferences.FormatDate(w.WorkoutDate))
+	return nil
+}
+
+// saves a draft that couldn't be logged and says where it went
+func keepDraft(text string, err error) error {
+	path, saveErr := editor.NewFile(text)
+	if saveErr != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w (what you wrote is saved in %s)", err, path)
+}
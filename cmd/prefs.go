@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// shows or updates a user's unit and localization preferences
+func runPrefs(s flexcreek.Store, args []string) error {
+	fs := flag.NewFlagSet("prefs", flag.ExitOnError)
+	userID := fs.Int("user", testingID, "user ID whose preferences to show or update")
+	weight := fs.String("weight", "", "weight unit (kg or lb)")
+	distance := fs.String("distance", "", "distance unit (km or mi)")
+	weekStart := fs.String("week-start", "", "first day of the week (e.g. monday)")
+	dateFormat := fs.String("date-format", "", "date display format as a Go layout (e.g. 01/02/2006)")
+	tz := fs.String("tz", "", "IANA time zone (e.g. America/New_York)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	u, err := s.GetUserByID(ctx, *userID)
+	if err != nil {
+		return err
+	}
+
+	p := u.Preferences
+	changed := false
+	var invalid error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "weight":
+			p.WeightUnit = *weight
+		case "distance":
+			p.DistanceUnit = *distance
+		case "date-format":
+			p.DateFormat = *dateFormat
+		case "tz":
+			p.TimeZone = *tz
+		case "week-start":
+			d, err := parseWeekday(*weekStart)
+			if err != nil {
+				invalid = err
+				return
+			}
+			p.FirstDayOfWeek = d
+		default:
+			return
+		}
+		changed = true
+	})
+	if invalid != nil {
+		return invalid
+	}
+
+	if changed {
+		if err := s.UpdateUserPreferences(ctx, u.ID, p); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("preferences for %s\n", u.Username)
+	fmt.Printf("  weight:      %s\n", p.WeightUnit)
+	fmt.Printf("  distance:    %s\n", p.DistanceUnit)
+	fmt.Printf("  week starts: %s\n", p.FirstDayOfWeek)
+	fmt.Printf("  dates:       %s (%s)\n", p.DateFormat, p.FormatDate(p.Today()))
+	fmt.Printf("  time zone:   %s\n", p.TimeZone)
+
+	return nil
+}
+
+// reads a day of the week written out in full or as its first three letters, in any case
+func parseWeekday(s string) (time.Weekday, error) {
+	names := make([]string, 7)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		names[d] = strings.ToLower(d.String())
+		if strings.EqualFold(s, names[d]) || strings.EqualFold(s, names[d][:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("-week-start must be a day of the week: %s", strings.Join(names, ", "))
+}
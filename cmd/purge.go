@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// how long deleted users and workouts stay in the trash by default
+const trashRetentionDays = 30
+
+type purgeStore interface {
+	PurgeTrash(ctx context.Context, before time.Time) (users int, workouts int, err error)
+}
+
+// permanently removes users and workouts that have been in the trash longer than the retention period
+func runPurge(s purgeStore, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	days := fs.Int("days", trashRetentionDays, "purge items that have been in the trash for at least this many days")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *days < 0 {
+		return fmt.Errorf("-days can't be negative")
+	}
+
+	before := time.Now().AddDate(0, 0, -*days)
+
+	users, workouts, err := s.PurgeTrash(context.Background(), before)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("purged %d users and %d workouts deleted before %s\n", users, workouts, before.Format("2006-01-02 15:04"))
+	return nil
+}
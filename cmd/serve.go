@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ekholme/flexcreek/api"
+)
+
+// what serve needs, which both storage backends provide
+type serveStore interface {
+	api.Store
+	api.AuthStore
+}
+
+// runs the JSON API server until interrupted
+func runServe(s serveStore, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "address to listen on, e.g. :8080 to accept connections from other machines")
+	noAuth := fs.Bool("no-auth", false, "serve without requiring api tokens, only on an address on this machine")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *noAuth {
+		//like the web ui, without tokens anyone who could reach it could change every user's data
+		if !isLoopback(*addr) {
+			return fmt.Errorf("-no-auth only listens on this machine (e.g. 127.0.0.1:8080 or localhost:8080), not %s", *addr)
+		}
+		log.Printf("authentication is disabled, anyone who can reach %s can read and change every user's data", *addr)
+		return listenAndServe(*addr, api.NewServer(s, nil))
+	}
+
+	return listenAndServe(*addr, api.NewServer(s, s))
+}
+
+// serves h on addr, shutting down gracefully on ctrl+c
+func listenAndServe(addr string, h http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
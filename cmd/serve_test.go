@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8080", true},
+		{"127.0.0.2:8080", true},
+		{"localhost:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"[::]:8080", false},
+		{"192.168.1.10:8080", false},
+		{"example.com:8080", false},
+		{"127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopback(tt.addr); got != tt.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestServeRefusesNoAuthOffThisMachine(t *testing.T) {
+	for _, addr := range []string{":8080", "0.0.0.0:8080", "192.168.1.10:8080"} {
+		//refused before anything is listened on, so no store is needed
+		err := runServe(nil, []string{"-no-auth", "-addr", addr})
+		if err == nil || !strings.Contains(err.Error(), "-no-auth") {
+			t.Errorf("serve -no-auth -addr %s returned %v, want it refused", addr, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/ekholme/flexcreek/changelog"
+	"github.com/ekholme/flexcreek/sqlite"
+)
+
+// exchanges changes with other devices through a shared directory
+func runSync(s *sqlite.Storage, args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dir := fs.String("dir", "", "shared directory other devices sync through (e.g. a synced folder)")
+	importOnly := fs.Bool("import-only", false, "only apply other devices' changes")
+	exportOnly := fs.Bool("export-only", false, "only write this device's changes")
+	newDevice := fs.Bool("new-device", false, "give this database a new device ID first; use it on a copy of a database that has already synced")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		return fmt.Errorf("-dir is required")
+	}
+
+	if *importOnly && *exportOnly {
+		return fmt.Errorf("-import-only and -export-only can't be used together")
+	}
+
+	ctx := context.Background()
+
+	if *newDevice {
+		id, err := s.NewDeviceID(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println("this device is now " + id)
+	}
+
+	if !*exportOnly {
+		n, conflicts, err := changelog.Import(ctx, s, *dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("imported %d changes\n", n)
+
+		if len(conflicts) > 0 {
+			fmt.Printf("\n%d conflicts:\n", len(conflicts))
+			for _, c := range conflicts {
+				fmt.Println("  " + c.String())
+			}
+			fmt.Println()
+		}
+	}
+
+	if !*importOnly {
+		path, err := changelog.Export(ctx, s, *dir)
+		if err != nil {
+			return err
+		}
+
+		if path == "" {
+			fmt.Println("no local changes to export")
+		} else {
+			fmt.Println("exported changes to " + path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+
+	"github.com/ekholme/flexcreek/web"
+)
+
+// runs the web ui until interrupted
+func runWeb(s web.Store, args []string) error {
+	fs := flag.NewFlagSet("web", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8081", "address to listen on, which must be on this machine")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	//the web ui has no logins, so anyone who could reach it could change every user's workouts
+	if !isLoopback(*addr) {
+		return fmt.Errorf("the web ui has no logins, so it only listens on this machine (e.g. 127.0.0.1:8081 or localhost:8081), not %s", *addr)
+	}
+
+	srv, err := web.NewServer(s)
+	if err != nil {
+		return err
+	}
+
+	return listenAndServe(*addr, srv)
+}
+
+// whether addr only accepts connections from this machine. an empty host listens everywhere
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
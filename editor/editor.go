@@ -0,0 +1,70 @@
+// Package editor hands text to the user's own editor: notes for the TUI's workout form, and whole
+// workouts (a YAML front matter header over markdown notes) for `flexcreek log -edit`.
+package editor
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// the editor used when neither VISUAL nor EDITOR is set
+const fallback = "vi"
+
+// Command returns the command that opens path in the user's editor: $VISUAL, then $EDITOR, then vi.
+// the variables can carry arguments, e.g. EDITOR="code --wait"
+func Command(path string) *exec.Cmd {
+	args := strings.Fields(os.Getenv("VISUAL"))
+	if len(args) == 0 {
+		args = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(args) == 0 {
+		args = []string{fallback}
+	}
+
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// NewFile writes text to a new temp file for editing and returns its path.
+// the markdown extension gets editors to highlight the notes
+func NewFile(text string) (string, error) {
+	f, err := os.CreateTemp("", "flexcreek-*.md")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), f.Close()
+}
+
+// Read returns what was saved to path, and removes the file
+func Read(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), os.Remove(path)
+}
+
+// Edit opens text in the user's editor, attached to this terminal, and returns what they saved
+func Edit(text string) (string, error) {
+	path, err := NewFile(text)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := Command(path)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return Read(path)
+}
@@ -0,0 +1,101 @@
+package editor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// the words ParseQuickAdd picks out of a one-line workout
+var (
+	minutesPattern = regexp.MustCompile(`^(\d+)(?:m|min|mins)$`)
+	hoursPattern   = regexp.MustCompile(`^(\d+)h(?:(\d+)(?:m|min|mins)?)?$`)
+	rpePattern     = regexp.MustCompile(`^rpe[:=]?(\d+)$`)
+	loadPattern    = regexp.MustCompile(`^load[:=]?(\d+(?:\.\d+)?)$`)
+)
+
+// ParseQuickAdd reads a workout typed on a single line, e.g.
+//
+//	KB ABC @yesterday #conditioning 20min rpe8 -- 20 min AMRAP...
+//
+// @ takes a day (anything Preferences.ParseDay reads, spaces and all, so @Jan 2, 2006 works when
+// that's the user's date format) or a start time like @07:30. a duration is minutes or hours
+// (20min, 45m, 1h, 1h30), rpe8 and load300 set those, and everything after -- is the notes.
+// the words left over, #tags included, are the title.
+// like a draft from the editor, the result still needs Workout to check it
+func ParseQuickAdd(line string, prefs flexcreek.Preferences) (Draft, error) {
+	var d Draft
+
+	header := line
+	padded := " " + line + " "
+	if i := strings.Index(padded, " -- "); i >= 0 {
+		header, d.Notes = padded[:i], strings.TrimSpace(padded[i+len(" -- "):])
+	}
+
+	//a date in a format like "Jan 2, 2006" spans words, so @ is tried with as many words as the format has
+	example := prefs.FormatDate(prefs.Today())
+	dateWords := len(strings.Fields(example))
+
+	var title []string
+	words := strings.Fields(header)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		lower := strings.ToLower(word)
+
+		switch {
+		case strings.HasPrefix(word, "@") && len(word) > 1:
+			if _, err := time.Parse(timeLayout, word[1:]); err == nil {
+				d.Start = word[1:]
+				continue
+			}
+			day, n, err := parseDayWords(words[i:], dateWords, prefs)
+			if err != nil {
+				return Draft{}, fmt.Errorf("%s isn't a day or a time, try @yesterday, @mon, @%s or @07:30", word, example)
+			}
+			d.Date = prefs.FormatDate(day)
+			i += n - 1
+
+		case minutesPattern.MatchString(lower):
+			d.Duration = minutesPattern.FindStringSubmatch(lower)[1]
+
+		case hoursPattern.MatchString(lower):
+			m := hoursPattern.FindStringSubmatch(lower)
+			hours, err := strconv.Atoi(m[1])
+			if err != nil {
+				return Draft{}, fmt.Errorf("%s is too long for a workout", word)
+			}
+			minutes, _ := strconv.Atoi(m[2])
+			d.Duration = strconv.Itoa(hours*60 + minutes)
+
+		case rpePattern.MatchString(lower):
+			d.RPE = rpePattern.FindStringSubmatch(lower)[1]
+
+		case loadPattern.MatchString(lower):
+			d.Load = loadPattern.FindStringSubmatch(lower)[1]
+
+		default:
+			title = append(title, word)
+		}
+	}
+	d.Title = strings.Join(title, " ")
+
+	return d, nil
+}
+
+// reads the day at the start of words, which begin with the @ word. the longest run of up to max words
+// that reads as a day wins, and how many words it took is returned with it
+func parseDayWords(words []string, max int, prefs flexcreek.Preferences) (time.Time, int, error) {
+	var err error
+	for n := min(max, len(words)); n >= 1; n-- {
+		var day time.Time
+		if day, err = prefs.ParseDay(strings.Join(words[:n], " ")[1:]); err == nil {
+			return day, n, nil
+		}
+	}
+
+	return time.Time{}, 0, err
+}
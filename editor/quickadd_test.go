@@ -0,0 +1,176 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	iso := flexcreek.DefaultPreferences()
+	us := iso
+	us.DateFormat = "Jan 2, 2006"
+	eu := iso
+	eu.DateFormat = "2 Jan 2006"
+
+	yesterday := iso.FormatDate(iso.Today().AddDate(0, 0, -1))
+
+	tests := []struct {
+		name  string
+		line  string
+		prefs flexcreek.Preferences
+		want  Draft
+	}{
+		{
+			name:  "just a title",
+			line:  "Easy run",
+			prefs: iso,
+			want:  Draft{Title: "Easy run"},
+		},
+		{
+			name:  "the example",
+			line:  "KB ABC @yesterday #conditioning 20min rpe8 -- 20 min AMRAP, 10 swings",
+			prefs: iso,
+			want:  Draft{Title: "KB ABC #conditioning", Date: yesterday, Duration: "20", RPE: "8", Notes: "20 min AMRAP, 10 swings"},
+		},
+		{
+			name:  "an iso date and a start time",
+			line:  "Run @2026-03-01 @07:30 45m load300",
+			prefs: iso,
+			want:  Draft{Title: "Run", Date: "2026-03-01", Start: "07:30", Duration: "45", Load: "300"},
+		},
+		{
+			name:  "a date with spaces and a comma",
+			line:  "Run @Mar 1, 2026 45min",
+			prefs: us,
+			want:  Draft{Title: "Run", Date: "Mar 1, 2026", Duration: "45"},
+		},
+		{
+			name:  "a date with spaces at the end of the line",
+			line:  "Run 45min @Mar 1, 2026",
+			prefs: us,
+			want:  Draft{Title: "Run", Date: "Mar 1, 2026", Duration: "45"},
+		},
+		{
+			name:  "a day first date with spaces",
+			line:  "Long run @1 Mar 2026 1h30 rpe6",
+			prefs: eu,
+			want:  Draft{Title: "Long run", Date: "1 Mar 2026", Duration: "90", RPE: "6"},
+		},
+		{
+			name:  "a date with spaces before the notes",
+			line:  "Run @1 Mar 2026 -- hilly",
+			prefs: eu,
+			want:  Draft{Title: "Run", Date: "1 Mar 2026", Notes: "hilly"},
+		},
+		{
+			name:  "a relative day in a format with spaces leaves the next words alone",
+			line:  "Run @yesterday 2 laps",
+			prefs: eu,
+			want:  Draft{Title: "Run 2 laps", Date: eu.FormatDate(eu.Today().AddDate(0, 0, -1))},
+		},
+		{
+			name:  "hours on their own, and upper case",
+			line:  "Hike 2H RPE5 Load:420.5",
+			prefs: iso,
+			want:  Draft{Title: "Hike", Duration: "120", RPE: "5", Load: "420.5"},
+		},
+		{
+			name:  "words that only look like settings stay in the title",
+			line:  "5x5 squat rpe @ 20 reps",
+			prefs: iso,
+			want:  Draft{Title: "5x5 squat rpe @ 20 reps"},
+		},
+		{
+			name:  "notes with nothing before them",
+			line:  "-- just notes",
+			prefs: iso,
+			want:  Draft{Notes: "just notes"},
+		},
+		{
+			name:  "a dash inside a word isn't the notes",
+			line:  "Row 2k--test 8min",
+			prefs: iso,
+			want:  Draft{Title: "Row 2k--test", Duration: "8"},
+		},
+		{
+			name:  "an empty line",
+			line:  "   ",
+			prefs: iso,
+			want:  Draft{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuickAdd(tt.line, tt.prefs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuickAddErrors(t *testing.T) {
+	iso := flexcreek.DefaultPreferences()
+	us := iso
+	us.DateFormat = "Jan 2, 2006"
+
+	tests := []struct {
+		name  string
+		line  string
+		prefs flexcreek.Preferences
+		err   string
+	}{
+		{"not a day", "Run @someday", iso, "@someday isn't a day or a time"},
+		{"a date in another format", "Run @Mar 1, 2026", iso, "@Mar isn't a day or a time"},
+		{"a half written date", "Run @Mar 45min", us, "@Mar isn't a day or a time"},
+		{"a date that doesn't exist", "Run @2026-02-30", iso, "@2026-02-30 isn't a day or a time"},
+		{"too many hours", "Run 99999999999999999999h", iso, "is too long for a workout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuickAdd(tt.line, tt.prefs)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got %v, want an error saying %q", err, tt.err)
+			}
+		})
+	}
+
+	//the error shows a date written the user's way
+	_, err := ParseQuickAdd("Run @someday", us)
+	if want := "@" + us.FormatDate(us.Today()); err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got %v, want it to suggest %s", err, want)
+	}
+}
+
+func TestParseQuickAddToWorkout(t *testing.T) {
+	us := flexcreek.DefaultPreferences()
+	us.DateFormat = "Jan 2, 2006"
+	us.TimeZone = "UTC"
+
+	d, err := ParseQuickAdd("Run @Mar 1, 2026 @07:30 45min rpe6", us)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := d.Workout(1, us)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC); !w.WorkoutDate.Equal(want) {
+		t.Errorf("dated %v, want %v", w.WorkoutDate, want)
+	}
+	if want := time.Date(2026, 3, 1, 7, 30, 0, 0, time.UTC); !w.StartTime.Equal(want) {
+		t.Errorf("starts %v, want %v", w.StartTime, want)
+	}
+	if w.ShortDescription != "Run" || w.DurationMinutes != 45 || w.RPE != 6 {
+		t.Errorf("got %+v", *w)
+	}
+}
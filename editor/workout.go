@@ -0,0 +1,160 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoTitle is returned for a draft saved without a title, which is how a user backs out
+var ErrNoTitle = errors.New("the workout has no title")
+
+const (
+	delimiter  = "---"
+	timeLayout = "15:04"
+)
+
+// Draft is a workout as it's written in the editor: a YAML front matter header over markdown notes.
+// everything is kept as text, so a blank field reads as unset and a bad one can be shown back as typed
+type Draft struct {
+	Title    string `yaml:"title"`
+	Date     string `yaml:"date"` // in the user's date format, blank for today
+	Start    string `yaml:"start"`
+	Duration string `yaml:"duration"`
+	RPE      string `yaml:"rpe"`
+	Load     string `yaml:"load"`
+	Notes    string `yaml:"-"`
+}
+
+// the header's fields in the order they're written
+var draftFields = []string{"title", "date", "start", "duration", "rpe", "load"}
+
+// String lays the draft out for editing, every field listed so the blank ones prompt for a value
+func (d Draft) String() string {
+	values := map[string]string{
+		"title":    d.Title,
+		"date":     d.Date,
+		"start":    d.Start,
+		"duration": d.Duration,
+		"rpe":      d.RPE,
+		"load":     d.Load,
+	}
+
+	var b strings.Builder
+	b.WriteString(delimiter + "\n")
+	b.WriteString("# save and quit to log the workout, or leave the title empty to cancel.\n")
+	b.WriteString("# start (HH:MM), duration (minutes), rpe (1-10) and load are optional\n")
+	for _, field := range draftFields {
+		b.WriteString(field + ":")
+		if v := values[field]; v != "" {
+			b.WriteString(" " + quote(v))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(delimiter + "\n\n")
+	if d.Notes != "" {
+		b.WriteString(strings.TrimRight(d.Notes, "\n") + "\n")
+	}
+
+	return b.String()
+}
+
+// yaml for a string value. yaml.Marshal would quote dates and numbers so they stay strings, but the
+// fields are read back as strings anyway, so they're only quoted where they wouldn't read back the same
+func quote(s string) string {
+	var v struct{ V string }
+	if err := yaml.Unmarshal([]byte("v: "+s), &v); err == nil && v.V == s {
+		return s
+	}
+
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return strconv.Quote(s)
+	}
+
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// ParseDraft reads back a draft laid out by String
+func ParseDraft(text string) (Draft, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	rest, ok := strings.CutPrefix(text, delimiter+"\n")
+	if !ok {
+		return Draft{}, errors.New("the workout must start with a --- header")
+	}
+
+	//a newline in front lets an empty header close on the line right after it opened
+	rest = "\n" + rest
+
+	header, notes, ok := strings.Cut(rest, "\n"+delimiter+"\n")
+	if !ok {
+		//the closing line can also be the very last thing in the file
+		if header, ok = strings.CutSuffix(rest, "\n"+delimiter); !ok {
+			return Draft{}, errors.New("the header needs a closing --- line")
+		}
+	}
+
+	var d Draft
+	if err := yaml.Unmarshal([]byte(header), &d); err != nil {
+		return Draft{}, fmt.Errorf("couldn't read the header: %w", err)
+	}
+	d.Notes = strings.TrimSpace(notes)
+
+	return d, nil
+}
+
+// Workout validates the draft and converts it into a workout owned by userID. the date is read in
+// prefs' format and the start time in prefs' zone
+func (d Draft) Workout(userID int, prefs flexcreek.Preferences) (*flexcreek.Workout, error) {
+	w := &flexcreek.Workout{
+		UserID:           userID,
+		ShortDescription: strings.TrimSpace(d.Title),
+		LongDescription:  d.Notes,
+		WorkoutDate:      prefs.Today(),
+	}
+	if w.ShortDescription == "" {
+		return nil, ErrNoTitle
+	}
+
+	var err error
+	if date := strings.TrimSpace(d.Date); date != "" {
+		if w.WorkoutDate, err = prefs.ParseDate(date); err != nil {
+			return nil, fmt.Errorf("date must look like %s", prefs.FormatDate(prefs.Today()))
+		}
+	}
+
+	if start := strings.TrimSpace(d.Start); start != "" {
+		t, err := time.Parse(timeLayout, start)
+		if err != nil {
+			return nil, errors.New("start must be a time of day like 07:30")
+		}
+		day := w.WorkoutDate
+		w.StartTime = time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, prefs.Location())
+	}
+
+	if duration := strings.TrimSpace(d.Duration); duration != "" {
+		if w.DurationMinutes, err = strconv.Atoi(duration); err != nil || w.DurationMinutes < 0 {
+			return nil, errors.New("duration must be a whole number of minutes")
+		}
+	}
+
+	if rpe := strings.TrimSpace(d.RPE); rpe != "" {
+		if w.RPE, err = strconv.Atoi(rpe); err != nil || w.RPE < 1 || w.RPE > 10 {
+			return nil, errors.New("rpe must be between 1 and 10")
+		}
+	}
+
+	if load := strings.TrimSpace(d.Load); load != "" {
+		if w.SessionLoad, err = strconv.ParseFloat(load, 64); err != nil || w.SessionLoad < 0 {
+			return nil, errors.New("load must be a positive number")
+		}
+	}
+
+	return w, nil
+}
@@ -0,0 +1,248 @@
+package editor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func TestDraftRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft Draft
+	}{
+		{"empty", Draft{}},
+		{"just a title", Draft{Title: "Easy run"}},
+		{
+			name:  "every field",
+			draft: Draft{Title: "KB ABC", Date: "2026-03-01", Start: "07:30", Duration: "40", RPE: "8", Load: "320.5", Notes: "5 rounds\n\n- 10 swings\n- 5 cleans"},
+		},
+		{
+			//values yaml would read as something else, or not at all, unless they're quoted
+			name:  "values that need quoting",
+			draft: Draft{Title: "KB: ABC #conditioning", Date: "Mar 1, 2026", Start: "7:30", Duration: "1e3", RPE: "yes", Load: "~"},
+		},
+		{"a title that looks like a list", Draft{Title: "[warm up] - then run"}},
+		{"a title with quotes", Draft{Title: `"Fran" 'for time'`}},
+		{"notes with a line like the delimiter", Draft{Title: "Run", Notes: "intervals\n---\ncool down"}},
+		{"notes that start with a header", Draft{Title: "Run", Notes: "# Warm up\neasy"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := tt.draft.String()
+			got, err := ParseDraft(text)
+			if err != nil {
+				t.Fatalf("%v reading back\n%s", err, text)
+			}
+			if got != tt.draft {
+				t.Errorf("read back %+v, want %+v, from\n%s", got, tt.draft, text)
+			}
+		})
+	}
+}
+
+func TestDraftStringListsEveryField(t *testing.T) {
+	text := Draft{Title: "Easy run"}.String()
+
+	for _, field := range draftFields {
+		if !strings.Contains(text, "\n"+field+":") {
+			t.Errorf("the %s field isn't in\n%s", field, text)
+		}
+	}
+	if !strings.HasPrefix(text, "---\n") || !strings.HasSuffix(text, "---\n\n") {
+		t.Errorf("a draft without notes should be just the header, got\n%s", text)
+	}
+}
+
+func TestParseDraft(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Draft
+		err  string
+	}{
+		{
+			name: "as an editor saves it",
+			text: "---\ntitle: Easy run\nduration: 30\n---\n\nfelt good\n",
+			want: Draft{Title: "Easy run", Duration: "30", Notes: "felt good"},
+		},
+		{
+			name: "windows line endings",
+			text: "---\r\ntitle: Easy run\r\n---\r\n\r\nfelt good\r\n",
+			want: Draft{Title: "Easy run", Notes: "felt good"},
+		},
+		{
+			name: "closed at the very end of the file",
+			text: "---\ntitle: Easy run\n---",
+			want: Draft{Title: "Easy run"},
+		},
+		{
+			name: "no body",
+			text: "---\ntitle: Easy run\n---\n",
+			want: Draft{Title: "Easy run"},
+		},
+		{
+			name: "a body of blank lines",
+			text: "---\ntitle: Easy run\n---\n\n\n  \n",
+			want: Draft{Title: "Easy run"},
+		},
+		{
+			name: "every field cleared",
+			text: "---\n---\n",
+			want: Draft{},
+		},
+		{
+			name: "the comments and unknown fields are ignored",
+			text: "---\n# a comment\ntitle: Easy run\nmood: great\n---\n",
+			want: Draft{Title: "Easy run"},
+		},
+		{
+			name: "nothing at all",
+			text: "",
+			err:  "must start with a --- header",
+		},
+		{
+			name: "no header",
+			text: "Easy run\n\nfelt good\n",
+			err:  "must start with a --- header",
+		},
+		{
+			name: "a header that's never closed",
+			text: "---\ntitle: Easy run\n\nfelt good\n",
+			err:  "needs a closing --- line",
+		},
+		{
+			name: "an unclosed list",
+			text: "---\ntitle: [Easy run\n---\n",
+			err:  "couldn't read the header",
+		},
+		{
+			name: "a field given twice",
+			text: "---\ntitle: Easy run\ntitle: Long run\n---\n",
+			err:  "couldn't read the header",
+		},
+		{
+			name: "a field that isn't text",
+			text: "---\ntitle:\n  name: Easy run\n---\n",
+			err:  "couldn't read the header",
+		},
+		{
+			name: "a bad indent",
+			text: "---\ntitle: Easy run\n  duration: 30\n---\n",
+			err:  "couldn't read the header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDraft(tt.text)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("got %+v, %v, want an error saying %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDraftWorkout(t *testing.T) {
+	prefs := flexcreek.DefaultPreferences()
+	prefs.TimeZone = "UTC"
+	us := prefs
+	us.DateFormat = "01/02/2006"
+	tokyo := prefs
+	tokyo.TimeZone = "Asia/Tokyo"
+	if _, err := time.LoadLocation(tokyo.TimeZone); err != nil {
+		t.Skipf("zone data not available: %v", err)
+	}
+
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		draft Draft
+		prefs flexcreek.Preferences
+		want  flexcreek.Workout
+		err   string
+	}{
+		{
+			name:  "every field",
+			draft: Draft{Title: " KB ABC ", Date: "2026-03-01", Start: "07:30", Duration: "40", RPE: "8", Load: "320.5", Notes: "5 rounds"},
+			prefs: prefs,
+			want: flexcreek.Workout{
+				UserID: 1, ShortDescription: "KB ABC", LongDescription: "5 rounds", WorkoutDate: march,
+				StartTime: time.Date(2026, 3, 1, 7, 30, 0, 0, time.UTC), DurationMinutes: 40, RPE: 8, SessionLoad: 320.5,
+			},
+		},
+		{
+			name:  "a date in the user's format",
+			draft: Draft{Title: "Run", Date: "03/01/2026"},
+			prefs: us,
+			want:  flexcreek.Workout{UserID: 1, ShortDescription: "Run", WorkoutDate: march},
+		},
+		{
+			name:  "a start time in the user's zone",
+			draft: Draft{Title: "Run", Date: "2026-03-01", Start: "06:00"},
+			prefs: tokyo,
+			want:  flexcreek.Workout{UserID: 1, ShortDescription: "Run", WorkoutDate: march, StartTime: time.Date(2026, 2, 28, 21, 0, 0, 0, time.UTC)},
+		},
+		{"no title", Draft{Date: "2026-03-01"}, prefs, flexcreek.Workout{}, ErrNoTitle.Error()},
+		{"a blank title", Draft{Title: "  \t"}, prefs, flexcreek.Workout{}, ErrNoTitle.Error()},
+		{"a date in another format", Draft{Title: "Run", Date: "2026-03-01"}, us, flexcreek.Workout{}, "date must look like"},
+		{"a start without minutes", Draft{Title: "Run", Start: "7"}, prefs, flexcreek.Workout{}, "start must be a time of day"},
+		{"a start past midnight", Draft{Title: "Run", Start: "24:00"}, prefs, flexcreek.Workout{}, "start must be a time of day"},
+		{"a duration in hours", Draft{Title: "Run", Duration: "1h"}, prefs, flexcreek.Workout{}, "duration must be a whole number"},
+		{"a negative duration", Draft{Title: "Run", Duration: "-5"}, prefs, flexcreek.Workout{}, "duration must be a whole number"},
+		{"an rpe of 0", Draft{Title: "Run", RPE: "0"}, prefs, flexcreek.Workout{}, "rpe must be between 1 and 10"},
+		{"an rpe of 11", Draft{Title: "Run", RPE: "11"}, prefs, flexcreek.Workout{}, "rpe must be between 1 and 10"},
+		{"a fractional rpe", Draft{Title: "Run", RPE: "7.5"}, prefs, flexcreek.Workout{}, "rpe must be between 1 and 10"},
+		{"a negative load", Draft{Title: "Run", Load: "-1"}, prefs, flexcreek.Workout{}, "load must be a positive number"},
+		{"a load that isn't a number", Draft{Title: "Run", Load: "heavy"}, prefs, flexcreek.Workout{}, "load must be a positive number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.draft.Workout(1, tt.prefs)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("got %v, want an error saying %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.StartTime.Equal(tt.want.StartTime) {
+				t.Errorf("starts %v, want %v", got.StartTime, tt.want.StartTime)
+			}
+			got.StartTime, tt.want.StartTime = time.Time{}, time.Time{}
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	//a blank date is today, wherever the user is
+	w, err := Draft{Title: "Run", Date: " "}.Workout(1, tokyo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !w.WorkoutDate.Equal(tokyo.Today()) {
+		t.Errorf("a blank date gave %v, want today, %v", w.WorkoutDate, tokyo.Today())
+	}
+
+	if _, err := (Draft{}).Workout(1, prefs); !errors.Is(err, ErrNoTitle) {
+		t.Errorf("a draft without a title returned %v, want ErrNoTitle", err)
+	}
+}
@@ -0,0 +1,14 @@
+package flexcreek
+
+import (
+	"errors"
+)
+
+// errors returned by storage implementations so callers don't need to know about the underlying driver
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("already exists")
+
+	// returned along with ErrConflict when a new user's name is still held by a user in the trash
+	ErrNameInTrash = errors.New("the name belongs to a user in the trash, who can be restored")
+)
@@ -0,0 +1,282 @@
+package flexcreek
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek/units"
+)
+
+// Goal is something a user is working towards, e.g. 4 sessions a week, 500 km of running this year
+// or a 200 kg deadlift by June.
+//
+// the workout metrics (sessions, minutes and load) count the user's workouts, only the ones whose
+// title contains Match when it's set. any other metric names a measurement, never workouts: distances add up, like
+// the kilometers of a year's runs, and everything else is reached by the best one, like a lift.
+// a goal with a Period starts over every week, month or year, one without runs from StartDate to Deadline
+type Goal struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Metric    string    `db:"metric"`
+	Match     string    `db:"title_match"`
+	Target    float64   `db:"target"`
+	Unit      string    `db:"unit"`   // canonical (kg, km, cm), and empty for the workout metrics
+	Period    string    `db:"period"` // week, month, year, or empty for a goal with a deadline
+	StartDate time.Time `db:"start_date"`
+	Deadline  time.Time `db:"deadline"` // zero for a goal with a period
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// the metrics counted from workouts
+const (
+	GoalSessions = "sessions"
+	GoalMinutes  = "minutes"
+	GoalLoad     = "load"
+)
+
+// how often a goal starts over
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+// CountsWorkouts reports whether the goal is measured from workouts rather than measurements
+func (g *Goal) CountsWorkouts() bool {
+	switch g.Metric {
+	case GoalSessions, GoalMinutes, GoalLoad:
+		return true
+	}
+	return false
+}
+
+// Totals reports whether progress adds up over the goal's window, rather than being the best measurement
+func (g *Goal) Totals() bool {
+	return g.CountsWorkouts() || g.Unit == units.Kilometer
+}
+
+// Validate checks the goal can be tracked
+func (g *Goal) Validate() error {
+	if g.Metric == "" {
+		return errors.New("a goal needs a metric")
+	}
+
+	if g.Target <= 0 {
+		return errors.New("the target must be more than 0")
+	}
+
+	if g.Match != "" && !g.CountsWorkouts() {
+		return fmt.Errorf("matching only works for %s, %s and %s", GoalSessions, GoalMinutes, GoalLoad)
+	}
+
+	switch g.Period {
+	case PeriodWeek, PeriodMonth, PeriodYear:
+		return nil
+	case "":
+	default:
+		return fmt.Errorf("unknown period %q, goals repeat every %s, %s or %s", g.Period, PeriodWeek, PeriodMonth, PeriodYear)
+	}
+
+	if g.Deadline.IsZero() {
+		return errors.New("a goal needs a period or a deadline")
+	}
+
+	if g.Deadline.Before(g.StartDate) {
+		return errors.New("the deadline is before the goal starts")
+	}
+
+	return nil
+}
+
+// Describe writes the goal the way ParseGoal reads it, e.g. 4 sessions a week or 200 kg deadlift by 2027-06-30
+func (g *Goal) Describe(prefs Preferences) string {
+	var b strings.Builder
+
+	target, unit := g.Target, prefs.DisplayUnit(g.Unit)
+	if v, err := units.Convert(target, g.Unit, unit); err == nil {
+		target = v
+	}
+	b.WriteString(strconv.FormatFloat(math.Round(target*10)/10, 'f', -1, 64))
+	if unit != "" {
+		b.WriteString(" " + unit)
+	}
+	b.WriteString(" " + g.Metric)
+
+	if g.Match != "" {
+		b.WriteString(" matching " + g.Match)
+	}
+
+	if g.Period != "" {
+		b.WriteString(" a " + g.Period)
+	} else {
+		b.WriteString(" by " + prefs.FormatDate(g.Deadline))
+	}
+
+	return b.String()
+}
+
+// a number, with the unit written straight after it if there is one, e.g. 500km
+var goalTargetPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)([a-z%]*)$`)
+
+// ParseGoal reads a goal written out in a line, e.g.
+//
+//	4 sessions a week
+//	150 minutes matching run a week
+//	run 500 km this year
+//	deadlift 200 kg by june
+//
+// the line ends with how often the goal starts over (a week, a month, a year) or by when it's due:
+// this week, month or year, by a month (the end of the next one) or by a date in the user's format.
+// goals with a deadline start today, except this week, month and year, which count from their start
+func ParseGoal(line string, userID int, prefs Preferences) (*Goal, error) {
+	return parseGoal(line, userID, prefs, prefs.Today())
+}
+
+func parseGoal(line string, userID int, prefs Preferences, today time.Time) (*Goal, error) {
+	g := Goal{UserID: userID}
+
+	words := strings.Fields(line)
+	lower := strings.Fields(strings.ToLower(line))
+	n := len(words)
+
+	//the schedule comes last
+	switch {
+	case n >= 2 && (lower[n-2] == "a" || lower[n-2] == "per" || lower[n-2] == "each" || lower[n-2] == "every") && isPeriod(lower[n-1]):
+		g.Period = lower[n-1]
+		words = words[:n-2]
+
+	case n >= 1 && strings.HasSuffix(lower[n-1], "ly") && isPeriod(strings.TrimSuffix(lower[n-1], "ly")):
+		g.Period = strings.TrimSuffix(lower[n-1], "ly")
+		words = words[:n-1]
+
+	case n >= 2 && lower[n-2] == "this" && isPeriod(lower[n-1]):
+		g.StartDate, g.Deadline = PeriodBounds(lower[n-1], today, prefs)
+		words = words[:n-2]
+
+	default:
+		by := -1
+		for i, w := range lower {
+			if w == "by" {
+				by = i
+			}
+		}
+		if by < 0 || by == n-1 {
+			return nil, errors.New("say how often or by when, e.g. a week, this year or by june")
+		}
+
+		deadline, err := parseDeadline(strings.Join(words[by+1:], " "), today, prefs)
+		if err != nil {
+			return nil, err
+		}
+		g.StartDate, g.Deadline = today, deadline
+		words = words[:by]
+	}
+
+	for i, w := range words {
+		if strings.EqualFold(w, "matching") {
+			g.Match = strings.Join(words[i+1:], " ")
+			words = words[:i]
+			break
+		}
+	}
+
+	var metric []string
+	var value float64
+	var unit string
+	found := false
+	for i := 0; i < len(words); i++ {
+		m := goalTargetPattern.FindStringSubmatch(strings.ToLower(words[i]))
+		if found || m == nil || (m[2] != "" && !isGoalUnit(m[2])) {
+			metric = append(metric, words[i])
+			continue
+		}
+
+		value, _ = strconv.ParseFloat(m[1], 64)
+		unit = m[2]
+		if unit == "" && i+1 < len(words) && isGoalUnit(strings.ToLower(words[i+1])) {
+			unit = strings.ToLower(words[i+1])
+			i++
+		}
+		found = true
+	}
+	if !found {
+		return nil, errors.New("the goal has no target, e.g. 4 sessions a week")
+	}
+
+	g.Metric = strings.Join(metric, " ")
+	switch strings.ToLower(g.Metric) {
+	case "":
+		return nil, errors.New("what is the goal for? e.g. 4 sessions a week or deadlift 200 kg by june")
+	case "session", "sessions", "workout", "workouts":
+		g.Metric = GoalSessions
+	case "minute", "minutes", "min", "mins":
+		g.Metric = GoalMinutes
+	case "load":
+		g.Metric = GoalLoad
+	}
+
+	if g.CountsWorkouts() && unit != "" {
+		return nil, fmt.Errorf("%s are counted without a unit", g.Metric)
+	}
+	g.Target, g.Unit = units.Canonical(value, unit)
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// PeriodBounds returns the first and last day of the week, month or year holding day
+func PeriodBounds(period string, day time.Time, prefs Preferences) (time.Time, time.Time) {
+	switch period {
+	case PeriodWeek:
+		start := prefs.StartOfWeek(day)
+		return start, start.AddDate(0, 0, 6)
+	case PeriodMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	default:
+		start := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	}
+}
+
+func isPeriod(word string) bool {
+	return word == PeriodWeek || word == PeriodMonth || word == PeriodYear
+}
+
+func isGoalUnit(word string) bool {
+	switch word {
+	case units.Kilogram, units.Pound, units.Kilometer, units.Mile, units.Centimeter, units.Inch, units.Percent:
+		return true
+	}
+	return false
+}
+
+// a month's name means the end of the next one of those months, which can be this one
+func parseDeadline(s string, today time.Time, prefs Preferences) (time.Time, error) {
+	word := strings.ToLower(s)
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if word == name || word == name[:3] {
+			year := today.Year()
+			if m < today.Month() {
+				year++
+			}
+			return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	d, err := prefs.ParseDate(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s isn't a month or a date, try by june or by %s", s, prefs.FormatDate(today.AddDate(0, 3, 0)))
+	}
+
+	return d, nil
+}
@@ -0,0 +1,81 @@
+package flexcreek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGoal(t *testing.T) {
+	//a Saturday in August, so june is next year's and december is this year's
+	today := time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		line string
+		want Goal
+	}{
+		{"4 sessions a week", Goal{Metric: GoalSessions, Target: 4, Period: PeriodWeek}},
+		{"3 workouts monthly", Goal{Metric: GoalSessions, Target: 3, Period: PeriodMonth}},
+		{"150 minutes matching easy run a week", Goal{Metric: GoalMinutes, Match: "easy run", Target: 150, Period: PeriodWeek}},
+		{"load 2000 matching KB every month", Goal{Metric: GoalLoad, Match: "KB", Target: 2000, Period: PeriodMonth}},
+		{"run 500 km this year", Goal{Metric: "run", Target: 500, Unit: "km", StartDate: date(2026, 1, 1), Deadline: date(2026, 12, 31)}},
+		{"run 500km this year", Goal{Metric: "run", Target: 500, Unit: "km", StartDate: date(2026, 1, 1), Deadline: date(2026, 12, 31)}},
+		{"run 100 km this month", Goal{Metric: "run", Target: 100, Unit: "km", StartDate: date(2026, 8, 1), Deadline: date(2026, 8, 31)}},
+		{"deadlift 200 kg by june", Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: today, Deadline: date(2027, 6, 30)}},
+		{"deadlift 200 kg by Dec", Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: today, Deadline: date(2026, 12, 31)}},
+		{"deadlift 200 kg by august", Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: today, Deadline: date(2026, 8, 31)}},
+		{"200 kg back squat by 2026-10-01", Goal{Metric: "back squat", Target: 200, Unit: "kg", StartDate: today, Deadline: date(2026, 10, 1)}},
+		{"body fat 15% by december", Goal{Metric: "body fat", Target: 15, Unit: "%", StartDate: today, Deadline: date(2026, 12, 31)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parseGoal(tt.line, 1, DefaultPreferences(), today)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.UserID = 1
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGoalConvertsUnits(t *testing.T) {
+	today := time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)
+
+	g, err := parseGoal("run 300 mi this year", 1, DefaultPreferences(), today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Unit != "km" || g.Target < 482.8 || g.Target > 482.9 {
+		t.Errorf("300 mi was stored as %v %s, want about 482.8 km", g.Target, g.Unit)
+	}
+}
+
+func TestParseGoalErrors(t *testing.T) {
+	today := time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []string{
+		"",
+		"4 sessions",
+		"4 sessions by",
+		"sessions a week",
+		"200 kg by june",
+		"4 kg sessions a week",
+		"0 sessions a week",
+		"200 kg deadlift matching heavy by june",
+		"200 kg deadlift by someday",
+		"200 kg deadlift by 2026-01-01",
+	}
+
+	for _, line := range tests {
+		t.Run(line, func(t *testing.T) {
+			if g, err := parseGoal(line, 1, DefaultPreferences(), today); err == nil {
+				t.Errorf("parsed %q as %+v, want an error", line, *g)
+			}
+		})
+	}
+}
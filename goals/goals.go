@@ -0,0 +1,151 @@
+// Package goals measures a user's progress towards their goals from their workouts and measurements
+package goals
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/units"
+)
+
+// Status is how a goal is going
+type Status string
+
+const (
+	Done    Status = "done"
+	OnTrack Status = "on track"
+	Behind  Status = "behind"
+	Missed  Status = "missed" // the deadline passed first
+)
+
+// Progress is where a goal stands on a given day
+type Progress struct {
+	Goal     *flexcreek.Goal
+	From     time.Time // the window being counted, inclusive: the current period, or start to deadline
+	To       time.Time
+	Current  float64 // in the goal's unit
+	Expected float64 // where steady progress through the window would have got to by the day
+	Status   Status
+}
+
+// Fraction is how much of the target has been reached, between 0 and 1
+func (p Progress) Fraction() float64 {
+	return min(max(p.Current/p.Goal.Target, 0), 1)
+}
+
+// DaysLeft counts the days left in the window, the day itself included
+func (p Progress) DaysLeft(today time.Time) int {
+	return max(int(p.To.Sub(today).Hours()/24)+1, 0)
+}
+
+// Summary is the progress against the target in the user's units, e.g. 3 / 4 sessions or 412.5 / 500 km
+func (p Progress) Summary(prefs flexcreek.Preferences) string {
+	g := p.Goal
+	unit := prefs.DisplayUnit(g.Unit)
+	number := func(v float64) string {
+		if c, err := units.Convert(v, g.Unit, unit); err == nil {
+			v = c
+		}
+		return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+	}
+
+	s := number(p.Current) + " / " + number(g.Target)
+	switch {
+	case unit != "":
+		s += " " + unit
+	case g.CountsWorkouts():
+		s += " " + g.Metric
+	}
+
+	return s
+}
+
+// Window returns the days a goal counts on today: the current week, month or year for a goal that
+// starts over, otherwise its start date through its deadline
+func Window(g *flexcreek.Goal, today time.Time, prefs flexcreek.Preferences) (time.Time, time.Time) {
+	if g.Period == "" {
+		return g.StartDate, g.Deadline
+	}
+
+	return flexcreek.PeriodBounds(g.Period, today, prefs)
+}
+
+// Evaluate works out a goal's progress on today from the user's workouts and their measurements of
+// the goal's metric. either can hold more than the goal's window, anything outside it is skipped
+func Evaluate(g *flexcreek.Goal, workouts []*flexcreek.Workout, measurements []*flexcreek.Measurement, today time.Time, prefs flexcreek.Preferences) Progress {
+	from, to := Window(g, today, prefs)
+	p := Progress{Goal: g, From: from, To: to}
+	inWindow := func(d time.Time) bool { return !d.Before(from) && !d.After(to) }
+
+	//where the line expected progress follows starts from
+	var baseline float64
+
+	switch {
+	case g.CountsWorkouts():
+		match := strings.ToLower(g.Match)
+		for _, w := range workouts {
+			if !inWindow(w.WorkoutDate) || !strings.Contains(strings.ToLower(w.ShortDescription), match) {
+				continue
+			}
+
+			switch g.Metric {
+			case flexcreek.GoalSessions:
+				p.Current++
+			case flexcreek.GoalMinutes:
+				p.Current += float64(w.DurationMinutes)
+			case flexcreek.GoalLoad:
+				p.Current += w.Load()
+			}
+		}
+
+	case g.Totals():
+		for _, m := range measurements {
+			if v, ok := inUnit(m, g.Unit); ok && inWindow(m.MeasuredOn) {
+				p.Current += v
+			}
+		}
+
+	default:
+		//a best only counts once it's been set, and only if it was set by the deadline
+		for _, m := range measurements {
+			v, ok := inUnit(m, g.Unit)
+			if !ok || m.MeasuredOn.After(to) || m.MeasuredOn.After(today) {
+				continue
+			}
+			p.Current = max(p.Current, v)
+			if m.MeasuredOn.Before(from) {
+				baseline = max(baseline, v)
+			}
+		}
+	}
+
+	days := to.Sub(from).Hours()/24 + 1
+	elapsed := min(max(today.Sub(from).Hours()/24, 0), days)
+	p.Expected = baseline + (g.Target-baseline)*elapsed/days
+
+	switch {
+	case p.Current >= g.Target:
+		p.Status = Done
+	case today.After(to):
+		p.Status = Missed
+	case p.Current >= p.Expected:
+		p.Status = OnTrack
+	default:
+		p.Status = Behind
+	}
+
+	return p
+}
+
+// a measurement's value in unit. a goal without a unit takes measurements as they were logged
+func inUnit(m *flexcreek.Measurement, unit string) (float64, bool) {
+	if unit == "" {
+		return m.Value, true
+	}
+
+	v, err := units.Convert(m.Value, m.Unit, unit)
+	return v, err == nil
+}
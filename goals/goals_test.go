@@ -0,0 +1,172 @@
+package goals
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func day(m time.Month, d int) time.Time {
+	return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func workout(title string, date time.Time, minutes int, load float64) *flexcreek.Workout {
+	return &flexcreek.Workout{ShortDescription: title, WorkoutDate: date, DurationMinutes: minutes, SessionLoad: load}
+}
+
+func measurement(value float64, unit string, date time.Time) *flexcreek.Measurement {
+	return &flexcreek.Measurement{Value: value, Unit: unit, MeasuredOn: date}
+}
+
+func TestEvaluate(t *testing.T) {
+	//a Wednesday
+	today := day(3, 11)
+	sundays := flexcreek.DefaultPreferences()
+	sundays.FirstDayOfWeek = time.Sunday
+
+	workouts := []*flexcreek.Workout{
+		workout("Long run", day(2, 28), 90, 0),
+		workout("Easy run", day(3, 1), 30, 0),
+		workout("KB ABC", day(3, 8), 40, 300),
+		workout("Easy Run", day(3, 9), 30, 150),
+		workout("KB ABC", day(3, 10), 40, 320),
+	}
+
+	deadlifts := []*flexcreek.Measurement{
+		measurement(180, "kg", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)),
+		measurement(190, "kg", day(2, 10)),
+		//logged after today, so it isn't counted yet
+		measurement(500, "lb", day(3, 20)),
+	}
+
+	tests := []struct {
+		name         string
+		goal         flexcreek.Goal
+		prefs        flexcreek.Preferences
+		workouts     []*flexcreek.Workout
+		measurements []*flexcreek.Measurement
+		from, to     time.Time
+		current      float64
+		expected     float64
+		status       Status
+	}{
+		{
+			name:     "sessions this week",
+			goal:     flexcreek.Goal{Metric: flexcreek.GoalSessions, Target: 3, Period: flexcreek.PeriodWeek},
+			workouts: workouts,
+			from:     day(3, 9), to: day(3, 15),
+			current: 2, expected: 3 * 2.0 / 7, status: OnTrack,
+		},
+		{
+			name:     "a week starting on sunday",
+			goal:     flexcreek.Goal{Metric: flexcreek.GoalSessions, Target: 4, Period: flexcreek.PeriodWeek},
+			prefs:    sundays,
+			workouts: workouts,
+			from:     day(3, 8), to: day(3, 14),
+			current: 3, expected: 4 * 3.0 / 7, status: OnTrack,
+		},
+		{
+			name:     "minutes matching run, whatever the case",
+			goal:     flexcreek.Goal{Metric: flexcreek.GoalMinutes, Match: "RUN", Target: 300, Period: flexcreek.PeriodMonth},
+			workouts: workouts,
+			from:     day(3, 1), to: day(3, 31),
+			current: 60, expected: 300 * 10.0 / 31, status: Behind,
+		},
+		{
+			name:     "load this month",
+			goal:     flexcreek.Goal{Metric: flexcreek.GoalLoad, Target: 1000, Period: flexcreek.PeriodMonth},
+			workouts: workouts,
+			from:     day(3, 1), to: day(3, 31),
+			//the first run has no session load, so it's 30 minutes at rpe 0
+			current: 770, expected: 1000 * 10.0 / 31, status: OnTrack,
+		},
+		{
+			name:     "sessions this year, done",
+			goal:     flexcreek.Goal{Metric: flexcreek.GoalSessions, Target: 5, Period: flexcreek.PeriodYear},
+			workouts: workouts,
+			from:     day(1, 1), to: day(12, 31),
+			current: 5, expected: 5 * 69.0 / 365, status: Done,
+		},
+		{
+			name: "distance totals in the goal's unit",
+			goal: flexcreek.Goal{Metric: "run", Target: 500, Unit: "km", StartDate: day(1, 1), Deadline: day(12, 31)},
+			measurements: []*flexcreek.Measurement{
+				measurement(100, "km", time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)),
+				measurement(12, "km", day(3, 1)),
+				measurement(10, "mi", day(3, 8)),
+				//a unit that can't be a distance is skipped
+				measurement(5, "kg", day(3, 9)),
+			},
+			from: day(1, 1), to: day(12, 31),
+			current: 12 + 16.09344, expected: 500 * 69.0 / 365, status: Behind,
+		},
+		{
+			name:         "the best lift, from the best before the start",
+			goal:         flexcreek.Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: day(1, 1), Deadline: day(6, 30)},
+			measurements: deadlifts,
+			from:         day(1, 1), to: day(6, 30),
+			current: 190, expected: 180 + 20*69.0/181, status: OnTrack,
+		},
+		{
+			name: "a missed deadline",
+			goal: flexcreek.Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: day(1, 1), Deadline: day(2, 28)},
+			measurements: append([]*flexcreek.Measurement{
+				//reached after the deadline, too late to count
+				measurement(205, "kg", day(3, 5)),
+			}, deadlifts...),
+			from: day(1, 1), to: day(2, 28),
+			current: 190, expected: 200, status: Missed,
+		},
+		{
+			name: "reached before the deadline",
+			goal: flexcreek.Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: day(1, 1), Deadline: day(6, 30)},
+			measurements: append([]*flexcreek.Measurement{
+				measurement(450, "lb", day(3, 5)),
+			}, deadlifts...),
+			from: day(1, 1), to: day(6, 30),
+			current: 450 / 2.20462262185, expected: 180 + 20*69.0/181, status: Done,
+		},
+		{
+			name:         "today before the goal starts",
+			goal:         flexcreek.Goal{Metric: "deadlift", Target: 200, Unit: "kg", StartDate: day(4, 1), Deadline: day(6, 30)},
+			measurements: deadlifts,
+			from:         day(4, 1), to: day(6, 30),
+			//nothing's expected yet beyond the best so far
+			current: 190, expected: 190, status: OnTrack,
+		},
+		{
+			name:         "a total before it starts",
+			goal:         flexcreek.Goal{Metric: "run", Target: 100, Unit: "km", StartDate: day(4, 1), Deadline: day(6, 30)},
+			measurements: []*flexcreek.Measurement{measurement(12, "km", day(3, 1))},
+			from:         day(4, 1), to: day(6, 30),
+			current: 0, expected: 0, status: OnTrack,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := tt.prefs
+			if prefs == (flexcreek.Preferences{}) {
+				prefs = flexcreek.DefaultPreferences()
+			}
+
+			p := Evaluate(&tt.goal, tt.workouts, tt.measurements, today, prefs)
+
+			if !p.From.Equal(tt.from) || !p.To.Equal(tt.to) {
+				t.Errorf("the window is %s to %s, want %s to %s", p.From.Format("2006-01-02"), p.To.Format("2006-01-02"),
+					tt.from.Format("2006-01-02"), tt.to.Format("2006-01-02"))
+			}
+			if math.Abs(p.Current-tt.current) > 1e-6 {
+				t.Errorf("current = %v, want %v", p.Current, tt.current)
+			}
+			if math.Abs(p.Expected-tt.expected) > 1e-6 {
+				t.Errorf("expected = %v, want %v", p.Expected, tt.expected)
+			}
+			if p.Status != tt.status {
+				t.Errorf("status = %q, want %q", p.Status, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package goals
+
+import (
+	"context"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+type Store interface {
+	GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error)
+	GetMeasurementsByMetric(ctx context.Context, metric string, userID int) ([]*flexcreek.Measurement, error)
+}
+
+// Service evaluates goals against the history held in storage
+type Service struct {
+	store Store
+}
+
+func NewService(s Store) *Service {
+	return &Service{
+		store: s,
+	}
+}
+
+// Progress returns where each of a user's goals stands on today, in the same order
+func (s *Service) Progress(ctx context.Context, goals []*flexcreek.Goal, today time.Time, prefs flexcreek.Preferences) ([]Progress, error) {
+	//goals on the same metric share their measurements
+	measurements := map[string][]*flexcreek.Measurement{}
+
+	progress := make([]Progress, len(goals))
+	for i, g := range goals {
+		var workouts []*flexcreek.Workout
+		var err error
+
+		if g.CountsWorkouts() {
+			from, to := Window(g, today, prefs)
+			workouts, err = s.store.GetWorkoutsBetween(ctx, from, to, g.UserID)
+		} else if _, ok := measurements[g.Metric]; !ok {
+			measurements[g.Metric], err = s.store.GetMeasurementsByMetric(ctx, g.Metric, g.UserID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		progress[i] = Evaluate(g, workouts, measurements[g.Metric], today, prefs)
+	}
+
+	return progress, nil
+}
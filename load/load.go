@@ -0,0 +1,99 @@
+// Package load computes training load analytics from a user's workout history:
+// the 7:28 day acute:chronic workload ratio (ACWR) and the exponentially weighted
+// fitness (CTL), fatigue (ATL) and form (TSB) curves.
+package load
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	AcuteWindow   = 7
+	ChronicWindow = 28
+
+	// time constants (in days) for the exponentially weighted averages
+	CTLTimeConstant = 42
+	ATLTimeConstant = 7
+
+	// ACWR outside of this band is flagged
+	ACWRLow  = 0.8
+	ACWRHigh = 1.3
+)
+
+// Day holds the load metrics for a single calendar day
+type Day struct {
+	Date    time.Time
+	Load    float64
+	Acute   float64 // rolling 7 day average load
+	Chronic float64 // rolling 28 day average load
+	ACWR    float64 // acute / chronic, 0 when there's no chronic load yet
+	CTL     float64 // chronic training load (fitness)
+	ATL     float64 // acute training load (fatigue)
+	TSB     float64 // training stress balance (form), yesterday's CTL - ATL
+}
+
+// InBand reports whether the day's ACWR sits inside the 0.8-1.3 band.
+// days without any chronic load are treated as in band since there's nothing to compare against
+func (d Day) InBand() bool {
+	if d.Chronic == 0 {
+		return true
+	}
+
+	return d.ACWR >= ACWRLow && d.ACWR <= ACWRHigh
+}
+
+// Warning returns a human readable warning when ACWR leaves the band, or an empty string
+func (d Day) Warning() string {
+	if d.InBand() {
+		return ""
+	}
+
+	if d.ACWR > ACWRHigh {
+		return fmt.Sprintf("ACWR %.2f is above %.1f -- load is spiking, injury risk is elevated", d.ACWR, ACWRHigh)
+	}
+
+	return fmt.Sprintf("ACWR %.2f is below %.1f -- load has dropped off, fitness may detrain", d.ACWR, ACWRLow)
+}
+
+// Compute calculates the metrics for each day in loads, where loads[i] is the total
+// load on start + i days. days with no training should be passed as 0
+func Compute(start time.Time, loads []float64) []Day {
+	days := make([]Day, len(loads))
+
+	var ctl, atl float64
+	var acuteSum, chronicSum float64
+
+	for i, l := range loads {
+		d := Day{
+			Date: start.AddDate(0, 0, i),
+			Load: l,
+			TSB:  ctl - atl,
+		}
+
+		//rolling sums over the trailing windows
+		acuteSum += l
+		if i >= AcuteWindow {
+			acuteSum -= loads[i-AcuteWindow]
+		}
+		chronicSum += l
+		if i >= ChronicWindow {
+			chronicSum -= loads[i-ChronicWindow]
+		}
+
+		d.Acute = acuteSum / AcuteWindow
+		d.Chronic = chronicSum / ChronicWindow
+		if d.Chronic > 0 {
+			d.ACWR = d.Acute / d.Chronic
+		}
+
+		ctl += (l - ctl) / CTLTimeConstant
+		atl += (l - atl) / ATLTimeConstant
+		d.CTL = ctl
+		d.ATL = atl
+
+		days[i] = d
+	}
+
+	return days
+}
@@ -0,0 +1,189 @@
+package load
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// n days of the same load
+func steady(l float64, n int) []float64 {
+	loads := make([]float64, n)
+	for i := range loads {
+		loads[i] = l
+	}
+
+	return loads
+}
+
+func TestCompute(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		loads []float64
+		day   int // the day checked
+		want  Day
+	}{
+		{
+			name:  "no training at all",
+			loads: make([]float64, 10),
+			day:   9,
+			want:  Day{},
+		},
+		{
+			name:  "a single workout",
+			loads: []float64{100},
+			day:   0,
+			//TSB is yesterday's form, and there was no yesterday
+			want: Day{Load: 100, Acute: 100.0 / 7, Chronic: 100.0 / 28, ACWR: 4, CTL: 100.0 / 42, ATL: 100.0 / 7},
+		},
+		{
+			name:  "the day after a single workout",
+			loads: []float64{100, 0},
+			day:   1,
+			want: Day{
+				Acute: 100.0 / 7, Chronic: 100.0 / 28, ACWR: 4,
+				CTL: 100.0 / 42 * (41.0 / 42), ATL: 100.0 / 7 * (6.0 / 7),
+				TSB: 100.0/42 - 100.0/7,
+			},
+		},
+		{
+			name:  "a workout leaves the acute window after 7 days",
+			loads: append([]float64{100}, make([]float64, 7)...),
+			day:   7,
+			want: Day{
+				Chronic: 100.0 / 28,
+				CTL:     100.0 / 42 * math.Pow(41.0/42, 7), ATL: 100.0 / 7 * math.Pow(6.0/7, 7),
+				TSB: 100.0/42*math.Pow(41.0/42, 6) - 100.0/7*math.Pow(6.0/7, 6),
+			},
+		},
+		{
+			name:  "and the chronic one after 28",
+			loads: append([]float64{100}, make([]float64, 28)...),
+			day:   28,
+			want: Day{
+				CTL: 100.0 / 42 * math.Pow(41.0/42, 28), ATL: 100.0 / 7 * math.Pow(6.0/7, 28),
+				TSB: 100.0/42*math.Pow(41.0/42, 27) - 100.0/7*math.Pow(6.0/7, 27),
+			},
+		},
+		{
+			name:  "a first week of steady training",
+			loads: steady(100, 7),
+			day:   6,
+			want: Day{
+				Load: 100, Acute: 100, Chronic: 25, ACWR: 4,
+				CTL: 100 * (1 - math.Pow(41.0/42, 7)), ATL: 100 * (1 - math.Pow(6.0/7, 7)),
+				TSB: 100*(1-math.Pow(41.0/42, 6)) - 100*(1-math.Pow(6.0/7, 6)),
+			},
+		},
+		{
+			name:  "steady training past the chronic window",
+			loads: steady(100, 40),
+			day:   39,
+			want: Day{
+				Load: 100, Acute: 100, Chronic: 100, ACWR: 1,
+				CTL: 100 * (1 - math.Pow(41.0/42, 40)), ATL: 100 * (1 - math.Pow(6.0/7, 40)),
+				TSB: 100*(1-math.Pow(41.0/42, 39)) - 100*(1-math.Pow(6.0/7, 39)),
+			},
+		},
+		{
+			name:  "a week off after a month of training",
+			loads: append(steady(100, 28), make([]float64, 7)...),
+			day:   34,
+			want: Day{
+				Chronic: 2100.0 / 28,
+				CTL:     100 * (1 - math.Pow(41.0/42, 28)) * math.Pow(41.0/42, 7),
+				ATL:     100 * (1 - math.Pow(6.0/7, 28)) * math.Pow(6.0/7, 7),
+				TSB:     100*(1-math.Pow(41.0/42, 28))*math.Pow(41.0/42, 6) - 100*(1-math.Pow(6.0/7, 28))*math.Pow(6.0/7, 6),
+			},
+		},
+		{
+			name:  "a spike after a month of light training",
+			loads: append(steady(50, 28), steady(200, 7)...),
+			day:   34,
+			want: Day{
+				Load: 200, Acute: 200, Chronic: (21*50 + 7*200) / 28.0, ACWR: 200 / ((21*50 + 7*200) / 28.0),
+				CTL: 50*(1-math.Pow(41.0/42, 28))*math.Pow(41.0/42, 7) + 200*(1-math.Pow(41.0/42, 7)),
+				ATL: 50*(1-math.Pow(6.0/7, 28))*math.Pow(6.0/7, 7) + 200*(1-math.Pow(6.0/7, 7)),
+				TSB: 50*(1-math.Pow(41.0/42, 28))*math.Pow(41.0/42, 6) + 200*(1-math.Pow(41.0/42, 6)) -
+					(50*(1-math.Pow(6.0/7, 28))*math.Pow(6.0/7, 6) + 200*(1-math.Pow(6.0/7, 6))),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := Compute(start, tt.loads)
+			if len(days) != len(tt.loads) {
+				t.Fatalf("got %d days for %d loads", len(days), len(tt.loads))
+			}
+
+			got := days[tt.day]
+			if want := start.AddDate(0, 0, tt.day); !got.Date.Equal(want) {
+				t.Errorf("day %d is dated %v, want %v", tt.day, got.Date, want)
+			}
+
+			checks := []struct {
+				name      string
+				got, want float64
+			}{
+				{"load", got.Load, tt.want.Load},
+				{"acute", got.Acute, tt.want.Acute},
+				{"chronic", got.Chronic, tt.want.Chronic},
+				{"ACWR", got.ACWR, tt.want.ACWR},
+				{"CTL", got.CTL, tt.want.CTL},
+				{"ATL", got.ATL, tt.want.ATL},
+				{"TSB", got.TSB, tt.want.TSB},
+			}
+			for _, c := range checks {
+				if !near(c.got, c.want) {
+					t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeNothing(t *testing.T) {
+	if days := Compute(time.Now(), nil); len(days) != 0 {
+		t.Errorf("no loads gave %d days", len(days))
+	}
+}
+
+func TestWarning(t *testing.T) {
+	tests := []struct {
+		name   string
+		day    Day
+		inBand bool
+		says   string
+	}{
+		{"no chronic load yet", Day{Acute: 50}, true, ""},
+		{"in band", Day{Acute: 100, Chronic: 100, ACWR: 1}, true, ""},
+		{"on the low edge", Day{Acute: 80, Chronic: 100, ACWR: 0.8}, true, ""},
+		{"on the high edge", Day{Acute: 130, Chronic: 100, ACWR: 1.3}, true, ""},
+		{"spiking", Day{Acute: 150, Chronic: 100, ACWR: 1.5}, false, "ACWR 1.50 is above 1.3"},
+		{"dropped off", Day{Chronic: 100}, false, "ACWR 0.00 is below 0.8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.day.InBand(); got != tt.inBand {
+				t.Errorf("InBand() = %v, want %v", got, tt.inBand)
+			}
+
+			w := tt.day.Warning()
+			if tt.says == "" && w != "" {
+				t.Errorf("warned %q, want nothing", w)
+			}
+			if !strings.Contains(w, tt.says) {
+				t.Errorf("warned %q, want it to say %q", w, tt.says)
+			}
+		})
+	}
+}
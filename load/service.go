@@ -0,0 +1,70 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// history fetched ahead of the requested window so the 28 day averages and
+// the 42 day CTL curve have warmed up by the first reported day
+const historyDays = 3 * CTLTimeConstant
+
+type WorkoutRangeProvider interface {
+	GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error)
+}
+
+// Service computes load metrics from workouts held in storage
+type Service struct {
+	store WorkoutRangeProvider
+}
+
+func NewService(s WorkoutRangeProvider) *Service {
+	return &Service{
+		store: s,
+	}
+}
+
+// Daily returns the load metrics for each of the n days ending on end (inclusive)
+func (s *Service) Daily(ctx context.Context, end time.Time, n int, userID int) ([]Day, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("can't report on %d days, it needs at least 1", n)
+	}
+
+	end = flexcreek.CivilDate(end)
+	first := end.AddDate(0, 0, -(n - 1))
+	start := first.AddDate(0, 0, -historyDays)
+
+	workouts, err := s.store.GetWorkoutsBetween(ctx, start, end, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := Compute(start, DailyLoads(workouts, start, end))
+
+	return days[historyDays:], nil
+}
+
+// DailyLoads buckets workouts into per-day load totals covering start through end (inclusive)
+func DailyLoads(workouts []*flexcreek.Workout, start time.Time, end time.Time) []float64 {
+	start = flexcreek.CivilDate(start)
+	end = flexcreek.CivilDate(end)
+
+	n := int(end.Sub(start).Hours()/24) + 1
+	if n < 0 {
+		n = 0
+	}
+
+	loads := make([]float64, n)
+	for _, w := range workouts {
+		i := int(flexcreek.CivilDate(w.WorkoutDate).Sub(start).Hours() / 24)
+		if i < 0 || i >= n {
+			continue
+		}
+		loads[i] += w.Load()
+	}
+
+	return loads
+}
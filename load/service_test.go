@@ -0,0 +1,157 @@
+package load
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// fakeRange hands back its workouts and records the range it was asked for
+type fakeRange struct {
+	workouts   []*flexcreek.Workout
+	start, end time.Time
+}
+
+func (s *fakeRange) GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error) {
+	s.start, s.end = start, end
+	return s.workouts, nil
+}
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDailyLoads(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tests := []struct {
+		name       string
+		workouts   []*flexcreek.Workout
+		start, end time.Time
+		want       []float64
+	}{
+		{
+			name:  "no workouts",
+			start: date(2026, 3, 1), end: date(2026, 3, 3),
+			want: []float64{0, 0, 0},
+		},
+		{
+			name: "gaps between days",
+			workouts: []*flexcreek.Workout{
+				{WorkoutDate: date(2026, 3, 1), SessionLoad: 300},
+				{WorkoutDate: date(2026, 3, 4), SessionLoad: 200},
+			},
+			start: date(2026, 3, 1), end: date(2026, 3, 5),
+			want: []float64{300, 0, 0, 200, 0},
+		},
+		{
+			name: "two workouts on a day add up, and rpe x minutes stands in for a missing load",
+			workouts: []*flexcreek.Workout{
+				{WorkoutDate: date(2026, 3, 2), SessionLoad: 300},
+				{WorkoutDate: date(2026, 3, 2), DurationMinutes: 30, RPE: 4},
+			},
+			start: date(2026, 3, 1), end: date(2026, 3, 2),
+			want: []float64{0, 420},
+		},
+		{
+			name: "workouts outside the range are left out",
+			workouts: []*flexcreek.Workout{
+				{WorkoutDate: date(2026, 2, 28), SessionLoad: 100},
+				{WorkoutDate: date(2026, 3, 1), SessionLoad: 200},
+				{WorkoutDate: date(2026, 3, 3), SessionLoad: 400},
+			},
+			start: date(2026, 3, 1), end: date(2026, 3, 2),
+			want: []float64{200, 0},
+		},
+		{
+			name: "dates count by their calendar day, whatever the zone or time",
+			workouts: []*flexcreek.Workout{
+				//still the 1st in Tokyo, though it's the 28th of February in UTC
+				{WorkoutDate: time.Date(2026, 3, 1, 1, 0, 0, 0, tokyo), SessionLoad: 100},
+			},
+			start: time.Date(2026, 3, 1, 23, 0, 0, 0, tokyo), end: date(2026, 3, 2),
+			want: []float64{100, 0},
+		},
+		{
+			name:  "a single day",
+			start: date(2026, 3, 1), end: date(2026, 3, 1),
+			workouts: []*flexcreek.Workout{{WorkoutDate: date(2026, 3, 1), SessionLoad: 50}},
+			want:     []float64{50},
+		},
+		{
+			name:  "an end before the start",
+			start: date(2026, 3, 2), end: date(2026, 3, 1),
+			want: []float64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DailyLoads(tt.workouts, tt.start, tt.end)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestServiceDaily(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		end   time.Time
+		n     int
+		first time.Time
+	}{
+		{"a monday to sunday week", date(2026, 3, 8), 7, date(2026, 3, 2)},
+		{"a sunday to saturday week across the new year", date(2026, 1, 3), 7, date(2025, 12, 28)},
+		{"a week across a month end", date(2026, 3, 3), 7, date(2026, 2, 25)},
+		{"just today, late in the evening somewhere west", time.Date(2026, 3, 8, 23, 30, 0, 0, time.FixedZone("PST", -8*60*60)), 1, date(2026, 3, 8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeRange{workouts: []*flexcreek.Workout{
+				//inside the history fetched ahead of the report, so it shows in the curves but not as a day
+				{WorkoutDate: tt.first.AddDate(0, 0, -10), SessionLoad: 420},
+				{WorkoutDate: tt.first, SessionLoad: 300},
+			}}
+
+			days, err := NewService(store).Daily(ctx, tt.end, tt.n, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(days) != tt.n {
+				t.Fatalf("got %d days, want %d", len(days), tt.n)
+			}
+			if !days[0].Date.Equal(tt.first) || !days[tt.n-1].Date.Equal(flexcreek.CivilDate(tt.end)) {
+				t.Errorf("the days run %v to %v, want %v to %v", days[0].Date, days[tt.n-1].Date, tt.first, flexcreek.CivilDate(tt.end))
+			}
+			if days[0].Load != 300 {
+				t.Errorf("the first day's load is %v, want 300", days[0].Load)
+			}
+			if days[0].Chronic != 720.0/28 || days[0].CTL <= 300.0/42 {
+				t.Errorf("the first day has chronic %v and CTL %v, want the earlier workout counted in both", days[0].Chronic, days[0].CTL)
+			}
+
+			//enough history is asked for to warm the 42 day curve up
+			if want := tt.first.AddDate(0, 0, -historyDays); !store.start.Equal(want) || !store.end.Equal(flexcreek.CivilDate(tt.end)) {
+				t.Errorf("asked storage for %v to %v, want %v to %v", store.start, store.end, want, flexcreek.CivilDate(tt.end))
+			}
+		})
+	}
+
+	if _, err := NewService(&fakeRange{}).Daily(ctx, date(2026, 3, 8), 0, 1); err == nil {
+		t.Error("a report of 0 days succeeded")
+	}
+}
@@ -0,0 +1,16 @@
+package flexcreek
+
+import (
+	"time"
+)
+
+// Measurement is a single body measurement, e.g. bodyweight, body fat or a tape measurement
+type Measurement struct {
+	ID         int       `db:"id"`
+	UserID     int       `db:"user_id"`
+	Metric     string    `db:"metric"`
+	Value      float64   `db:"value"`
+	Unit       string    `db:"unit"`
+	MeasuredOn time.Time `db:"measured_on"`
+	CreatedAt  time.Time `db:"created_at"`
+}
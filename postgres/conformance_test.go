@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/storetest"
+)
+
+// the suite needs a server to run against, e.g. one started with `make postgres-test-db`.
+// it's skipped when this isn't set, unless CI is, so a pipeline can't pass without running it
+const testDSNEnv = "FLEXCREEK_TEST_POSTGRES_DSN"
+
+// opens a store in a schema of its own, so tests don't see each other's rows and the
+// schema can be dropped afterwards whatever state the test left it in
+func openTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" && os.Getenv("CI") != "" {
+		t.Fatalf("%s isn't set, and the postgres suite can't be skipped in CI", testDSNEnv)
+	}
+	if dsn == "" {
+		t.Skipf("%s isn't set", testDSNEnv)
+	}
+
+	admin, err := Open(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("flexcreek_test_%d", time.Now().UnixNano())
+	if _, err := admin.db.ExecContext(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.db.ExecContext(context.Background(), "DROP SCHEMA "+schema+" CASCADE") })
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	s, err := Open(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) flexcreek.Store {
+		return openTestStorage(t)
+	})
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	s := openTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := migrations[len(migrations)-1].version; v != want {
+		t.Errorf("schema version = %d, want %d", v, want)
+	}
+}
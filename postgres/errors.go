@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// sqlstates for the constraint violations callers care about
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// translates driver errors into the flexcreek error values
+func translateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return flexcreek.ErrNotFound
+	}
+
+	var pe *pgconn.PgError
+	if errors.As(err, &pe) {
+		switch pe.Code {
+		case uniqueViolation:
+			return flexcreek.ErrConflict
+		case foreignKeyViolation:
+			//the row being written points at one that doesn't exist, e.g. a workout for a missing user
+			return flexcreek.ErrNotFound
+		}
+	}
+
+	return err
+}
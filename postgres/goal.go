@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const goalColumns = `
+		id,
+		user_id,
+		metric,
+		title_match,
+		target,
+		unit,
+		period,
+		start_date,
+		deadline,
+		created_at
+`
+
+// scans a single goal row selected with goalColumns
+func scanGoal(r rowScanner) (*flexcreek.Goal, error) {
+	var g flexcreek.Goal
+	var deadline sql.NullTime
+
+	if err := r.Scan(&g.ID, &g.UserID, &g.Metric, &g.Match, &g.Target, &g.Unit, &g.Period, &g.StartDate, &deadline, &g.CreatedAt); err != nil {
+		return nil, translateError(err)
+	}
+	g.StartDate = flexcreek.CivilDate(g.StartDate)
+	if deadline.Valid {
+		g.Deadline = flexcreek.CivilDate(deadline.Time)
+	}
+	g.CreatedAt = g.CreatedAt.UTC()
+
+	return &g, nil
+}
+
+// goals that start over every period have no deadline, which is stored as NULL
+func deadlineValue(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: civilDate(t), Valid: true}
+}
+
+func (s *Storage) CreateGoal(ctx context.Context, g *flexcreek.Goal) (int, error) {
+	if err := g.Validate(); err != nil {
+		return 0, err
+	}
+
+	qry := `
+		INSERT INTO goals (
+			user_id,
+			metric,
+			title_match,
+			target,
+			unit,
+			period,
+			start_date,
+			deadline
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+
+	var id int
+	err := s.querier().QueryRowContext(ctx, qry, g.UserID, g.Metric, g.Match, g.Target, g.Unit, g.Period, civilDate(g.StartDate), deadlineValue(g.Deadline)).Scan(&id)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return id, nil
+}
+
+// GetGoals returns all of a user's goals, oldest first
+func (s *Storage) GetGoals(ctx context.Context, userID int) ([]*flexcreek.Goal, error) {
+	qry := `
+		SELECT ` + goalColumns + `
+		FROM goals
+		WHERE user_id = $1
+		  AND ` + liveUser + `
+		ORDER BY id asc
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []*flexcreek.Goal
+	for rows.Next() {
+		g, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+func (s *Storage) DeleteGoal(ctx context.Context, id int, userID int) error {
+	qry := `
+		DELETE FROM goals
+		WHERE id = $1
+		  AND user_id = $2
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
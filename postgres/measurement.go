@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const measurementColumns = `
+		id,
+		user_id,
+		metric,
+		value,
+		unit,
+		measured_on,
+		created_at
+`
+
+// scans a single measurement row selected with measurementColumns
+func scanMeasurement(r rowScanner) (*flexcreek.Measurement, error) {
+	var m flexcreek.Measurement
+
+	if err := r.Scan(&m.ID, &m.UserID, &m.Metric, &m.Value, &m.Unit, &m.MeasuredOn, &m.CreatedAt); err != nil {
+		return nil, translateError(err)
+	}
+	m.MeasuredOn = flexcreek.CivilDate(m.MeasuredOn)
+	m.CreatedAt = m.CreatedAt.UTC()
+
+	return &m, nil
+}
+
+// scans and closes a set of measurement rows
+func scanMeasurements(rows *sql.Rows) ([]*flexcreek.Measurement, error) {
+	defer rows.Close()
+
+	var measurements []*flexcreek.Measurement
+	for rows.Next() {
+		m, err := scanMeasurement(rows)
+		if err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return measurements, nil
+}
+
+func (s *Storage) CreateMeasurement(ctx context.Context, m *flexcreek.Measurement) (int, error) {
+	qry := `
+		INSERT INTO measurements (
+			user_id,
+			metric,
+			value,
+			unit,
+			measured_on
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id int
+	if err := s.querier().QueryRowContext(ctx, qry, m.UserID, m.Metric, m.Value, m.Unit, civilDate(m.MeasuredOn)).Scan(&id); err != nil {
+		return 0, translateError(err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetMeasurementByID(ctx context.Context, id int, userID int) (*flexcreek.Measurement, error) {
+	qry := `
+		SELECT ` + measurementColumns + `
+		FROM measurements
+		WHERE id = $1
+		  AND user_id = $2
+		  AND ` + liveUser + `
+	`
+
+	return scanMeasurement(s.querier().QueryRowContext(ctx, qry, id, userID))
+}
+
+// GetLatestMeasurements returns a user's n most recent measurements across all metrics, newest first
+func (s *Storage) GetLatestMeasurements(ctx context.Context, n int, userID int) ([]*flexcreek.Measurement, error) {
+	qry := `
+		SELECT ` + measurementColumns + `
+		FROM measurements
+		WHERE user_id = $1
+		  AND ` + liveUser + `
+		ORDER BY measured_on desc, id desc
+		LIMIT $2
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry, userID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMeasurements(rows)
+}
+
+// GetMeasurementsByMetric returns every measurement of a single metric for a user, oldest first
+func (s *Storage) GetMeasurementsByMetric(ctx context.Context, metric string, userID int) ([]*flexcreek.Measurement, error) {
+	qry := `
+		SELECT ` + measurementColumns + `
+		FROM measurements
+		WHERE user_id = $1
+		  AND metric = $2
+		  AND ` + liveUser + `
+		ORDER BY measured_on asc, id asc
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry, userID, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMeasurements(rows)
+}
+
+func (s *Storage) UpdateMeasurement(ctx context.Context, m *flexcreek.Measurement) error {
+	qry := `
+		UPDATE measurements
+		SET metric = $1,
+		value = $2,
+		unit = $3,
+		measured_on = $4
+		WHERE id = $5
+		  AND user_id = $6
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, m.Metric, m.Value, m.Unit, civilDate(m.MeasuredOn), m.ID, m.UserID)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
+
+func (s *Storage) DeleteMeasurement(ctx context.Context, id int, userID int) error {
+	qry := `
+		DELETE FROM measurements
+		WHERE id = $1
+		  AND user_id = $2
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrations are plain sql files named NNN_description.sql, like the sqlite ones.
+// the numbering is separate from sqlite's, the two schemas move independently
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// key for the advisory lock Migrate holds, so two processes starting at once don't both apply a migration
+const migrateLockKey = 0x666c6578 // "flex"
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loads and sorts the embedded migrations by version
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrationFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	for _, f := range files {
+		name := strings.TrimPrefix(f, "migrations/")
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s is missing a version prefix", name)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version prefix: %w", name, err)
+		}
+
+		b, err := migrationFS.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{version: version, name: name, sql: string(b)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// there's no user_version pragma, so applied migrations are recorded in a table
+const createMigrationsTable = `
+	CREATE TABLE IF NOT EXISTS schema_migrations
+	(
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)
+`
+
+// SchemaVersion returns the newest migration applied to the database, 0 if it hasn't been migrated
+func (s *Storage) SchemaVersion(ctx context.Context) (int, error) {
+	if _, err := s.querier().ExecContext(ctx, createMigrationsTable); err != nil {
+		return 0, err
+	}
+
+	var v int
+	if err := s.querier().QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&v); err != nil {
+		return 0, err
+	}
+
+	return v, nil
+}
+
+// Migrate applies any embedded migrations newer than the database's schema version.
+// postgres can roll back ddl, so they all run in one transaction and either all apply or none do
+func (s *Storage) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	//released when the transaction ends
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrateLockKey); err != nil {
+		return err
+	}
+
+	//read the version under the lock, another process may have just migrated
+	current, err := (&Storage{db: s.db, tx: tx.Tx}).SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+			return fmt.Errorf("applying migration %s: %w", m.name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.version, m.name); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
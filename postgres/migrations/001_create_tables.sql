@@ -0,0 +1,53 @@
+-- the postgres schema starts out where the sqlite one had got to by its 011 migration,
+-- minus the tables only the single machine backend has (sync), and the ones later migrations add
+CREATE TABLE IF NOT EXISTS users
+(
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    uuid TEXT NOT NULL UNIQUE,
+    username TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    weight_unit TEXT NOT NULL DEFAULT 'kg',
+    distance_unit TEXT NOT NULL DEFAULT 'km',
+    first_day_of_week INTEGER NOT NULL DEFAULT 1,
+    date_format TEXT NOT NULL DEFAULT '2006-01-02',
+    time_zone TEXT NOT NULL DEFAULT 'Local',
+    -- bcrypt hash of the user's password, empty when no password has been set
+    password_hash TEXT NOT NULL DEFAULT '',
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS workouts
+(
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    uuid TEXT NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    short_description TEXT NOT NULL,
+    long_description TEXT,
+    workout_date DATE NOT NULL,
+    -- RFC3339 with the offset the workout started at, which a TIMESTAMPTZ would throw away
+    start_time TEXT,
+    duration_minutes INTEGER NOT NULL DEFAULT 0,
+    rpe INTEGER NOT NULL DEFAULT 0,
+    session_load DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ
+);
+
+-- serves the workout list, which pages through a user's workouts newest first
+CREATE INDEX IF NOT EXISTS idx_workouts_user_date ON workouts (user_id, workout_date DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_workouts_deleted_at ON workouts (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS api_tokens
+(
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT NOT NULL,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens (user_id);
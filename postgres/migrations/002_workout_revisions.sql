@@ -0,0 +1,18 @@
+-- the values a workout had before each edit, as in the sqlite schema. changed_by is the user who
+-- made the edit, which is the owner unless an admin token was used
+CREATE TABLE IF NOT EXISTS workout_revisions
+(
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    workout_id BIGINT NOT NULL REFERENCES workouts (id) ON DELETE CASCADE,
+    changed_by BIGINT NOT NULL,
+    short_description TEXT NOT NULL,
+    long_description TEXT,
+    workout_date DATE NOT NULL,
+    start_time TEXT,
+    duration_minutes INTEGER NOT NULL DEFAULT 0,
+    rpe INTEGER NOT NULL DEFAULT 0,
+    session_load DOUBLE PRECISION NOT NULL DEFAULT 0,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_revisions_workout_id ON workout_revisions (workout_id);
@@ -0,0 +1,31 @@
+-- measurements and goals, as in the sqlite schema. measurements are stored in canonical units
+CREATE TABLE IF NOT EXISTS measurements
+(
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    metric TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    unit TEXT NOT NULL,
+    measured_on DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_measurements_user_metric ON measurements (user_id, metric, measured_on);
+
+CREATE TABLE IF NOT EXISTS goals
+(
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    metric TEXT NOT NULL,
+    -- workout goals only count workouts whose title contains this, when it's set
+    title_match TEXT NOT NULL DEFAULT '',
+    target DOUBLE PRECISION NOT NULL,
+    unit TEXT NOT NULL DEFAULT '',
+    -- week, month or year for a goal that starts over, empty for one with a deadline
+    period TEXT NOT NULL DEFAULT '',
+    start_date DATE NOT NULL,
+    deadline DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals (user_id);
@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// ListTrashedUsers returns every user in the trash, most recently deleted first
+func (s *Storage) ListTrashedUsers(ctx context.Context) ([]*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at desc, id desc
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []*flexcreek.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// ListTrashedWorkouts returns a user's workouts in the trash, most recently deleted first
+func (s *Storage) ListTrashedWorkouts(ctx context.Context, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = $1
+		  AND deleted_at IS NOT NULL
+		ORDER BY deleted_at desc, id desc
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// RestoreUser takes a user out of the trash, along with the workouts that were trashed with them.
+// workouts deleted individually beforehand stay in the trash
+func (s *Storage) RestoreUser(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	//just the workouts DeleteUser trashed. if the user isn't in the trash nothing is flagged,
+	//and the user's update below finds nothing and rolls this back anyway
+	workoutsQry := `
+		UPDATE workouts
+		SET deleted_at = NULL, deleted_with_user = FALSE
+		WHERE user_id = $1
+		  AND deleted_with_user
+	`
+
+	if _, err := tx.ExecContext(ctx, workoutsQry, id); err != nil {
+		return err
+	}
+
+	qry := `
+		UPDATE users
+		SET deleted_at = NULL
+		WHERE id = $1
+		  AND deleted_at IS NOT NULL
+	`
+
+	res, err := tx.ExecContext(ctx, qry, id)
+	if err != nil {
+		return err
+	}
+
+	if err := expectRow(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestoreWorkout takes one of userID's workouts out of the trash. the user has to be restored first if they're trashed too
+func (s *Storage) RestoreWorkout(ctx context.Context, id int, userID int) error {
+	qry := `
+		UPDATE workouts
+		SET deleted_at = NULL, deleted_with_user = FALSE
+		WHERE id = $1
+		  AND user_id = $2
+		  AND deleted_at IS NOT NULL
+		  AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
+
+// PurgeTrash permanently removes users and workouts that were trashed before the cutoff, returning how many of each went.
+// a purged user takes all of their data with them
+func (s *Storage) PurgeTrash(ctx context.Context, before time.Time) (users int, workouts int, err error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := before.UTC().Truncate(time.Second)
+
+	//workouts go first so they're counted, revisions and tokens go with ON DELETE CASCADE
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM workouts
+		WHERE (deleted_at IS NOT NULL AND deleted_at < $1)
+		   OR user_id IN (SELECT id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1)`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	workouts = int(n)
+
+	res, err = tx.ExecContext(ctx, `
+		DELETE FROM users
+		WHERE deleted_at IS NOT NULL
+		  AND deleted_at < $1`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err = res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	users = int(n)
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return users, workouts, nil
+}
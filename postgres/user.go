@@ -0,0 +1,255 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/google/uuid"
+)
+
+// limits a user's measurements and goals to users that aren't in the trash
+const liveUser = `user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)`
+
+const userColumns = `
+		id,
+		uuid,
+		username,
+		created_at,
+		weight_unit,
+		distance_unit,
+		first_day_of_week,
+		date_format,
+		time_zone,
+		deleted_at
+`
+
+// scans a single user row selected with userColumns
+func scanUser(r rowScanner) (*flexcreek.User, error) {
+	var u flexcreek.User
+	p := &u.Preferences
+	var deletedAt sql.NullTime
+
+	if err := r.Scan(&u.ID, &u.UUID, &u.Username, &u.CreatedAt, &p.WeightUnit, &p.DistanceUnit, &p.FirstDayOfWeek, &p.DateFormat, &p.TimeZone, &deletedAt); err != nil {
+		return nil, translateError(err)
+	}
+	u.CreatedAt = u.CreatedAt.UTC()
+	if deletedAt.Valid {
+		u.DeletedAt = deletedAt.Time.UTC()
+	}
+
+	return &u, nil
+}
+
+// Create a new user with a new UUID
+func (s *Storage) CreateUser(ctx context.Context, username string) (int, error) {
+	qry := `
+		INSERT INTO users (uuid, username)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+
+	var id int
+	if err := s.querier().QueryRowContext(ctx, qry, uuid.NewString(), username).Scan(&id); err != nil {
+		return 0, s.usernameConflict(ctx, username, translateError(err))
+	}
+
+	return id, nil
+}
+
+// usernames stay taken while their user is in the trash, so a conflict with a trashed user says so
+func (s *Storage) usernameConflict(ctx context.Context, username string, err error) error {
+	if !errors.Is(err, flexcreek.ErrConflict) {
+		return err
+	}
+
+	var trashed bool
+	qry := `SELECT EXISTS (SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NOT NULL)`
+	if qerr := s.querier().QueryRowContext(ctx, qry, username).Scan(&trashed); qerr == nil && trashed {
+		return fmt.Errorf("%w: %w", flexcreek.ErrConflict, flexcreek.ErrNameInTrash)
+	}
+
+	return err
+}
+
+func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE username = $1
+		  AND deleted_at IS NULL
+	`
+
+	return scanUser(s.querier().QueryRowContext(ctx, qry, username))
+}
+
+func (s *Storage) GetUserByID(ctx context.Context, id int) (*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1
+		  AND deleted_at IS NULL
+	`
+
+	return scanUser(s.querier().QueryRowContext(ctx, qry, id))
+}
+
+// GetUserByUUID looks up a user by their public identifier
+func (s *Storage) GetUserByUUID(ctx context.Context, id string) (*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE uuid = $1
+		  AND deleted_at IS NULL
+	`
+
+	return scanUser(s.querier().QueryRowContext(ctx, qry, id))
+}
+
+// GetAllUsers returns every user outside the trash, oldest first
+func (s *Storage) GetAllUsers(ctx context.Context) ([]*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deleted_at IS NULL
+		ORDER BY id
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []*flexcreek.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// DeleteUser moves a user to the trash along with their workouts, all stamped with the same deleted_at
+func (s *Storage) DeleteUser(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qry := `
+		UPDATE users
+		SET deleted_at = $1
+		WHERE id = $2
+		  AND deleted_at IS NULL
+	`
+
+	now := trashTime()
+
+	res, err := tx.ExecContext(ctx, qry, now, id)
+	if err != nil {
+		return err
+	}
+
+	if err := expectRow(res); err != nil {
+		return err
+	}
+
+	//flagged so RestoreUser can tell these from workouts that were trashed on their own
+	workoutsQry := `
+		UPDATE workouts
+		SET deleted_at = $1, deleted_with_user = TRUE
+		WHERE user_id = $2
+		  AND deleted_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, workoutsQry, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateUserPreferences replaces a user's display preferences
+func (s *Storage) UpdateUserPreferences(ctx context.Context, id int, p flexcreek.Preferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	qry := `
+		UPDATE users
+		SET weight_unit = $1,
+		distance_unit = $2,
+		first_day_of_week = $3,
+		date_format = $4,
+		time_zone = $5
+		WHERE id = $6
+		  AND deleted_at IS NULL
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, p.WeightUnit, p.DistanceUnit, int(p.FirstDayOfWeek), p.DateFormat, p.TimeZone, id)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
+
+// SetPasswordHash stores a user's password hash. hashing is the caller's job (see the auth package)
+func (s *Storage) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	qry := `
+		UPDATE users
+		SET password_hash = $1
+		WHERE id = $2
+		  AND deleted_at IS NULL
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, hash, id)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
+
+// GetPasswordHash returns a user's password hash, which is empty if no password has been set
+func (s *Storage) GetPasswordHash(ctx context.Context, id int) (string, error) {
+	qry := `
+		SELECT password_hash
+		FROM users
+		WHERE id = $1
+		  AND deleted_at IS NULL
+	`
+
+	var hash string
+	if err := s.querier().QueryRowContext(ctx, qry, id).Scan(&hash); err != nil {
+		return "", translateError(err)
+	}
+
+	return hash, nil
+}
+
+// reports ErrNotFound when an update or delete didn't match a row
+func expectRow(res sql.Result) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,346 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/google/uuid"
+)
+
+// columns selected by every workout read query, in the order scanWorkout expects
+const workoutColumns = `
+		id,
+		uuid,
+		user_id,
+		short_description,
+		long_description,
+		workout_date,
+		start_time,
+		duration_minutes,
+		rpe,
+		session_load,
+		created_at,
+		deleted_at
+`
+
+// workout dates are sent as YYYY-MM-DD text so the server never shifts them by a time zone
+const dateLayout = "2006-01-02"
+
+// formats a workout's date for storage. the date is read in t's own zone, like CivilDate
+func civilDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+// start times keep their offset so they read back as the local time the workout started
+func startTimeValue(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: t.Format(time.RFC3339), Valid: true}
+}
+
+func parseStartTime(s sql.NullString) (time.Time, error) {
+	if !s.Valid || s.String == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s.String)
+}
+
+// a LIKE pattern matching text that contains q, with q's own wildcards escaped so they match literally
+func containsPattern(q string) string {
+	return "%" + likeEscaper.Replace(q) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// scans a single workout row selected with workoutColumns
+func scanWorkout(r rowScanner) (*flexcreek.Workout, error) {
+	var w flexcreek.Workout
+	var longDescription sql.NullString //the column is nullable
+	var startTime sql.NullString
+	var deletedAt sql.NullTime
+
+	err := r.Scan(&w.ID, &w.UUID, &w.UserID, &w.ShortDescription, &longDescription, &w.WorkoutDate, &startTime, &w.DurationMinutes, &w.RPE, &w.SessionLoad, &w.CreatedAt, &deletedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	w.LongDescription = longDescription.String
+	//pgx hands DATE columns back as midnight UTC already, this just makes sure
+	w.WorkoutDate = flexcreek.CivilDate(w.WorkoutDate)
+	w.CreatedAt = w.CreatedAt.UTC()
+	if deletedAt.Valid {
+		w.DeletedAt = deletedAt.Time.UTC()
+	}
+
+	if w.StartTime, err = parseStartTime(startTime); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// scans and closes a set of workout rows
+func scanWorkouts(rows *sql.Rows) ([]*flexcreek.Workout, error) {
+	defer rows.Close()
+
+	var workouts []*flexcreek.Workout
+
+	for rows.Next() {
+		w, err := scanWorkout(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		workouts = append(workouts, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return workouts, nil
+}
+
+// CreateWorkout inserts a workout, giving it a new UUID unless it already has one
+func (s *Storage) CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error) {
+	if w.UUID == "" {
+		w.UUID = uuid.NewString()
+	}
+
+	qry := `
+		INSERT INTO workouts (
+			uuid,
+			user_id,
+			short_description,
+			long_description,
+			workout_date,
+			start_time,
+			duration_minutes,
+			rpe,
+			session_load
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	var id int
+	err := s.querier().QueryRowContext(ctx, qry, w.UUID, w.UserID, w.ShortDescription, w.LongDescription, civilDate(w.WorkoutDate), startTimeValue(w.StartTime), w.DurationMinutes, w.RPE, w.SessionLoad).Scan(&id)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE id = $1
+		  AND user_id = $2
+		  AND deleted_at IS NULL
+	`
+
+	return scanWorkout(s.querier().QueryRowContext(ctx, qry, id, userID))
+}
+
+// GetWorkoutByUUID looks up one of userID's workouts by its public identifier
+func (s *Storage) GetWorkoutByUUID(ctx context.Context, id string, userID int) (*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE uuid = $1
+		  AND user_id = $2
+		  AND deleted_at IS NULL
+	`
+
+	return scanWorkout(s.querier().QueryRowContext(ctx, qry, id, userID))
+}
+
+func (s *Storage) GetWorkoutByDate(ctx context.Context, date time.Time, userID int) (*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = $1
+		  AND workout_date = $2
+		  AND deleted_at IS NULL
+		ORDER BY id
+		LIMIT 1
+	`
+
+	return scanWorkout(s.querier().QueryRowContext(ctx, qry, userID, civilDate(date)))
+}
+
+func (s *Storage) GetLatestWorkouts(ctx context.Context, n int, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = $1
+		  AND deleted_at IS NULL
+		ORDER BY workout_date desc, id desc
+		LIMIT $2
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry, userID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// GetWorkoutsBetween returns a user's workouts dated between start and end (inclusive), oldest first
+func (s *Storage) GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = $1
+		  AND workout_date BETWEEN $2 AND $3
+		  AND deleted_at IS NULL
+		ORDER BY workout_date asc, id asc
+	`
+
+	rows, err := s.querier().QueryContext(ctx, qry, userID, civilDate(start), civilDate(end))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// ListWorkouts returns a page of a user's workouts matching the filter, newest first
+func (s *Storage) ListWorkouts(ctx context.Context, f flexcreek.WorkoutFilter, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = $1
+		  AND deleted_at IS NULL
+	`
+	args := []any{userID}
+
+	//appends v to the arguments and returns its placeholder
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if !f.From.IsZero() {
+		qry += " AND workout_date >= " + arg(civilDate(f.From))
+	}
+	if !f.To.IsZero() {
+		qry += " AND workout_date <= " + arg(civilDate(f.To))
+	}
+	if f.Query != "" {
+		//ILIKE matches sqlite's LIKE, which ignores case
+		pattern := arg(containsPattern(f.Query))
+		qry += " AND (short_description ILIKE " + pattern + ` ESCAPE '\' OR long_description ILIKE ` + pattern + ` ESCAPE '\')`
+	}
+
+	//LIMIT NULL is no limit
+	limit := sql.NullInt64{Int64: int64(f.Limit), Valid: f.Limit > 0}
+	qry += " ORDER BY workout_date desc, id desc LIMIT " + arg(limit) + " OFFSET " + arg(f.Offset)
+
+	rows, err := s.querier().QueryContext(ctx, qry, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// UpdateWorkout replaces a workout's editable fields, keeping the old values as a revision.
+// saving a workout without changing anything leaves no revision behind
+func (s *Storage) UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE id = $1
+		  AND user_id = $2
+		  AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	old, err := scanWorkout(tx.QueryRowContext(ctx, current, w.ID, w.UserID))
+	if err != nil {
+		return err
+	}
+
+	if len(flexcreek.DiffWorkouts(old, w)) == 0 {
+		return nil
+	}
+
+	changedBy, ok := flexcreek.ActorFrom(ctx)
+	if !ok {
+		changedBy = w.UserID
+	}
+
+	if err := saveRevision(ctx, tx, w.ID, w.UserID, changedBy); err != nil {
+		return err
+	}
+
+	qry := `
+		UPDATE workouts
+		SET short_description = $1,
+		long_description = $2,
+		workout_date = $3,
+		start_time = $4,
+		duration_minutes = $5,
+		rpe = $6,
+		session_load = $7
+		WHERE id = $8
+		  AND user_id = $9
+		  AND deleted_at IS NULL
+	`
+
+	res, err := tx.ExecContext(ctx, qry, w.ShortDescription, w.LongDescription, civilDate(w.WorkoutDate), startTimeValue(w.StartTime), w.DurationMinutes, w.RPE, w.SessionLoad, w.ID, w.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := expectRow(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteWorkout moves a workout owned by userID to the trash. workouts belonging to anyone else are reported as not found
+func (s *Storage) DeleteWorkout(ctx context.Context, id int, userID int) error {
+	qry := `
+		UPDATE workouts
+		SET deleted_at = $1
+		WHERE id = $2
+		  AND user_id = $3
+		  AND deleted_at IS NULL
+	`
+
+	res, err := s.querier().ExecContext(ctx, qry, trashTime(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectRow(res)
+}
+
+// CloneWorkout saves a copy of one of userID's workouts on date, returning the copy's ID.
+// the original's revisions stay with it, the copy starts with no history
+func (s *Storage) CloneWorkout(ctx context.Context, id int, userID int, date time.Time) (int, error) {
+	w, err := s.GetWorkoutByID(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.CreateWorkout(ctx, w.CopyTo(date))
+}
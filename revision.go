@@ -0,0 +1,85 @@
+package flexcreek
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// WorkoutRevision is a snapshot of a workout taken just before an edit
+type WorkoutRevision struct {
+	ID        int       `db:"id"`
+	Workout   Workout   // the values before the edit. ID and UserID are the workout's
+	ChangedBy int       `db:"changed_by"`
+	ChangedAt time.Time `db:"changed_at"`
+}
+
+// FieldChange is one field that differs between two versions of a workout, formatted for display
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DiffWorkouts lists the fields that differ between two versions of a workout, in form order.
+// values are compared exactly, so an edit that only changes whitespace still counts.
+// the UUID is only compared when both versions have one, since forms and revisions don't carry it
+func DiffWorkouts(old, new *Workout) []FieldChange {
+	fields := []struct {
+		name     string
+		old, new string
+		same     bool
+	}{
+		{"uuid", old.UUID, new.UUID, old.UUID == new.UUID || old.UUID == "" || new.UUID == ""},
+		{"short description", old.ShortDescription, new.ShortDescription, old.ShortDescription == new.ShortDescription},
+		{"long description", old.LongDescription, new.LongDescription, old.LongDescription == new.LongDescription},
+		{"date", old.WorkoutDate.Format("2006-01-02"), new.WorkoutDate.Format("2006-01-02"), CivilDate(old.WorkoutDate).Equal(CivilDate(new.WorkoutDate))},
+		{"start time", formatStartTime(old.StartTime), formatStartTime(new.StartTime), sameStartTime(old.StartTime, new.StartTime)},
+		{"duration", strconv.Itoa(old.DurationMinutes), strconv.Itoa(new.DurationMinutes), old.DurationMinutes == new.DurationMinutes},
+		{"rpe", strconv.Itoa(old.RPE), strconv.Itoa(new.RPE), old.RPE == new.RPE},
+		{"session load", strconv.FormatFloat(old.SessionLoad, 'f', -1, 64), strconv.FormatFloat(new.SessionLoad, 'f', -1, 64), old.SessionLoad == new.SessionLoad},
+	}
+
+	var changes []FieldChange
+	for _, f := range fields {
+		if !f.same {
+			changes = append(changes, FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+
+	return changes
+}
+
+// reports whether two start times are the same instant with the same offset.
+// the same wall time in another zone, or a different second, is a different start
+func sameStartTime(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() == b.IsZero()
+	}
+
+	_, aOffset := a.Zone()
+	_, bOffset := b.Zone()
+	return a.Equal(b) && aOffset == bOffset
+}
+
+func formatStartTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("15:04 -07:00")
+}
+
+type actorKey struct{}
+
+// WithActor records which user is making changes through ctx, for the revision history.
+// storage falls back to the workout's owner when no actor is set
+func WithActor(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFrom returns the user set with WithActor, if any
+func ActorFrom(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(actorKey{}).(int)
+	return id, ok
+}
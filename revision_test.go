@@ -0,0 +1,62 @@
+package flexcreek
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffWorkouts(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	start := time.Date(2026, 3, 1, 7, 30, 0, 0, est)
+	base := Workout{
+		UUID:             "9b2f6c1e-0d7a-4a4e-9a61-0c1f6a2d9e11",
+		ShortDescription: "Easy run",
+		LongDescription:  "3 miles\nflat",
+		WorkoutDate:      time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		StartTime:        start,
+		DurationMinutes:  30,
+		RPE:              4,
+		SessionLoad:      120,
+	}
+
+	tests := []struct {
+		name   string
+		edit   func(w *Workout)
+		fields []string
+	}{
+		{"unchanged", func(w *Workout) {}, nil},
+		{"trailing space", func(w *Workout) { w.ShortDescription += " " }, []string{"short description"}},
+		{"only a newline", func(w *Workout) { w.LongDescription += "\n" }, []string{"long description"}},
+		{"another uuid", func(w *Workout) { w.UUID = "0f0c2d39-3e0a-4f43-8d0e-5bb0ba4e7a90" }, []string{"uuid"}},
+		{"no uuid given", func(w *Workout) { w.UUID = "" }, nil},
+		{"a second later", func(w *Workout) { w.StartTime = start.Add(time.Second) }, []string{"start time"}},
+		{"the same instant in utc", func(w *Workout) { w.StartTime = start.UTC() }, []string{"start time"}},
+		{"the same wall time in another zone", func(w *Workout) {
+			w.StartTime = time.Date(2026, 3, 1, 7, 30, 0, 0, time.UTC)
+		}, []string{"start time"}},
+		{"start time cleared", func(w *Workout) { w.StartTime = time.Time{} }, []string{"start time"}},
+		{"several", func(w *Workout) { w.RPE, w.SessionLoad = 5, 150 }, []string{"rpe", "session load"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edited := base
+			tt.edit(&edited)
+
+			changes := DiffWorkouts(&base, &edited)
+			var fields []string
+			for _, c := range changes {
+				fields = append(fields, c.Field)
+			}
+
+			if len(fields) != len(tt.fields) {
+				t.Fatalf("changed fields are %v, want %v", fields, tt.fields)
+			}
+			for i := range fields {
+				if fields[i] != tt.fields[i] {
+					t.Errorf("changed fields are %v, want %v", fields, tt.fields)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/archive"
+)
+
+// ImportArchive adds an exported document's users and workouts in a single transaction.
+// rows whose uuid is already in the database are updated in place, and brought back if they were in the trash
+func (s *Storage) ImportArchive(ctx context.Context, doc *archive.Document) (users int, workouts int, err error) {
+	err = s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, u := range doc.Users {
+			userID, err := importUser(ctx, tx, u)
+			if err != nil {
+				return err
+			}
+			users++
+
+			for _, w := range u.Workouts {
+				if err := importWorkout(ctx, tx, w, userID); err != nil {
+					return err
+				}
+				workouts++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return users, workouts, nil
+}
+
+// looks up a row's id by uuid, trashed or not. 0 means there's no such row
+func idByUUID(ctx context.Context, tx *sql.Tx, table string, id string) (int, error) {
+	var rowID int
+	err := tx.QueryRowContext(ctx, "SELECT id FROM "+table+" WHERE uuid = ?", id).Scan(&rowID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return rowID, err
+}
+
+func importUser(ctx context.Context, tx *sql.Tx, u archive.User) (int, error) {
+	p := flexcreek.Preferences{
+		WeightUnit:     u.WeightUnit,
+		DistanceUnit:   u.DistanceUnit,
+		FirstDayOfWeek: time.Weekday(u.FirstDayOfWeek),
+		DateFormat:     u.DateFormat,
+		TimeZone:       u.TimeZone,
+	}
+	if err := p.Validate(); err != nil {
+		return 0, fmt.Errorf("user %s: %w", u.UUID, err)
+	}
+
+	rowID, err := idByUUID(ctx, tx, "users", u.UUID)
+	if err != nil {
+		return 0, err
+	}
+
+	args := []any{u.Username, p.WeightUnit, p.DistanceUnit, p.FirstDayOfWeek, p.DateFormat, p.TimeZone}
+
+	if rowID == 0 {
+		qry := `
+			INSERT INTO users (username, weight_unit, distance_unit, first_day_of_week, date_format, time_zone, created_at, uuid)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`
+
+		var res sql.Result
+		res, err = tx.ExecContext(ctx, qry, append(args, utcTimestamp(u.CreatedAt), u.UUID)...)
+		if err == nil {
+			var id int64
+			id, err = res.LastInsertId()
+			rowID = int(id)
+		}
+	} else {
+		qry := `
+			UPDATE users
+			SET username = ?, weight_unit = ?, distance_unit = ?, first_day_of_week = ?, date_format = ?, time_zone = ?, deleted_at = NULL
+			WHERE id = ?
+		`
+
+		_, err = tx.ExecContext(ctx, qry, append(args, rowID)...)
+		if err == nil {
+			//the user is out of the trash now, so workouts left behind there are on their own.
+			//the ones in the archive are brought back as they're imported
+			_, err = tx.ExecContext(ctx, "UPDATE workouts SET deleted_with_user = 0 WHERE user_id = ? AND deleted_with_user = 1", rowID)
+		}
+	}
+
+	if errors.Is(translateError(err), flexcreek.ErrConflict) {
+		return 0, fmt.Errorf("user %s: a different user is already called %s", u.UUID, u.Username)
+	}
+
+	return rowID, err
+}
+
+func importWorkout(ctx context.Context, tx *sql.Tx, w archive.Workout, userID int) error {
+	date, err := time.Parse(dateLayout, w.WorkoutDate)
+	if err != nil {
+		return fmt.Errorf("workout %s: %w", w.UUID, err)
+	}
+
+	start, err := w.Start()
+	if err != nil {
+		return fmt.Errorf("workout %s: %w", w.UUID, err)
+	}
+
+	rowID, err := idByUUID(ctx, tx, "workouts", w.UUID)
+	if err != nil {
+		return err
+	}
+
+	args := []any{userID, w.ShortDescription, w.LongDescription, civilDate(date), startTimeValue(start), w.DurationMinutes, w.RPE, w.SessionLoad}
+
+	if rowID == 0 {
+		qry := `
+			INSERT INTO workouts (user_id, short_description, long_description, workout_date, start_time, duration_minutes, rpe, session_load, created_at, uuid)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+
+		_, err = tx.ExecContext(ctx, qry, append(args, utcTimestamp(w.CreatedAt), w.UUID)...)
+		return err
+	}
+
+	qry := `
+		UPDATE workouts
+		SET user_id = ?, short_description = ?, long_description = ?, workout_date = ?, start_time = ?, duration_minutes = ?, rpe = ?, session_load = ?, deleted_at = NULL, deleted_with_user = 0
+		WHERE id = ?
+	`
+
+	_, err = tx.ExecContext(ctx, qry, append(args, rowID)...)
+	return err
+}
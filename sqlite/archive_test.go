@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/archive"
+)
+
+// exports src and reads the written document back, as export and import would through a file
+func roundTrip(t *testing.T, src *Storage) *archive.Document {
+	t.Helper()
+
+	doc, err := archive.Export(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	doc, err = archive.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newTestStorage(t)
+
+	alice, err := src.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefs := flexcreek.Preferences{WeightUnit: "lb", DistanceUnit: "mi", FirstDayOfWeek: time.Sunday, DateFormat: "01/02/2006", TimeZone: "UTC"}
+	if err := src.UpdateUserPreferences(ctx, alice, prefs); err != nil {
+		t.Fatal(err)
+	}
+	bob, err := src.CreateUser(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a morning run in New York and an evening one in Tokyo, which is the previous day in UTC
+	starts := []time.Time{
+		time.Date(2026, 3, 1, 7, 30, 0, 0, time.FixedZone("EST", -5*60*60)),
+		time.Date(2026, 3, 2, 6, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+		{},
+	}
+	for i, start := range starts {
+		w := &flexcreek.Workout{UserID: alice, ShortDescription: "Run", LongDescription: "easy", WorkoutDate: time.Date(2026, 3, 1+i, 0, 0, 0, 0, time.UTC), StartTime: start, DurationMinutes: 40, RPE: 5, SessionLoad: 200}
+		if _, err := src.CreateWorkout(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := src.CreateWorkout(ctx, &flexcreek.Workout{UserID: bob, ShortDescription: "Lift", WorkoutDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := newTestStorage(t)
+	doc := roundTrip(t, src)
+	users, workouts, err := dst.ImportArchive(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users != 2 || workouts != 4 {
+		t.Errorf("imported %d users and %d workouts, want 2 and 4", users, workouts)
+	}
+
+	srcUsers, err := src.GetAllUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, su := range srcUsers {
+		du, err := dst.GetUserByUUID(ctx, su.UUID)
+		if err != nil {
+			t.Fatalf("user %s wasn't imported: %v", su.Username, err)
+		}
+		if du.Username != su.Username || du.Preferences != su.Preferences || !du.CreatedAt.Equal(su.CreatedAt.Truncate(time.Second)) {
+			t.Errorf("imported %+v, want %+v", *du, *su)
+		}
+
+		srcWorkouts, err := src.ListWorkouts(ctx, flexcreek.WorkoutFilter{}, su.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sw := range srcWorkouts {
+			dw, err := dst.GetWorkoutByUUID(ctx, sw.UUID, du.ID)
+			if err != nil {
+				t.Fatalf("workout %s wasn't imported for %s: %v", sw.UUID, su.Username, err)
+			}
+			if dw.ShortDescription != sw.ShortDescription || dw.LongDescription != sw.LongDescription || !dw.WorkoutDate.Equal(sw.WorkoutDate) ||
+				dw.DurationMinutes != sw.DurationMinutes || dw.RPE != sw.RPE || dw.SessionLoad != sw.SessionLoad {
+				t.Errorf("imported %+v, want %+v", *dw, *sw)
+			}
+
+			_, dOffset := dw.StartTime.Zone()
+			_, sOffset := sw.StartTime.Zone()
+			if !dw.StartTime.Equal(sw.StartTime) || dOffset != sOffset || dw.StartTime.IsZero() != sw.StartTime.IsZero() {
+				t.Errorf("workout %s starts %v, want %v in the same offset", sw.UUID, dw.StartTime, sw.StartTime)
+			}
+		}
+	}
+
+	//importing again changes nothing, and doesn't add a second copy of anything
+	if users, workouts, err := dst.ImportArchive(ctx, doc); err != nil || users != 2 || workouts != 4 {
+		t.Fatalf("the second import gave %d users, %d workouts and %v", users, workouts, err)
+	}
+	again := roundTrip(t, dst)
+	if len(again.Users) != 2 {
+		t.Fatalf("after importing twice there are %d users", len(again.Users))
+	}
+	for i, u := range again.Users {
+		if u.UUID != doc.Users[i].UUID || len(u.Workouts) != len(doc.Users[i].Workouts) {
+			t.Errorf("after importing twice user %s has %d workouts, want %s with %d", u.UUID, len(u.Workouts), doc.Users[i].UUID, len(doc.Users[i].Workouts))
+			continue
+		}
+		for j, w := range u.Workouts {
+			if w != doc.Users[i].Workouts[j] {
+				t.Errorf("after importing twice workout %d is %+v, want %+v", j, w, doc.Users[i].Workouts[j])
+			}
+		}
+	}
+
+	//an import updates the rows it made the first time, and brings them back from the trash
+	dstAlice, err := dst.GetUserByUUID(ctx, doc.Users[0].UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashed, err := dst.GetWorkoutByUUID(ctx, doc.Users[0].Workouts[0].UUID, dstAlice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.DeleteWorkout(ctx, trashed.ID, dstAlice.ID); err != nil {
+		t.Fatal(err)
+	}
+	doc.Users[0].Workouts[1].ShortDescription = "Long run"
+	if _, _, err := dst.ImportArchive(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	if w, err := dst.GetWorkoutByUUID(ctx, trashed.UUID, dstAlice.ID); err != nil || w.ID != trashed.ID {
+		t.Errorf("the trashed workout came back as %v, %v, want the same row", w, err)
+	}
+	if w, err := dst.GetWorkoutByUUID(ctx, doc.Users[0].Workouts[1].UUID, dstAlice.ID); err != nil || w.ShortDescription != "Long run" {
+		t.Errorf("the edited workout came back as %v, %v", w, err)
+	}
+}
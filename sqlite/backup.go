@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	driver "modernc.org/sqlite"
+)
+
+// Snapshot writes a consistent copy of the database to path using VACUUM INTO.
+// it's safe to run while the database is in use, and path must not already exist
+func (s *Storage) Snapshot(ctx context.Context, path string) error {
+	_, err := s.db.ExecContext(ctx, "VACUUM INTO ?", path)
+	return err
+}
+
+// FileSchemaVersion opens the database file at path read-only and returns its schema version.
+// it fails if the file isn't a sqlite database
+func FileSchemaVersion(ctx context.Context, path string) (int, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&check); err != nil {
+		return 0, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if check != "ok" {
+		return 0, fmt.Errorf("%s failed its integrity check: %s", path, check)
+	}
+
+	return NewStorage(db).SchemaVersion(ctx)
+}
+
+// Restore replaces the whole contents of the database with the database file at path,
+// using sqlite's online backup api so open connections stay valid
+func (s *Storage) Restore(ctx context.Context, path string) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(dc any) error {
+		r, ok := dc.(interface {
+			NewRestore(srcURI string) (*driver.Backup, error)
+		})
+		if !ok {
+			return fmt.Errorf("the sqlite driver doesn't support restoring backups")
+		}
+
+		b, err := r.NewRestore(path)
+		if err != nil {
+			return err
+		}
+
+		//copy every page in one step
+		for more := true; more; {
+			if more, err = b.Step(-1); err != nil {
+				b.Finish()
+				return err
+			}
+		}
+
+		return b.Finish()
+	})
+}
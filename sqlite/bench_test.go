@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const benchWorkouts = 5000
+
+// each benchmark runs against a file database opened the way the app opens it, once with the statement
+// cache and once re-parsing every query, and once more with sql.Open's defaults for comparison
+var benchSetups = []struct {
+	name string
+	open func(b *testing.B) *Storage
+}{
+	{"prepared", func(b *testing.B) *Storage {
+		s, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { s.Close() })
+		return s
+	}},
+	{"unprepared", func(b *testing.B) *Storage {
+		s, err := Open(filepath.Join(b.TempDir(), "bench.db"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { s.Close() })
+		s.stmts = nil
+		return s
+	}},
+	{"defaults", func(b *testing.B) *Storage {
+		db, err := sql.Open("sqlite", filepath.Join(b.TempDir(), "bench.db"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Cleanup(func() { db.Close() })
+		return &Storage{db: db}
+	}},
+}
+
+func benchStorage(b *testing.B, open func(b *testing.B) *Storage) (*Storage, int) {
+	b.Helper()
+
+	s := open(b)
+	ctx := context.Background()
+	if err := s.Migrate(ctx); err != nil {
+		b.Fatal(err)
+	}
+
+	userID, err := s.CreateUser(ctx, "bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return s, userID
+}
+
+func benchWorkout(userID int, i int) *flexcreek.Workout {
+	return &flexcreek.Workout{
+		UserID:           userID,
+		ShortDescription: "workout " + strconv.Itoa(i),
+		LongDescription:  "5 rounds of something hard",
+		WorkoutDate:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i%2000),
+		DurationMinutes:  45,
+		RPE:              7,
+	}
+}
+
+func BenchmarkCreateWorkout(b *testing.B) {
+	for _, setup := range benchSetups {
+		b.Run(setup.name, func(b *testing.B) {
+			s, userID := benchStorage(b, setup.open)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.CreateWorkout(ctx, benchWorkout(userID, i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkListWorkouts(b *testing.B) {
+	for _, setup := range benchSetups {
+		b.Run(setup.name, func(b *testing.B) {
+			s, userID := benchStorage(b, setup.open)
+			ctx := context.Background()
+
+			for i := 0; i < benchWorkouts; i++ {
+				if _, err := s.CreateWorkout(ctx, benchWorkout(userID, i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			//the first page, which is what the tui, api and web ui show most
+			f := flexcreek.WorkoutFilter{Limit: 20}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.ListWorkouts(ctx, f, userID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		storetest.Run(t, func(t *testing.T) flexcreek.Store {
+			//a file opened the way the app opens it, so WAL and the rest of the connection settings apply
+			s, err := Open(filepath.Join(t.TempDir(), "flexcreek.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { s.Close() })
+
+			if err := s.Migrate(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			return s
+		})
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		storetest.Run(t, func(t *testing.T) flexcreek.Store {
+			return newTestStorage(t)
+		})
+	})
+}
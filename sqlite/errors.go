@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/ekholme/flexcreek"
+	driver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// translates driver errors into the flexcreek error values
+func translateError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return flexcreek.ErrNotFound
+	}
+
+	var se *driver.Error
+	if errors.As(err, &se) {
+		switch se.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+			return flexcreek.ErrConflict
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			//the row being written points at one that doesn't exist, e.g. a workout for a missing user
+			return flexcreek.ErrNotFound
+		}
+	}
+
+	return err
+}
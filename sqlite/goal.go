@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const goalColumns = `
+		id,
+		user_id,
+		metric,
+		title_match,
+		target,
+		unit,
+		period,
+		start_date,
+		deadline,
+		created_at
+`
+
+// scans a single goal row selected with goalColumns
+func scanGoal(r rowScanner) (*flexcreek.Goal, error) {
+	var g flexcreek.Goal
+	var startDate string
+	var deadline sql.NullString
+
+	if err := r.Scan(&g.ID, &g.UserID, &g.Metric, &g.Match, &g.Target, &g.Unit, &g.Period, &startDate, &deadline, &g.CreatedAt); err != nil {
+		return nil, translateError(err)
+	}
+
+	var err error
+	if g.StartDate, err = time.Parse(dateLayout, startDate); err != nil {
+		return nil, err
+	}
+	if deadline.Valid {
+		if g.Deadline, err = time.Parse(dateLayout, deadline.String); err != nil {
+			return nil, err
+		}
+	}
+
+	return &g, nil
+}
+
+// goals that start over every period have no deadline, which is stored as NULL
+func deadlineValue(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: civilDate(t), Valid: true}
+}
+
+func (s *Storage) CreateGoal(ctx context.Context, g *flexcreek.Goal) (int, error) {
+	if err := g.Validate(); err != nil {
+		return 0, err
+	}
+
+	qry := `
+		INSERT INTO goals (
+			user_id,
+			metric,
+			title_match,
+			target,
+			unit,
+			period,
+			start_date,
+			deadline
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := s.exec(ctx, qry, g.UserID, g.Metric, g.Match, g.Target, g.Unit, g.Period, civilDate(g.StartDate), deadlineValue(g.Deadline))
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetGoals returns all of a user's goals, oldest first
+func (s *Storage) GetGoals(ctx context.Context, userID int) ([]*flexcreek.Goal, error) {
+	qry := `
+		SELECT ` + goalColumns + `
+		FROM goals
+		WHERE user_id = ?
+		  AND ` + liveUser + `
+		ORDER BY id asc
+	`
+
+	rows, err := s.query(ctx, qry, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []*flexcreek.Goal
+	for rows.Next() {
+		g, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+func (s *Storage) DeleteGoal(ctx context.Context, id int, userID int) error {
+	qry := `
+		DELETE FROM goals
+		WHERE id = ?
+		  AND user_id = ?
+	`
+
+	res, err := s.exec(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func TestGoalsRoundTrip(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := s.CreateUser(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	goals := []flexcreek.Goal{
+		{UserID: alice, Metric: flexcreek.GoalMinutes, Match: "run", Target: 150, Period: flexcreek.PeriodWeek,
+			StartDate: time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{UserID: alice, Metric: "deadlift", Target: 200, Unit: "kg",
+			StartDate: time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), Deadline: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)},
+	}
+	for i := range goals {
+		if goals[i].ID, err = s.CreateGoal(ctx, &goals[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.GetGoals(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(goals) {
+		t.Fatalf("got %d goals, want %d", len(got), len(goals))
+	}
+	for i, g := range got {
+		g.CreatedAt = time.Time{}
+		if *g != goals[i] {
+			t.Errorf("goal %d read back as %+v, want %+v", i, *g, goals[i])
+		}
+	}
+
+	if _, err := s.CreateGoal(ctx, &flexcreek.Goal{UserID: alice, Metric: "deadlift", Target: 200}); err == nil {
+		t.Error("a goal without a period or a deadline was saved")
+	}
+
+	//bob can neither see nor delete alice's goals
+	if got, err := s.GetGoals(ctx, bob); err != nil || len(got) != 0 {
+		t.Errorf("bob's goals are %v (%v), want none", got, err)
+	}
+	if err := s.DeleteGoal(ctx, goals[0].ID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("bob deleting alice's goal returned %v, want ErrNotFound", err)
+	}
+
+	if err := s.DeleteGoal(ctx, goals[0].ID, alice); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetGoals(ctx, alice); len(got) != 1 || got[0].ID != goals[1].ID {
+		t.Errorf("after the delete alice has %v, want just the deadlift goal", got)
+	}
+}
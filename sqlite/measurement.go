@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const measurementColumns = `
+		id,
+		user_id,
+		metric,
+		value,
+		unit,
+		measured_on,
+		created_at
+`
+
+// scans a single measurement row selected with measurementColumns
+func scanMeasurement(r rowScanner) (*flexcreek.Measurement, error) {
+	var m flexcreek.Measurement
+	var measuredOn string
+
+	if err := r.Scan(&m.ID, &m.UserID, &m.Metric, &m.Value, &m.Unit, &measuredOn, &m.CreatedAt); err != nil {
+		return nil, translateError(err)
+	}
+
+	t, err := time.Parse("2006-01-02", measuredOn)
+	if err != nil {
+		return nil, err
+	}
+	m.MeasuredOn = t
+
+	return &m, nil
+}
+
+// scans and closes a set of measurement rows
+func scanMeasurements(rows *sql.Rows) ([]*flexcreek.Measurement, error) {
+	defer rows.Close()
+
+	var measurements []*flexcreek.Measurement
+	for rows.Next() {
+		m, err := scanMeasurement(rows)
+		if err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return measurements, nil
+}
+
+func (s *Storage) CreateMeasurement(ctx context.Context, m *flexcreek.Measurement) (int, error) {
+	qry := `
+		INSERT INTO measurements (
+			user_id,
+			metric,
+			value,
+			unit,
+			measured_on
+		)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	res, err := s.exec(ctx, qry, m.UserID, m.Metric, m.Value, m.Unit, m.MeasuredOn.Format("2006-01-02"))
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (s *Storage) GetMeasurementByID(ctx context.Context, id int, userID int) (*flexcreek.Measurement, error) {
+	qry := `
+		SELECT ` + measurementColumns + `
+		FROM measurements
+		WHERE id = ?
+		  AND user_id = ?
+		  AND ` + liveUser + `
+	`
+
+	return scanMeasurement(s.queryRow(ctx, qry, id, userID))
+}
+
+// GetLatestMeasurements returns a user's n most recent measurements across all metrics, newest first
+func (s *Storage) GetLatestMeasurements(ctx context.Context, n int, userID int) ([]*flexcreek.Measurement, error) {
+	qry := `
+		SELECT ` + measurementColumns + `
+		FROM measurements
+		WHERE user_id = ?
+		  AND ` + liveUser + `
+		ORDER BY measured_on desc, id desc
+		LIMIT ?
+	`
+
+	rows, err := s.query(ctx, qry, userID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMeasurements(rows)
+}
+
+// GetMeasurementsByMetric returns every measurement of a single metric for a user, oldest first
+func (s *Storage) GetMeasurementsByMetric(ctx context.Context, metric string, userID int) ([]*flexcreek.Measurement, error) {
+	qry := `
+		SELECT ` + measurementColumns + `
+		FROM measurements
+		WHERE user_id = ?
+		  AND metric = ?
+		  AND ` + liveUser + `
+		ORDER BY measured_on asc, id asc
+	`
+
+	rows, err := s.query(ctx, qry, userID, metric)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanMeasurements(rows)
+}
+
+func (s *Storage) UpdateMeasurement(ctx context.Context, m *flexcreek.Measurement) error {
+	qry := `
+		UPDATE measurements
+		SET metric = ?,
+		value = ?,
+		unit = ?,
+		measured_on = ?
+		WHERE id = ?
+		  AND user_id = ?
+	`
+
+	res, err := s.exec(ctx, qry, m.Metric, m.Value, m.Unit, m.MeasuredOn.Format("2006-01-02"), m.ID, m.UserID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteMeasurement(ctx context.Context, id int, userID int) error {
+	qry := `
+		DELETE FROM measurements
+		WHERE id = ?
+		  AND user_id = ?
+	`
+
+	res, err := s.exec(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
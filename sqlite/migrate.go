@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// rows that existed before UUIDs were added get one derived from the row, so every copy of the
+// same database agrees on them instead of treating them as different rows
+var backfillNamespace = uuid.MustParse("b4fff665-5990-4eb5-b462-5b77bf14c184")
+
+// migrations are plain sql files named NNN_description.sql
+// the numeric prefix is the schema version the file brings the database up to
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loads and sorts the embedded migrations by version
+func loadMigrations() ([]migration, error) {
+	files, err := fs.Glob(migrationFS, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []migration
+	for _, f := range files {
+		name := strings.TrimPrefix(f, "migrations/")
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s is missing a version prefix", name)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version prefix: %w", name, err)
+		}
+
+		b, err := migrationFS.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration{version: version, name: name, sql: string(b)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	return migrations, nil
+}
+
+// LatestSchemaVersion returns the version the embedded migrations bring a database up to
+func LatestSchemaVersion() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+
+	return migrations[len(migrations)-1].version, nil
+}
+
+// IsEmpty reports whether the database has no tables of its own yet.
+// databases created before migrations were versioned have tables and rows but a schema version of 0
+func (s *Storage) IsEmpty(ctx context.Context) (bool, error) {
+	var n int
+	qry := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\'`
+	if err := s.queryRow(ctx, qry).Scan(&n); err != nil {
+		return false, err
+	}
+
+	return n == 0, nil
+}
+
+// SchemaVersion returns the schema version currently recorded in the database
+func (s *Storage) SchemaVersion(ctx context.Context) (int, error) {
+	var v int
+	if err := s.queryRow(ctx, "PRAGMA user_version").Scan(&v); err != nil {
+		return 0, err
+	}
+
+	return v, nil
+}
+
+// Migrate applies any embedded migrations newer than the database's schema version.
+// each migration runs in its own transaction along with the version bump
+func (s *Storage) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := s.begin(ctx)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %s: %w", m.name, err)
+		}
+
+		//pragmas can't take bound parameters
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", m.version)); err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return s.backfillUUIDs(ctx)
+}
+
+// gives rows created before the uuid columns existed a uuid. sql can't build one, so this runs after the migrations
+func (s *Storage) backfillUUIDs(ctx context.Context) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, table := range []struct{ name, entity string }{{"users", "user"}, {"workouts", "workout"}} {
+			rows, err := tx.QueryContext(ctx, "SELECT id, created_at FROM "+table.name+" WHERE uuid IS NULL")
+			if err != nil {
+				return err
+			}
+
+			ids := map[int]string{}
+			for rows.Next() {
+				var id int
+				var createdAt time.Time
+				if err := rows.Scan(&id, &createdAt); err != nil {
+					rows.Close()
+					return err
+				}
+				ids[id] = uuid.NewSHA1(backfillNamespace, []byte(table.entity+":"+strconv.Itoa(id)+":"+createdAt.UTC().Format(time.RFC3339))).String()
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			for id, u := range ids {
+				if _, err := tx.ExecContext(ctx, "UPDATE "+table.name+" SET uuid = ? WHERE id = ?", u, id); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
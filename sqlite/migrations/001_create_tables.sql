@@ -21,5 +21,5 @@ NOT NULL,
 );
 
 -- create indexes
-CREATE INDEX idx_workouts_user_id ON workouts(user_id);
-CREATE INDEX idx_workouts_date ON workouts(workout_date);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_workouts_user_id ON workouts(user_id);
+CREATE INDEX IF NOT EXISTS idx_workouts_date ON workouts(workout_date);
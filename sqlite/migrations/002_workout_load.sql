@@ -0,0 +1,4 @@
+-- session load inputs for training load analytics
+ALTER TABLE workouts ADD COLUMN duration_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workouts ADD COLUMN rpe INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE workouts ADD COLUMN session_load REAL NOT NULL DEFAULT 0;
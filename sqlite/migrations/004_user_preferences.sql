@@ -0,0 +1,10 @@
+-- per-user display preferences, quantities are always stored in canonical units
+ALTER TABLE users ADD COLUMN weight_unit TEXT NOT NULL DEFAULT 'kg';
+ALTER TABLE users ADD COLUMN distance_unit TEXT NOT NULL DEFAULT 'km';
+ALTER TABLE users ADD COLUMN first_day_of_week INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE users ADD COLUMN date_format TEXT NOT NULL DEFAULT '2006-01-02';
+ALTER TABLE users ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'Local';
+
+-- measurements were stored as entered before preferences existed, normalize them to canonical units
+UPDATE measurements SET value = value / 2.20462262185, unit = 'kg' WHERE unit = 'lb';
+UPDATE measurements SET value = value * 2.54, unit = 'cm' WHERE unit = 'in';
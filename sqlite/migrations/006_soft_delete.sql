@@ -0,0 +1,7 @@
+-- deleting users and workouts moves them to the trash by setting deleted_at.
+-- they're only removed for good when the trash is purged
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE workouts ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_workouts_deleted_at ON workouts(deleted_at);
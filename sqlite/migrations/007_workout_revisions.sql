@@ -0,0 +1,18 @@
+-- the values a workout had before each edit. changed_by is the user who made the edit,
+-- which is the owner unless an admin token was used
+CREATE TABLE
+IF NOT EXISTS workout_revisions
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    workout_id INTEGER NOT NULL REFERENCES workouts(id) ON DELETE CASCADE,
+    changed_by INTEGER NOT NULL,
+    short_description TEXT NOT NULL,
+    long_description TEXT,
+    workout_date TEXT,
+    duration_minutes INTEGER NOT NULL DEFAULT 0,
+    rpe INTEGER NOT NULL DEFAULT 0,
+    session_load REAL NOT NULL DEFAULT 0,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_revisions_workout_id ON workout_revisions(workout_id);
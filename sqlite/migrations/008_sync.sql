@@ -0,0 +1,90 @@
+-- the sync identity of each user and workout, and the version its current state came from
+CREATE TABLE
+IF NOT EXISTS sync_rows
+(
+    entity TEXT NOT NULL,
+    row_id INTEGER NOT NULL,
+    uuid TEXT NOT NULL UNIQUE,
+    hlc TEXT NOT NULL,
+    device TEXT NOT NULL,
+    PRIMARY KEY (entity, row_id)
+);
+
+-- rows changed locally since the changelog was last brought up to date, filled in by the triggers below.
+-- changed_at is empty for rows that already existed when syncing was added
+CREATE TABLE
+IF NOT EXISTS sync_pending
+(
+    entity TEXT NOT NULL,
+    row_id INTEGER NOT NULL,
+    changed_at TEXT NOT NULL,
+    PRIMARY KEY (entity, row_id)
+);
+
+-- this device's changes, kept until they've been written to a bundle. data is NULL for a purged row,
+-- and base_hlc is the version the row was at before the change, empty for a new row
+CREATE TABLE
+IF NOT EXISTS sync_changes
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity TEXT NOT NULL,
+    uuid TEXT NOT NULL,
+    hlc TEXT NOT NULL,
+    device TEXT NOT NULL,
+    data TEXT,
+    base_hlc TEXT NOT NULL DEFAULT '',
+    exported INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_changes_uuid ON sync_changes(uuid, exported);
+
+-- the newest change imported from each other device
+CREATE TABLE
+IF NOT EXISTS sync_peers
+(
+    device TEXT PRIMARY KEY,
+    hlc TEXT NOT NULL
+);
+
+-- the device ID and the last clock reading
+CREATE TABLE
+IF NOT EXISTS sync_meta
+(
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+INSERT OR IGNORE INTO sync_pending (entity, row_id, changed_at) SELECT 'user', id, '' FROM users;
+INSERT OR IGNORE INTO sync_pending (entity, row_id, changed_at) SELECT 'workout', id, '' FROM workouts;
+
+CREATE TRIGGER IF NOT EXISTS sync_users_insert AFTER INSERT ON users
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('user', NEW.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+-- password changes aren't synced, so they don't count
+CREATE TRIGGER IF NOT EXISTS sync_users_update
+AFTER UPDATE OF username, weight_unit, distance_unit, first_day_of_week, date_format, time_zone, deleted_at ON users
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('user', NEW.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS sync_users_delete AFTER DELETE ON users
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('user', OLD.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS sync_workouts_insert AFTER INSERT ON workouts
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('workout', NEW.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS sync_workouts_update AFTER UPDATE ON workouts
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('workout', NEW.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+CREATE TRIGGER IF NOT EXISTS sync_workouts_delete AFTER DELETE ON workouts
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('workout', OLD.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
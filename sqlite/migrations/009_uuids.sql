@@ -0,0 +1,20 @@
+-- public identifiers that stay the same across databases. integer IDs remain internal to each database
+ALTER TABLE users ADD COLUMN uuid TEXT;
+ALTER TABLE workouts ADD COLUMN uuid TEXT;
+
+-- assigning the uuids isn't an edit worth syncing, so workouts now only count changes to their synced columns
+DROP TRIGGER IF EXISTS sync_workouts_update;
+
+CREATE TRIGGER IF NOT EXISTS sync_workouts_update
+AFTER UPDATE OF user_id, short_description, long_description, workout_date, duration_minutes, rpe, session_load, deleted_at ON workouts
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('workout', NEW.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
+
+-- rows that have already synced keep the uuid other devices know them by.
+-- the rest are filled in by Storage.Migrate once the migrations have run
+UPDATE users SET uuid = (SELECT s.uuid FROM sync_rows s WHERE s.entity = 'user' AND s.row_id = users.id);
+UPDATE workouts SET uuid = (SELECT s.uuid FROM sync_rows s WHERE s.entity = 'workout' AND s.row_id = workouts.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_uuid ON users(uuid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workouts_uuid ON workouts(uuid);
@@ -0,0 +1,35 @@
+-- workout_date holds a civil date, YYYY-MM-DD, with no time or zone attached.
+-- older rows hold whatever the driver made of a time.Time, e.g. "2026-10-01 00:00:00 -0500 CDT",
+-- whose first 10 characters are the date that was picked.
+-- when a workout started is kept separately in start_time as RFC 3339 text with its UTC offset
+--
+-- the sync trigger is dropped while rows are rewritten, since tidying the stored text isn't an edit
+DROP TRIGGER IF EXISTS sync_workouts_update;
+
+UPDATE workouts SET workout_date = substr(workout_date, 1, 10) WHERE length(workout_date) > 10;
+UPDATE workout_revisions SET workout_date = substr(workout_date, 1, 10) WHERE length(workout_date) > 10;
+
+ALTER TABLE workouts ADD COLUMN start_time TEXT;
+ALTER TABLE workout_revisions ADD COLUMN start_time TEXT;
+
+-- created_at is UTC in the same "YYYY-MM-DD HH:MM:SS" form CURRENT_TIMESTAMP writes.
+-- rows written from Go look like "2026-10-01 07:00:00.5 -0500 CDT", so the offset after the time
+-- (and any fraction) is turned into sqlite's +HH:MM suffix and datetime() converts to UTC.
+-- anything that doesn't parse is left alone
+UPDATE users
+SET created_at = coalesce(datetime(substr(created_at, 1, 19)
+        || substr(substr(created_at, 20), instr(substr(created_at, 20), ' ') + 1, 3) || ':'
+        || substr(substr(created_at, 20), instr(substr(created_at, 20), ' ') + 4, 2)), created_at)
+WHERE length(created_at) > 19;
+
+UPDATE workouts
+SET created_at = coalesce(datetime(substr(created_at, 1, 19)
+        || substr(substr(created_at, 20), instr(substr(created_at, 20), ' ') + 1, 3) || ':'
+        || substr(substr(created_at, 20), instr(substr(created_at, 20), ' ') + 4, 2)), created_at)
+WHERE length(created_at) > 19;
+
+CREATE TRIGGER IF NOT EXISTS sync_workouts_update
+AFTER UPDATE OF user_id, short_description, long_description, workout_date, start_time, duration_minutes, rpe, session_load, deleted_at ON workouts
+BEGIN
+    INSERT OR REPLACE INTO sync_pending (entity, row_id, changed_at) VALUES ('workout', NEW.id, strftime('%Y-%m-%dT%H:%M:%fZ', 'now'));
+END;
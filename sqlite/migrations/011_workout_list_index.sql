@@ -0,0 +1,2 @@
+-- lets workout listings read a user's workouts already in date order instead of sorting all of them for every page
+CREATE INDEX IF NOT EXISTS idx_workouts_user_date ON workouts(user_id, workout_date DESC, id DESC);
@@ -0,0 +1,18 @@
+CREATE TABLE
+IF NOT EXISTS goals
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric TEXT NOT NULL,
+    -- workout goals only count workouts whose title contains this, when it's set
+    title_match TEXT NOT NULL DEFAULT '',
+    target REAL NOT NULL,
+    unit TEXT NOT NULL DEFAULT '',
+    -- week, month or year for a goal that starts over, empty for one with a deadline
+    period TEXT NOT NULL DEFAULT '',
+    start_date TEXT NOT NULL,
+    deadline TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
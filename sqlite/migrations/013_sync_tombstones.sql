@@ -0,0 +1,11 @@
+-- the version each purged user and workout was purged at, so an older change to it arriving
+-- afterwards doesn't bring it back
+CREATE TABLE
+IF NOT EXISTS sync_tombstones
+(
+    entity TEXT NOT NULL,
+    uuid TEXT NOT NULL,
+    hlc TEXT NOT NULL,
+    device TEXT NOT NULL,
+    PRIMARY KEY (entity, uuid)
+);
@@ -0,0 +1,9 @@
+-- marks the workouts a user's deletion sent to the trash, so restoring the user brings back just those
+-- rather than any workout whose deleted_at happens to fall in the same second
+ALTER TABLE workouts ADD COLUMN deleted_with_user INTEGER NOT NULL DEFAULT 0;
+
+-- workouts already in the trash with their user can only be told apart by the time they went
+UPDATE workouts
+SET deleted_with_user = 1
+WHERE deleted_at IS NOT NULL
+  AND deleted_at = (SELECT deleted_at FROM users WHERE users.id = workouts.user_id);
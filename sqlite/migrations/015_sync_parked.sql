@@ -0,0 +1,15 @@
+-- other devices' workout changes that arrived before their user did. they're kept here, oldest first,
+-- and applied once a change brings the user to this device
+CREATE TABLE
+IF NOT EXISTS sync_parked
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_uuid TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    uuid TEXT NOT NULL,
+    hlc TEXT NOT NULL,
+    device TEXT NOT NULL,
+    data TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_parked_user ON sync_parked(user_uuid);
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const revisionColumns = `
+		r.id,
+		r.workout_id,
+		w.user_id,
+		r.changed_by,
+		r.short_description,
+		r.long_description,
+		r.workout_date,
+		r.start_time,
+		r.duration_minutes,
+		r.rpe,
+		r.session_load,
+		r.changed_at
+`
+
+// scans a single revision row selected with revisionColumns
+func scanRevision(r rowScanner) (*flexcreek.WorkoutRevision, error) {
+	var rev flexcreek.WorkoutRevision
+	w := &rev.Workout
+	var longDescription sql.NullString
+	var workoutDate string
+	var startTime sql.NullString
+
+	err := r.Scan(&rev.ID, &w.ID, &w.UserID, &rev.ChangedBy, &w.ShortDescription, &longDescription, &workoutDate, &startTime, &w.DurationMinutes, &w.RPE, &w.SessionLoad, &rev.ChangedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	w.LongDescription = longDescription.String
+	rev.ChangedAt = rev.ChangedAt.UTC()
+
+	if w.WorkoutDate, err = time.Parse(dateLayout, workoutDate); err != nil {
+		return nil, err
+	}
+
+	if w.StartTime, err = parseStartTime(startTime); err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+// copies a workout's current values into workout_revisions. it's a no-op if the workout doesn't exist,
+// leaving the update that follows to report that
+func saveRevision(ctx context.Context, tx *sql.Tx, workoutID int, userID int, changedBy int) error {
+	qry := `
+		INSERT INTO workout_revisions (
+			workout_id,
+			changed_by,
+			short_description,
+			long_description,
+			workout_date,
+			start_time,
+			duration_minutes,
+			rpe,
+			session_load
+		)
+		SELECT id, ?, short_description, long_description, workout_date, start_time, duration_minutes, rpe, session_load
+		FROM workouts
+		WHERE id = ?
+		  AND user_id = ?
+		  AND deleted_at IS NULL
+	`
+
+	_, err := tx.ExecContext(ctx, qry, changedBy, workoutID, userID)
+	return err
+}
+
+// ListWorkoutRevisions returns the saved versions of one of userID's workouts, newest first
+func (s *Storage) ListWorkoutRevisions(ctx context.Context, workoutID int, userID int) ([]*flexcreek.WorkoutRevision, error) {
+	qry := `
+		SELECT ` + revisionColumns + `
+		FROM workout_revisions r
+		JOIN workouts w ON w.id = r.workout_id
+		WHERE r.workout_id = ?
+		  AND w.user_id = ?
+		  AND w.deleted_at IS NULL
+		ORDER BY r.id desc
+	`
+
+	rows, err := s.query(ctx, qry, workoutID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var revisions []*flexcreek.WorkoutRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
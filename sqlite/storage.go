@@ -1,13 +1,266 @@
 package sqlite
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// pragmas set on every connection Open makes.
+// WAL lets readers carry on while something writes, foreign_keys makes the ON DELETE CASCADEs in the
+// migrations actually apply, and busy_timeout makes a connection wait for a lock instead of failing straight away
+var connectionPragmas = []string{
+	"foreign_keys(1)",
+	"journal_mode(WAL)",
+	"synchronous(NORMAL)",
+	"busy_timeout(5000)",
+}
+
+// WAL allows any number of readers alongside one writer, so a small pool is plenty for a single user app
+const (
+	maxOpenConns    = 4
+	connMaxIdleTime = 5 * time.Minute
+)
 
 type Storage struct {
-	db *sql.DB
+	db    *sql.DB
+	tx    *sql.Tx    // set on the Storage WithTx hands out, which runs everything in that transaction
+	stmts *stmtCache // nil turns caching off
+}
+
+// prepared statements keyed by their sql, shared by a Storage and the ones WithTx hands out
+type stmtCache struct {
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
 }
 
+var _ flexcreek.Store = (*Storage)(nil)
+
+// NewStorage wraps an open database. queries are prepared the first time they're used and reused after that
 func NewStorage(db *sql.DB) *Storage {
 	return &Storage{
-		db: db,
+		db:    db,
+		stmts: &stmtCache{stmts: map[string]*sql.Stmt{}},
+	}
+}
+
+// Open opens (creating if needed) the database file at path with the connection settings flexcreek expects
+func Open(path string) (*Storage, error) {
+	q := url.Values{}
+	for _, p := range connectionPragmas {
+		q.Add("_pragma", p)
+	}
+	//take the write lock when a transaction starts, rather than failing part way through when two try to write
+	q.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	db.SetConnMaxIdleTime(connMaxIdleTime)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return NewStorage(db), nil
+}
+
+// Close releases the prepared statements and closes the database
+func (s *Storage) Close() error {
+	if s.stmts != nil {
+		s.stmts.mu.Lock()
+		for qry, stmt := range s.stmts.stmts {
+			stmt.Close()
+			delete(s.stmts.stmts, qry)
+		}
+		s.stmts.mu.Unlock()
+	}
+
+	return s.db.Close()
+}
+
+// WithTx runs fn in a single transaction, committing if it returns nil and rolling back if it returns an error or panics.
+// the Store fn gets is a *Storage, so it can be type asserted to reach methods outside the shared contract.
+// fn has to go through tx rather than s, which would wait on the transaction's lock
+func (s *Storage) WithTx(ctx context.Context, fn func(tx flexcreek.Store) error) error {
+	t, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.Rollback()
+
+	if err := fn(&Storage{db: s.db, tx: t.Tx, stmts: s.stmts}); err != nil {
+		return err
+	}
+
+	return t.Commit()
+}
+
+// a transaction, or a savepoint when it was started inside one.
+// Rollback after Commit does nothing, so it can always be deferred
+type txn struct {
+	*sql.Tx
+	ctx    context.Context
+	nested bool
+	done   bool
+}
+
+// begin starts a transaction, or a savepoint if s is already in one
+func (s *Storage) begin(ctx context.Context) (*txn, error) {
+	if s.tx != nil {
+		//savepoints can share a name, ROLLBACK TO and RELEASE act on the innermost one
+		if _, err := s.tx.ExecContext(ctx, "SAVEPOINT nested"); err != nil {
+			return nil, err
+		}
+		return &txn{Tx: s.tx, ctx: ctx, nested: true}, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txn{Tx: tx, ctx: ctx}, nil
+}
+
+func (t *txn) Commit() error {
+	t.done = true
+	if t.nested {
+		_, err := t.Tx.ExecContext(t.ctx, "RELEASE nested")
+		return err
+	}
+
+	return t.Tx.Commit()
+}
+
+func (t *txn) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	if t.nested {
+		if _, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO nested"); err != nil {
+			return err
+		}
+		_, err := t.Tx.ExecContext(t.ctx, "RELEASE nested")
+		return err
+	}
+
+	return t.Tx.Rollback()
+}
+
+// runs fn in a transaction, or a savepoint if s is already in one
+func (s *Storage) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	t, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.Rollback()
+
+	if err := fn(t.Tx); err != nil {
+		return err
+	}
+
+	return t.Commit()
+}
+
+// returns the cached statement for qry, bound to s's transaction if it has one.
+// nil means caching is off and the query should run unprepared
+func (s *Storage) prepare(ctx context.Context, qry string) (*sql.Stmt, error) {
+	if s.stmts == nil {
+		return nil, nil
+	}
+
+	s.stmts.mu.Lock()
+	defer s.stmts.mu.Unlock()
+
+	stmt, ok := s.stmts.stmts[qry]
+	if s.tx != nil {
+		//preparing needs a connection of its own, and the transaction may be holding the only one
+		if !ok {
+			return nil, nil
+		}
+		return s.tx.StmtContext(ctx, stmt), nil
+	}
+
+	if !ok {
+		var err error
+		if stmt, err = s.db.PrepareContext(ctx, qry); err != nil {
+			return nil, err
+		}
+		s.stmts.stmts[qry] = stmt
+	}
+
+	return stmt, nil
+}
+
+// what queries run on when they aren't prepared
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *Storage) querier() querier {
+	if s.tx != nil {
+		return s.tx
+	}
+
+	return s.db
+}
+
+// exec, query and queryRow run a query through the statement cache, in s's transaction if it has one
+func (s *Storage) exec(ctx context.Context, qry string, args ...any) (sql.Result, error) {
+	stmt, err := s.prepare(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return s.querier().ExecContext(ctx, qry, args...)
+	}
+
+	return stmt.ExecContext(ctx, args...)
+}
+
+func (s *Storage) query(ctx context.Context, qry string, args ...any) (*sql.Rows, error) {
+	stmt, err := s.prepare(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+	if stmt == nil {
+		return s.querier().QueryContext(ctx, qry, args...)
 	}
+
+	return stmt.QueryContext(ctx, args...)
+}
+
+func (s *Storage) queryRow(ctx context.Context, qry string, args ...any) rowScanner {
+	stmt, err := s.prepare(ctx, qry)
+	if err != nil {
+		return errRow{err}
+	}
+	if stmt == nil {
+		return s.querier().QueryRowContext(ctx, qry, args...)
+	}
+
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+// stands in for a *sql.Row when the query couldn't even be prepared
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
 }
@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func countUsers(t *testing.T, s *Storage) int {
+	t.Helper()
+
+	users, err := s.GetAllUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return len(users)
+}
+
+func TestWithTxCommits(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		id, err := tx.CreateUser(ctx, "alice")
+		if err != nil {
+			return err
+		}
+
+		wid, err := tx.CreateWorkout(ctx, &flexcreek.Workout{UserID: id, ShortDescription: "KB ABC", WorkoutDate: time.Now()})
+		if err != nil {
+			return err
+		}
+
+		//methods that run their own transaction join this one
+		w, err := tx.GetWorkoutByID(ctx, wid, id)
+		if err != nil {
+			return err
+		}
+		w.ShortDescription = "KB ABC, heavier"
+		return tx.UpdateWorkout(ctx, w)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workouts, err := s.GetLatestWorkouts(ctx, 10, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workouts) != 1 || workouts[0].ShortDescription != "KB ABC, heavier" {
+		t.Errorf("got workouts %+v", workouts)
+	}
+}
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	failed := errors.New("failed")
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		if _, err := tx.CreateUser(ctx, "alice"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithTx returned %v, want %v", err, failed)
+	}
+
+	if n := countUsers(t, s); n != 0 {
+		t.Errorf("%d users after rollback, want 0", n)
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("the panic wasn't passed on")
+			}
+		}()
+
+		s.WithTx(ctx, func(tx flexcreek.Store) error {
+			if _, err := tx.CreateUser(ctx, "alice"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	if n := countUsers(t, s); n != 0 {
+		t.Errorf("%d users after rollback, want 0", n)
+	}
+
+	//the connection has to be usable again afterwards
+	if _, err := s.CreateUser(ctx, "bob"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithTxNested(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		if _, err := tx.CreateUser(ctx, "alice"); err != nil {
+			return err
+		}
+
+		//a failed inner unit only undoes its own work
+		inner := tx.WithTx(ctx, func(tx flexcreek.Store) error {
+			if _, err := tx.CreateUser(ctx, "bob"); err != nil {
+				return err
+			}
+			return errors.New("never mind")
+		})
+		if inner == nil {
+			t.Error("the inner WithTx didn't return its error")
+		}
+
+		return tx.WithTx(ctx, func(tx flexcreek.Store) error {
+			_, err := tx.CreateUser(ctx, "carol")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := s.GetAllUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	if len(names) != 2 || names[0] != "alice" || names[1] != "carol" {
+		t.Errorf("got users %v, want [alice carol]", names)
+	}
+}
+
+func TestWithTxConflictRollsBack(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	if _, err := s.CreateUser(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		if _, err := tx.CreateUser(ctx, "bob"); err != nil {
+			return err
+		}
+		_, err := tx.CreateUser(ctx, "alice")
+		return err
+	})
+	if !errors.Is(err, flexcreek.ErrConflict) {
+		t.Fatalf("WithTx returned %v, want ErrConflict", err)
+	}
+
+	if n := countUsers(t, s); n != 1 {
+		t.Errorf("%d users after rollback, want 1", n)
+	}
+}
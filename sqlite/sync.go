@@ -0,0 +1,890 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/changelog"
+	"github.com/google/uuid"
+)
+
+// rows that existed before syncing was added get the same version on every copy of the database,
+// so devices that started from a copied flexcreek.db agree on them instead of duplicating them
+var backfillHLC = changelog.HLC{Logical: 1}
+
+// layout the sync triggers write changed_at in
+const pendingLayout = "2006-01-02T15:04:05.000Z"
+
+// sync_meta helpers. a missing key reads as an empty string
+func getMeta(ctx context.Context, tx *sql.Tx, key string) (string, error) {
+	var v string
+	err := tx.QueryRowContext(ctx, "SELECT value FROM sync_meta WHERE key = ?", key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return v, err
+}
+
+func setMeta(ctx context.Context, tx *sql.Tx, key string, value string) error {
+	_, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO sync_meta (key, value) VALUES (?, ?)", key, value)
+	return err
+}
+
+func loadClock(ctx context.Context, tx *sql.Tx) (*changelog.Clock, error) {
+	v, err := getMeta(ctx, tx, "clock")
+	if err != nil || v == "" {
+		return &changelog.Clock{}, err
+	}
+
+	last, err := changelog.ParseHLC(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &changelog.Clock{Last: last}, nil
+}
+
+func deviceID(ctx context.Context, tx *sql.Tx) (string, error) {
+	id, err := getMeta(ctx, tx, "device_id")
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	id = uuid.NewString()
+	return id, setMeta(ctx, tx, "device_id", id)
+}
+
+// DeviceID returns the ID this database syncs under, creating one the first time it's asked for
+func (s *Storage) DeviceID(ctx context.Context) (string, error) {
+	var id string
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		id, err = deviceID(ctx, tx)
+		return err
+	})
+
+	return id, err
+}
+
+// NewDeviceID gives the database a fresh device ID. a copy of a database that has already synced
+// needs one, or the two copies would skip each other's bundles
+func (s *Storage) NewDeviceID(ctx context.Context) (string, error) {
+	id := uuid.NewString()
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		return setMeta(ctx, tx, "device_id", id)
+	})
+
+	return id, err
+}
+
+// RecordChanges turns the rows the triggers have flagged into changelog entries
+func (s *Storage) RecordChanges(ctx context.Context) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		device, err := deviceID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		clock, err := loadClock(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		type pendingRow struct {
+			entity    string
+			rowID     int
+			changedAt string
+		}
+
+		//users go first on ties so a workout's owner always has an identity by the time the workout is recorded
+		rows, err := tx.QueryContext(ctx, `
+			SELECT entity, row_id, changed_at
+			FROM sync_pending
+			ORDER BY changed_at, CASE entity WHEN 'user' THEN 0 ELSE 1 END, row_id
+		`)
+		if err != nil {
+			return err
+		}
+
+		var pending []pendingRow
+		for rows.Next() {
+			var p pendingRow
+			if err := rows.Scan(&p.entity, &p.rowID, &p.changedAt); err != nil {
+				rows.Close()
+				return err
+			}
+			pending = append(pending, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, p := range pending {
+			data, rowUUID, err := rowData(ctx, tx, p.entity, p.rowID)
+			if err != nil {
+				return err
+			}
+
+			id, base, err := rowIdentity(ctx, tx, p.entity, p.rowID)
+			if err != nil {
+				return err
+			}
+			if id == "" {
+				id = rowUUID
+			}
+			if id == "" && data != nil {
+				//only rows written outside of Storage can be missing one
+				if id, err = assignUUID(ctx, tx, p.entity, p.rowID); err != nil {
+					return err
+				}
+			}
+
+			backfill := p.changedAt == ""
+
+			var hlc changelog.HLC
+			switch {
+			case id == "" && data == nil:
+				//created and purged before it was ever recorded, so no other device knows about it
+				if _, err := tx.ExecContext(ctx, "DELETE FROM sync_pending WHERE entity = ? AND row_id = ?", p.entity, p.rowID); err != nil {
+					return err
+				}
+				continue
+
+			case backfill:
+				hlc = backfillHLC
+
+			default:
+				t, err := time.Parse(pendingLayout, p.changedAt)
+				if err != nil {
+					return fmt.Errorf("sync_pending has an invalid changed_at %q: %w", p.changedAt, err)
+				}
+				hlc = clock.Tick(t)
+			}
+
+			if data == nil {
+				if err := setTombstone(ctx, tx, p.entity, p.rowID, id, changelog.Version{HLC: hlc, Device: device}); err != nil {
+					return err
+				}
+			} else if err := setRowIdentity(ctx, tx, p.entity, p.rowID, id, changelog.Version{HLC: hlc, Device: device}); err != nil {
+				return err
+			}
+
+			baseHLC := ""
+			if base.Device != "" {
+				baseHLC = base.HLC.String()
+			}
+
+			qry := `
+				INSERT INTO sync_changes (entity, uuid, hlc, device, data, base_hlc)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`
+
+			if _, err := tx.ExecContext(ctx, qry, p.entity, id, hlc.String(), device, nullData(data), baseHLC); err != nil {
+				return err
+			}
+
+			if _, err := tx.ExecContext(ctx, "DELETE FROM sync_pending WHERE entity = ? AND row_id = ?", p.entity, p.rowID); err != nil {
+				return err
+			}
+		}
+
+		return setMeta(ctx, tx, "clock", clock.Last.String())
+	})
+}
+
+func nullData(data []byte) sql.NullString {
+	return sql.NullString{String: string(data), Valid: data != nil}
+}
+
+func assignUUID(ctx context.Context, tx *sql.Tx, entity string, rowID int) (string, error) {
+	table := "users"
+	if entity == changelog.EntityWorkout {
+		table = "workouts"
+	}
+
+	id := uuid.NewString()
+	_, err := tx.ExecContext(ctx, "UPDATE "+table+" SET uuid = ? WHERE id = ?", id, rowID)
+	return id, err
+}
+
+// returns a row's UUID and version, or an empty UUID if it doesn't have one yet
+func rowIdentity(ctx context.Context, tx *sql.Tx, entity string, rowID int) (string, changelog.Version, error) {
+	var id, hlc string
+	var v changelog.Version
+
+	err := tx.QueryRowContext(ctx, "SELECT uuid, hlc, device FROM sync_rows WHERE entity = ? AND row_id = ?", entity, rowID).Scan(&id, &hlc, &v.Device)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", v, nil
+	}
+	if err != nil {
+		return "", v, err
+	}
+
+	v.HLC, err = changelog.ParseHLC(hlc)
+	return id, v, err
+}
+
+// returns the local row ID for a UUID, or 0 if there isn't a row for it
+func rowByUUID(ctx context.Context, tx *sql.Tx, entity string, id string) (int, changelog.Version, error) {
+	var rowID int
+	var hlc string
+	var v changelog.Version
+
+	err := tx.QueryRowContext(ctx, "SELECT row_id, hlc, device FROM sync_rows WHERE entity = ? AND uuid = ?", entity, id).Scan(&rowID, &hlc, &v.Device)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, v, nil
+	}
+	if err != nil {
+		return 0, v, err
+	}
+
+	v.HLC, err = changelog.ParseHLC(hlc)
+	return rowID, v, err
+}
+
+func setRowIdentity(ctx context.Context, tx *sql.Tx, entity string, rowID int, id string, v changelog.Version) error {
+	qry := `
+		INSERT OR REPLACE INTO sync_rows (entity, row_id, uuid, hlc, device)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, qry, entity, rowID, id, v.HLC.String(), v.Device)
+	return err
+}
+
+// returns the version a purged row was purged at, or a zero version if it hasn't been
+func tombstone(ctx context.Context, tx *sql.Tx, entity string, id string) (changelog.Version, error) {
+	var hlc string
+	var v changelog.Version
+
+	err := tx.QueryRowContext(ctx, "SELECT hlc, device FROM sync_tombstones WHERE entity = ? AND uuid = ?", entity, id).Scan(&hlc, &v.Device)
+	if errors.Is(err, sql.ErrNoRows) {
+		return v, nil
+	}
+	if err != nil {
+		return v, err
+	}
+
+	v.HLC, err = changelog.ParseHLC(hlc)
+	return v, err
+}
+
+// swaps a purged row's identity for a tombstone. rowID is 0 when the row was never on this device
+func setTombstone(ctx context.Context, tx *sql.Tx, entity string, rowID int, id string, v changelog.Version) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sync_rows WHERE entity = ? AND row_id = ?", entity, rowID); err != nil {
+		return err
+	}
+
+	qry := `
+		INSERT OR REPLACE INTO sync_tombstones (entity, uuid, hlc, device)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := tx.ExecContext(ctx, qry, entity, id, v.HLC.String(), v.Device)
+	return err
+}
+
+// reads a row, trashed or not, as the json a change carries along with its uuid. the data is nil if the row no longer exists
+func rowData(ctx context.Context, tx *sql.Tx, entity string, rowID int) ([]byte, string, error) {
+	switch entity {
+	case changelog.EntityUser:
+		u, err := scanUser(tx.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", rowID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		p := u.Preferences
+		b, err := json.Marshal(changelog.UserData{
+			Username:       u.Username,
+			CreatedAt:      u.CreatedAt.UTC(),
+			WeightUnit:     p.WeightUnit,
+			DistanceUnit:   p.DistanceUnit,
+			FirstDayOfWeek: int(p.FirstDayOfWeek),
+			DateFormat:     p.DateFormat,
+			TimeZone:       p.TimeZone,
+			DeletedAt:      timePtr(u.DeletedAt),
+		})
+		return b, u.UUID, err
+
+	case changelog.EntityWorkout:
+		w, err := scanWorkout(tx.QueryRowContext(ctx, "SELECT "+workoutColumns+" FROM workouts WHERE id = ?", rowID))
+		if errors.Is(err, flexcreek.ErrNotFound) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		userUUID, _, err := rowIdentity(ctx, tx, changelog.EntityUser, w.UserID)
+		if err != nil {
+			return nil, "", err
+		}
+		if userUUID == "" {
+			return nil, "", fmt.Errorf("workout %d belongs to user %d, who hasn't been recorded for syncing", w.ID, w.UserID)
+		}
+
+		var withUser bool
+		if err := tx.QueryRowContext(ctx, "SELECT deleted_with_user FROM workouts WHERE id = ?", rowID).Scan(&withUser); err != nil {
+			return nil, "", err
+		}
+
+		b, err := json.Marshal(changelog.WorkoutData{
+			UserUUID:         userUUID,
+			ShortDescription: w.ShortDescription,
+			LongDescription:  w.LongDescription,
+			WorkoutDate:      civilDate(w.WorkoutDate),
+			StartTime:        startTimeValue(w.StartTime).String,
+			DurationMinutes:  w.DurationMinutes,
+			RPE:              w.RPE,
+			SessionLoad:      w.SessionLoad,
+			CreatedAt:        w.CreatedAt.UTC(),
+			DeletedAt:        timePtr(w.DeletedAt),
+			DeletedWithUser:  withUser,
+		})
+		return b, w.UUID, err
+	}
+
+	return nil, "", fmt.Errorf("unknown sync entity %q", entity)
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.UTC()
+	return &t
+}
+
+// UnexportedChanges returns this device's changes that haven't been written to a bundle yet, oldest first
+func (s *Storage) UnexportedChanges(ctx context.Context) ([]changelog.Change, error) {
+	qry := `
+		SELECT entity, uuid, hlc, device, data
+		FROM sync_changes
+		WHERE exported = 0
+		ORDER BY id
+	`
+
+	rows, err := s.query(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []changelog.Change
+	for rows.Next() {
+		var c changelog.Change
+		var hlc string
+		var data sql.NullString
+
+		if err := rows.Scan(&c.Entity, &c.UUID, &hlc, &c.Device, &data); err != nil {
+			return nil, err
+		}
+
+		if c.HLC, err = changelog.ParseHLC(hlc); err != nil {
+			return nil, err
+		}
+		if data.Valid {
+			c.Data = json.RawMessage(data.String)
+		}
+
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// MarkExported flags this device's changes up to and including upTo as written to a bundle
+func (s *Storage) MarkExported(ctx context.Context, upTo changelog.HLC) error {
+	_, err := s.exec(ctx, "UPDATE sync_changes SET exported = 1 WHERE exported = 0 AND hlc <= ?", upTo.String())
+	return err
+}
+
+// PeerWatermark returns the newest change imported from device, or the zero HLC if there hasn't been one
+func (s *Storage) PeerWatermark(ctx context.Context, device string) (changelog.HLC, error) {
+	var hlc string
+	err := s.queryRow(ctx, "SELECT hlc FROM sync_peers WHERE device = ?", device).Scan(&hlc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return changelog.HLC{}, nil
+	}
+	if err != nil {
+		return changelog.HLC{}, err
+	}
+
+	return changelog.ParseHLC(hlc)
+}
+
+// ApplyChanges merges other devices' changes, oldest first, keeping whichever side wrote last.
+// a conflict is reported when the losing side had a change this device hasn't exported yet
+func (s *Storage) ApplyChanges(ctx context.Context, changes []changelog.Change) ([]changelog.Conflict, error) {
+	var conflicts []changelog.Conflict
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		clock, err := loadClock(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			conflict, err := applyChange(ctx, tx, c)
+			if err != nil {
+				return fmt.Errorf("applying %s %s: %w", c.Entity, c.UUID, err)
+			}
+			if conflict != nil {
+				conflicts = append(conflicts, *conflict)
+			}
+
+			if c.Entity == changelog.EntityUser {
+				replayed, err := replayParked(ctx, tx, c)
+				if err != nil {
+					return fmt.Errorf("applying the changes waiting on user %s: %w", c.UUID, err)
+				}
+				conflicts = append(conflicts, replayed...)
+			}
+
+			clock.Observe(c.HLC)
+
+			qry := `
+				INSERT INTO sync_peers (device, hlc) VALUES (?, ?)
+				ON CONFLICT (device) DO UPDATE SET hlc = excluded.hlc WHERE excluded.hlc > sync_peers.hlc
+			`
+
+			if _, err := tx.ExecContext(ctx, qry, c.Device, c.HLC.String()); err != nil {
+				return err
+			}
+		}
+
+		return setMeta(ctx, tx, "clock", clock.Last.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+func applyChange(ctx context.Context, tx *sql.Tx, c changelog.Change) (*changelog.Conflict, error) {
+	if c.Entity != changelog.EntityUser && c.Entity != changelog.EntityWorkout {
+		return nil, fmt.Errorf("unknown sync entity %q", c.Entity)
+	}
+
+	remote := changelog.Version{HLC: c.HLC, Device: c.Device}
+
+	rowID, local, err := rowByUUID(ctx, tx, c.Entity, c.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflict *changelog.Conflict
+	if rowID != 0 {
+		current, _, err := rowData(ctx, tx, c.Entity, rowID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !c.Purged() && sameData(c.Entity, current, c.Data) {
+			//nothing to change, but keep the winning version so every device records the same one
+			if remote.Newer(local) {
+				return nil, setRowIdentity(ctx, tx, c.Entity, rowID, c.UUID, remote)
+			}
+			return nil, nil
+		}
+
+		//the local side only conflicts if it has unexported changes made without having seen this remote change,
+		//i.e. they're based on an older version than it
+		var base sql.NullString
+		err = tx.QueryRowContext(ctx, "SELECT base_hlc FROM sync_changes WHERE uuid = ? AND exported = 0 ORDER BY id LIMIT 1", c.UUID).Scan(&base)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		if base.Valid && (base.String == "" || c.HLC.String() > base.String) {
+			conflict = &changelog.Conflict{
+				Entity:  c.Entity,
+				UUID:    c.UUID,
+				Summary: summarize(c.Entity, current),
+				Local:   local,
+				Remote:  remote,
+				Kept:    "local",
+			}
+		}
+
+		if !remote.Newer(local) {
+			return conflict, nil
+		}
+
+		if conflict != nil {
+			conflict.Kept = "remote"
+		}
+	} else {
+		//a row purged here or elsewhere only comes back for a change made after the purge
+		purged, err := tombstone(ctx, tx, c.Entity, c.UUID)
+		if err != nil {
+			return nil, err
+		}
+
+		if purged.Device != "" && !remote.Newer(purged) {
+			return nil, nil
+		}
+
+		if c.Purged() {
+			return nil, setTombstone(ctx, tx, c.Entity, 0, c.UUID, remote)
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM sync_tombstones WHERE entity = ? AND uuid = ?", c.Entity, c.UUID); err != nil {
+			return nil, err
+		}
+	}
+
+	reason, err := writeChange(ctx, tx, c, rowID)
+	if errors.Is(err, errNoUser) {
+		//the peer's watermark moves past it regardless, so it's kept to be applied when the user turns up
+		return nil, park(ctx, tx, c)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if reason != "" {
+		if conflict == nil {
+			conflict = &changelog.Conflict{Entity: c.Entity, UUID: c.UUID, Summary: summarize(c.Entity, c.Data), Local: local, Remote: remote}
+		}
+		conflict.Kept = "local"
+		conflict.Reason = reason
+	}
+
+	return conflict, nil
+}
+
+// writes a winning remote change into the local tables. a non-empty reason means it couldn't be applied
+func writeChange(ctx context.Context, tx *sql.Tx, c changelog.Change, rowID int) (string, error) {
+	remote := changelog.Version{HLC: c.HLC, Device: c.Device}
+
+	if c.Purged() {
+		if c.Entity == changelog.EntityUser {
+			if err := purgeUser(ctx, tx, rowID); err != nil {
+				return "", err
+			}
+		} else if err := purgeWorkout(ctx, tx, rowID); err != nil {
+			return "", err
+		}
+
+		return "", setTombstone(ctx, tx, c.Entity, rowID, c.UUID, remote)
+	}
+
+	var err error
+	var reason string
+	switch c.Entity {
+	case changelog.EntityUser:
+		rowID, reason, err = writeUser(ctx, tx, c.UUID, c.Data, rowID)
+	case changelog.EntityWorkout:
+		rowID, reason, err = writeWorkout(ctx, tx, c.UUID, c.Data, rowID)
+	}
+	if err != nil || reason != "" {
+		return reason, err
+	}
+
+	if err := setRowIdentity(ctx, tx, c.Entity, rowID, c.UUID, remote); err != nil {
+		return "", err
+	}
+
+	//the triggers saw the write too, but it isn't a local change
+	_, err = tx.ExecContext(ctx, "DELETE FROM sync_pending WHERE entity = ? AND row_id = ?", c.Entity, rowID)
+	return "", err
+}
+
+func writeUser(ctx context.Context, tx *sql.Tx, id string, data json.RawMessage, rowID int) (int, string, error) {
+	var u changelog.UserData
+	if err := json.Unmarshal(data, &u); err != nil {
+		return 0, "", err
+	}
+
+	args := []any{u.Username, u.WeightUnit, u.DistanceUnit, u.FirstDayOfWeek, u.DateFormat, u.TimeZone, nullTimePtr(u.DeletedAt)}
+
+	var res sql.Result
+	var err error
+	if rowID == 0 {
+		res, err = tx.ExecContext(ctx, `
+			INSERT INTO users (username, weight_unit, distance_unit, first_day_of_week, date_format, time_zone, deleted_at, created_at, uuid)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`, append(args, utcTimestamp(u.CreatedAt), id)...)
+	} else {
+		res, err = tx.ExecContext(ctx, `
+			UPDATE users
+			SET username = ?, weight_unit = ?, distance_unit = ?, first_day_of_week = ?, date_format = ?, time_zone = ?, deleted_at = ?
+			WHERE id = ?`, append(args, rowID)...)
+	}
+
+	if errors.Is(translateError(err), flexcreek.ErrConflict) {
+		return 0, "a different user is already called " + u.Username + " on this device", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if rowID == 0 {
+		id, err := res.LastInsertId()
+		return int(id), "", err
+	}
+
+	return rowID, "", nil
+}
+
+func writeWorkout(ctx context.Context, tx *sql.Tx, id string, data json.RawMessage, rowID int) (int, string, error) {
+	var w changelog.WorkoutData
+	if err := json.Unmarshal(data, &w); err != nil {
+		return 0, "", err
+	}
+
+	userID, _, err := rowByUUID(ctx, tx, changelog.EntityUser, w.UserUUID)
+	if err != nil {
+		return 0, "", err
+	}
+	if userID == 0 {
+		return 0, "", errNoUser
+	}
+
+	date, err := time.Parse(dateLayout, w.WorkoutDate)
+	if err != nil {
+		return 0, "", err
+	}
+
+	start, err := parseStartTime(sql.NullString{String: w.StartTime, Valid: true})
+	if err != nil {
+		return 0, "", err
+	}
+
+	args := []any{userID, w.ShortDescription, w.LongDescription, civilDate(date), startTimeValue(start), w.DurationMinutes, w.RPE, w.SessionLoad, nullTimePtr(w.DeletedAt), w.DeletedAt != nil && w.DeletedWithUser}
+
+	if rowID == 0 {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO workouts (user_id, short_description, long_description, workout_date, start_time, duration_minutes, rpe, session_load, deleted_at, deleted_with_user, created_at, uuid)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, append(args, utcTimestamp(w.CreatedAt), id)...)
+		if err != nil {
+			return 0, "", err
+		}
+
+		id, err := res.LastInsertId()
+		return int(id), "", err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE workouts
+		SET user_id = ?, short_description = ?, long_description = ?, workout_date = ?, start_time = ?, duration_minutes = ?, rpe = ?, session_load = ?, deleted_at = ?, deleted_with_user = ?
+		WHERE id = ?`, append(args, rowID)...)
+
+	return rowID, "", err
+}
+
+// errNoUser is returned for a workout change whose user hasn't reached this device yet
+var errNoUser = errors.New("its user isn't on this device")
+
+// keeps a workout change that arrived before its user
+func park(ctx context.Context, tx *sql.Tx, c changelog.Change) error {
+	var w changelog.WorkoutData
+	if err := json.Unmarshal(c.Data, &w); err != nil {
+		return err
+	}
+
+	//a user purged here isn't coming back for an old workout change, so there's nothing to wait for
+	purged, err := tombstone(ctx, tx, changelog.EntityUser, w.UserUUID)
+	if err != nil || purged.Device != "" {
+		return err
+	}
+
+	qry := `
+		INSERT INTO sync_parked (user_uuid, entity, uuid, hlc, device, data)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err = tx.ExecContext(ctx, qry, w.UserUUID, c.Entity, c.UUID, c.HLC.String(), c.Device, string(c.Data))
+	return err
+}
+
+// applies the changes parked waiting on the user c changed, oldest first. a purged user's are dropped,
+// and any the user still isn't here for (their change lost, say) are parked again
+func replayParked(ctx context.Context, tx *sql.Tx, c changelog.Change) ([]changelog.Conflict, error) {
+	qry := `
+		SELECT entity, uuid, hlc, device, data
+		FROM sync_parked
+		WHERE user_uuid = ?
+		ORDER BY id
+	`
+
+	rows, err := tx.QueryContext(ctx, qry, c.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var parked []changelog.Change
+	for rows.Next() {
+		var p changelog.Change
+		var hlc, data string
+		if err := rows.Scan(&p.Entity, &p.UUID, &hlc, &p.Device, &data); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if p.HLC, err = changelog.ParseHLC(hlc); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p.Data = json.RawMessage(data)
+		parked = append(parked, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(parked) == 0 {
+		return nil, nil
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sync_parked WHERE user_uuid = ?", c.UUID); err != nil {
+		return nil, err
+	}
+	if c.Purged() {
+		return nil, nil
+	}
+
+	var conflicts []changelog.Conflict
+	for _, p := range parked {
+		conflict, err := applyChange(ctx, tx, p)
+		if err != nil {
+			return nil, fmt.Errorf("applying %s %s: %w", p.Entity, p.UUID, err)
+		}
+		if conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		}
+	}
+
+	return conflicts, nil
+}
+
+func nullTimePtr(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// removes a user another device purged, along with everything of theirs that only lives on this device
+func purgeUser(ctx context.Context, tx *sql.Tx, rowID int) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM workouts WHERE user_id = ?", rowID)
+	if err != nil {
+		return err
+	}
+
+	var workoutIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		workoutIDs = append(workoutIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range workoutIDs {
+		if err := purgeWorkout(ctx, tx, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM sync_rows WHERE entity = 'workout' AND row_id = ?", id); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range []string{"measurements", "goals", "api_tokens"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE user_id = ?", rowID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = ?", rowID); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM sync_pending WHERE entity = 'user' AND row_id = ?", rowID)
+	return err
+}
+
+func purgeWorkout(ctx context.Context, tx *sql.Tx, rowID int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM workout_revisions WHERE workout_id = ?", rowID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM workouts WHERE id = ?", rowID); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "DELETE FROM sync_pending WHERE entity = 'workout' AND row_id = ?", rowID)
+	return err
+}
+
+// compares two encodings of a row by decoding them, so formatting differences don't count as changes
+func sameData(entity string, a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	var na, nb []byte
+	var err error
+	switch entity {
+	case changelog.EntityUser:
+		na, err = normalize[changelog.UserData](a)
+		if err == nil {
+			nb, err = normalize[changelog.UserData](b)
+		}
+	default:
+		na, err = normalize[changelog.WorkoutData](a)
+		if err == nil {
+			nb, err = normalize[changelog.WorkoutData](b)
+		}
+	}
+
+	return err == nil && bytes.Equal(na, nb)
+}
+
+func normalize[T any](data json.RawMessage) ([]byte, error) {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// a short description of a row for conflict reports
+func summarize(entity string, data json.RawMessage) string {
+	if data == nil {
+		return "purged"
+	}
+
+	if entity == changelog.EntityUser {
+		var u changelog.UserData
+		json.Unmarshal(data, &u)
+		return u.Username
+	}
+
+	var w changelog.WorkoutData
+	json.Unmarshal(data, &w)
+	return w.WorkoutDate + " " + w.ShortDescription
+}
@@ -0,0 +1,497 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/changelog"
+)
+
+const (
+	syncUser    = "6f1c1a52-8d0e-4c55-9a43-1f0c7f0d2a01"
+	syncWorkout = "6f1c1a52-8d0e-4c55-9a43-1f0c7f0d2a02"
+)
+
+var syncCreated = time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+func userChange(t *testing.T, device string, wall int64, username string) changelog.Change {
+	t.Helper()
+	p := flexcreek.DefaultPreferences()
+	data, err := json.Marshal(changelog.UserData{
+		Username:       username,
+		CreatedAt:      syncCreated,
+		WeightUnit:     p.WeightUnit,
+		DistanceUnit:   p.DistanceUnit,
+		FirstDayOfWeek: int(p.FirstDayOfWeek),
+		DateFormat:     p.DateFormat,
+		TimeZone:       p.TimeZone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return changelog.Change{Entity: changelog.EntityUser, UUID: syncUser, HLC: changelog.HLC{Wall: wall}, Device: device, Data: data}
+}
+
+// a version of the test workout as written on device at wall
+func workoutChange(t *testing.T, device string, wall int64, title string, minutes int, trashed bool) changelog.Change {
+	t.Helper()
+	w := changelog.WorkoutData{
+		UserUUID:         syncUser,
+		ShortDescription: title,
+		WorkoutDate:      "2026-03-01",
+		DurationMinutes:  minutes,
+		CreatedAt:        syncCreated,
+	}
+	if trashed {
+		deleted := syncCreated.Add(time.Hour)
+		w.DeletedAt = &deleted
+	}
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return changelog.Change{Entity: changelog.EntityWorkout, UUID: syncWorkout, HLC: changelog.HLC{Wall: wall}, Device: device, Data: data}
+}
+
+// a store that already has the test user and workout, as synced from device a
+func syncedStorage(t *testing.T) *Storage {
+	t.Helper()
+	s := newTestStorage(t)
+	applyAll(t, s, []changelog.Change{
+		userChange(t, "a", 1, "alice"),
+		workoutChange(t, "a", 2, "Easy run", 30, false),
+	})
+
+	return s
+}
+
+func applyAll(t *testing.T, s *Storage, changes []changelog.Change) {
+	t.Helper()
+	conflicts, err := s.ApplyChanges(context.Background(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Fatalf("applying %d changes reported conflicts %v", len(changes), conflicts)
+	}
+}
+
+// the test workout as it stands in s
+func syncedWorkout(t *testing.T, s *Storage) string {
+	t.Helper()
+	var title string
+	var minutes int
+	var trashed bool
+
+	qry := `SELECT short_description, duration_minutes, deleted_at IS NOT NULL FROM workouts WHERE uuid = ?`
+	err := s.queryRow(context.Background(), qry, syncWorkout).Scan(&title, &minutes, &trashed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "gone"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if trashed {
+		return fmt.Sprintf("%s %d (trashed)", title, minutes)
+	}
+	return fmt.Sprintf("%s %d", title, minutes)
+}
+
+// every order of the batches, each of which is applied oldest first like one device's bundle
+func permutations(batches [][]changelog.Change) [][][]changelog.Change {
+	if len(batches) <= 1 {
+		return [][][]changelog.Change{batches}
+	}
+
+	var out [][][]changelog.Change
+	for i := range batches {
+		rest := append(append([][]changelog.Change{}, batches[:i]...), batches[i+1:]...)
+		for _, p := range permutations(rest) {
+			out = append(out, append([][]changelog.Change{batches[i]}, p...))
+		}
+	}
+
+	return out
+}
+
+func TestSyncConvergesWhateverTheImportOrder(t *testing.T) {
+	batches := [][]changelog.Change{
+		{workoutChange(t, "a", 10, "Tempo run", 40, false), workoutChange(t, "a", 12, "Tempo run", 40, true)},
+		{workoutChange(t, "b", 11, "Long run", 90, false)},
+		//the same timestamp as a's delete, and c sorts after a
+		{workoutChange(t, "c", 12, "Hill repeats", 50, false)},
+	}
+
+	for i, order := range permutations(batches) {
+		s := syncedStorage(t)
+		for _, batch := range order {
+			applyAll(t, s, batch)
+		}
+
+		if got := syncedWorkout(t, s); got != "Hill repeats 50" {
+			t.Errorf("order %d ended with %q, want c's Hill repeats 50", i, got)
+		}
+	}
+}
+
+func TestSyncWorkoutBeforeItsUser(t *testing.T) {
+	ctx := context.Background()
+	batches := [][]changelog.Change{
+		{userChange(t, "a", 1, "alice")},
+		{workoutChange(t, "a", 2, "Easy run", 30, false)},
+		{workoutChange(t, "b", 3, "Easy run", 35, false)},
+	}
+
+	for i, order := range permutations(batches) {
+		s := newTestStorage(t)
+		for _, batch := range order {
+			applyAll(t, s, batch)
+		}
+
+		if got := syncedWorkout(t, s); got != "Easy run 35" {
+			t.Errorf("order %d ended with %q, want b's Easy run 35", i, got)
+		}
+
+		//every device's changes were read, whether or not they could be applied straight away
+		for device, want := range map[string]int64{"a": 2, "b": 3} {
+			if hlc, err := s.PeerWatermark(ctx, device); err != nil || hlc.Wall < want {
+				t.Errorf("order %d left %s's watermark at %v (%v)", i, device, hlc, err)
+			}
+		}
+
+		var parked int
+		if err := s.queryRow(ctx, "SELECT COUNT(*) FROM sync_parked").Scan(&parked); err != nil {
+			t.Fatal(err)
+		}
+		if parked != 0 {
+			t.Errorf("order %d left %d changes parked", i, parked)
+		}
+	}
+}
+
+func TestSyncWorkoutOfAPurgedUser(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+
+	applyAll(t, s, []changelog.Change{workoutChange(t, "a", 2, "Easy run", 30, false)})
+	if got := syncedWorkout(t, s); got != "gone" {
+		t.Fatalf("a workout without its user was written as %q", got)
+	}
+
+	//the user's purge arrives instead of the user, so the workout has nothing to wait for
+	purge := changelog.Change{Entity: changelog.EntityUser, UUID: syncUser, HLC: changelog.HLC{Wall: 3}, Device: "a"}
+	applyAll(t, s, []changelog.Change{purge})
+	applyAll(t, s, []changelog.Change{workoutChange(t, "b", 1, "Easy run", 45, false)})
+
+	var parked int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM sync_parked").Scan(&parked); err != nil {
+		t.Fatal(err)
+	}
+	if parked != 0 {
+		t.Errorf("%d changes are parked for a purged user", parked)
+	}
+
+	//and the user's older creation, arriving late, stays purged along with the workout
+	applyAll(t, s, []changelog.Change{userChange(t, "a", 1, "alice")})
+	if _, err := s.GetUserByUUID(ctx, syncUser); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("the purged user came back (%v)", err)
+	}
+	if got := syncedWorkout(t, s); got != "gone" {
+		t.Errorf("the purged user's workout came back as %q", got)
+	}
+}
+
+func TestSyncEqualTimestampsGoToTheHigherDevice(t *testing.T) {
+	a := userChange(t, "device-a", 10, "alice")
+	b := userChange(t, "device-b", 10, "ally")
+
+	for _, order := range [][]changelog.Change{{a, b}, {b, a}} {
+		s := syncedStorage(t)
+		applyAll(t, s, order[:1])
+		applyAll(t, s, order[1:])
+
+		u, err := s.GetUserByUUID(context.Background(), syncUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Username != "ally" {
+			t.Errorf("applying %s then %s kept %q, want device-b's ally", order[0].Device, order[1].Device, u.Username)
+		}
+	}
+}
+
+func TestSyncDeleteVersusEdit(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []changelog.Change
+		want    string
+	}{
+		{
+			"edit after the delete",
+			[]changelog.Change{workoutChange(t, "a", 10, "Easy run", 30, true), workoutChange(t, "b", 11, "Easy run", 35, false)},
+			"Easy run 35",
+		},
+		{
+			"delete after the edit",
+			[]changelog.Change{workoutChange(t, "b", 10, "Easy run", 35, false), workoutChange(t, "a", 11, "Easy run", 30, true)},
+			"Easy run 30 (trashed)",
+		},
+		{
+			"purge after the edit",
+			[]changelog.Change{workoutChange(t, "b", 10, "Easy run", 35, false),
+				{Entity: changelog.EntityWorkout, UUID: syncWorkout, HLC: changelog.HLC{Wall: 11}, Device: "a"}},
+			"gone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, reversed := range []bool{false, true} {
+				first, second := tt.changes[0], tt.changes[1]
+				if reversed {
+					first, second = second, first
+				}
+
+				s := syncedStorage(t)
+				applyAll(t, s, []changelog.Change{first})
+				applyAll(t, s, []changelog.Change{second})
+
+				if got := syncedWorkout(t, s); got != tt.want {
+					t.Errorf("applying %s then %s ended with %q, want %q", first.Device, second.Device, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncBetweenDevices(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	a, b := newTestStorage(t), newTestStorage(t)
+
+	alice, err := a.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "Easy run", WorkoutDate: syncCreated}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.RecordChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	sent, err := a.UnexportedChanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := changelog.Export(ctx, a, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, _, err := changelog.Import(ctx, b, dir); err != nil || n != 2 {
+		t.Fatalf("b imported %d changes (%v), want the user and the workout", n, err)
+	}
+	u, err := b.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws, err := b.GetLatestWorkouts(ctx, 10, u.ID); err != nil || len(ws) != 1 {
+		t.Fatalf("b has %d of alice's workouts (%v), want 1", len(ws), err)
+	}
+
+	//reading the same bundle again, or applying its changes twice, changes nothing
+	if n, conflicts, err := changelog.Import(ctx, b, dir); err != nil || n != 0 || len(conflicts) != 0 {
+		t.Errorf("importing again read %d changes with conflicts %v (%v), want nothing", n, conflicts, err)
+	}
+	applyAll(t, b, sent)
+	if err := b.RecordChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if changes, err := b.UnexportedChanges(ctx); err != nil || len(changes) != 0 {
+		t.Errorf("after re-applying a's changes b has %d of its own to export (%v), want none", len(changes), err)
+	}
+
+	//b's own measurements don't survive a purge on a
+	if _, err := b.CreateMeasurement(ctx, &flexcreek.Measurement{UserID: u.ID, Metric: "run", Value: 5, Unit: "km", MeasuredOn: syncCreated}); err != nil {
+		t.Fatal(err)
+	}
+
+	//and b's edit made before it heard of the purge doesn't bring her back on a
+	prefs := u.Preferences
+	prefs.WeightUnit = "lb"
+	if err := b.UpdateUserPreferences(ctx, u.ID, prefs); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RecordChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if err := a.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.PurgeTrash(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := changelog.Export(ctx, a, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := changelog.Import(ctx, b, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.GetUserByID(ctx, u.ID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("after a purged alice, b looking her up returned %v, want ErrNotFound", err)
+	}
+	if trashed, _ := b.ListTrashedUsers(ctx); len(trashed) != 0 {
+		t.Errorf("after a purged alice, b's trash holds %v", trashed)
+	}
+	for _, qry := range []string{
+		`SELECT COUNT(*) FROM workouts WHERE user_id = ?`,
+		`SELECT COUNT(*) FROM measurements WHERE user_id = ?`,
+	} {
+		var n int
+		if err := b.queryRow(ctx, qry, u.ID).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%s found %d rows on b after the purge", qry, n)
+		}
+	}
+
+	if _, err := changelog.Export(ctx, b, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := changelog.Import(ctx, a, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetUserByUsername(ctx, "alice"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("after importing b's older edit, a looking alice up returned %v, want ErrNotFound", err)
+	}
+	if trashed, _ := a.ListTrashedUsers(ctx); len(trashed) != 0 {
+		t.Errorf("after importing b's older edit, a's trash holds %v", trashed)
+	}
+}
+
+func TestSyncRestoreUser(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	a, b := newTestStorage(t), newTestStorage(t)
+
+	alice, err := a.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := a.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "Easy run", WorkoutDate: syncCreated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alone, err := a.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "KB ABC", WorkoutDate: syncCreated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteWorkout(ctx, alone, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := changelog.Export(ctx, a, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := changelog.Import(ctx, b, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	//b knows which of alice's workouts went with her, so restoring her there brings back just that one
+	trashed, err := b.ListTrashedUsers(ctx)
+	if err != nil || len(trashed) != 1 {
+		t.Fatalf("b's trashed users are %v (%v), want alice", trashed, err)
+	}
+	if err := b.RestoreUser(ctx, trashed[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := b.GetLatestWorkouts(ctx, 10, trashed[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Easy run"}; len(ws) != 1 || ws[0].ShortDescription != want[0] {
+		t.Errorf("after restoring alice on b her workouts are %v, want %v", ws, want)
+	}
+
+	//and the restore goes back to a the same way
+	if _, err := changelog.Export(ctx, b, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := changelog.Import(ctx, a, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.GetWorkoutByID(ctx, kept, alice); err != nil {
+		t.Errorf("after b restored alice, a is missing the workout trashed with her: %v", err)
+	}
+	if _, err := a.GetWorkoutByID(ctx, alone, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("after b restored alice, a has the workout trashed on its own back too (%v)", err)
+	}
+}
+
+func TestSyncLocalEditAfterAFastClock(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+
+	//the other device's clock runs a day ahead
+	ahead := time.Now().Add(24 * time.Hour).UnixMilli()
+	applyAll(t, s, []changelog.Change{
+		userChange(t, "fast", ahead, "alice"),
+		workoutChange(t, "fast", ahead+1, "Easy run", 30, false),
+	})
+	remote := changelog.HLC{Wall: ahead + 1}
+
+	u, err := s.GetUserByUUID(ctx, syncUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := s.GetWorkoutByUUID(ctx, syncWorkout, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.DurationMinutes = 45
+	if err := s.UpdateWorkout(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RecordChanges(ctx); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := s.UnexportedChanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("the edit was recorded as %d changes, want 1", len(changes))
+	}
+	if changes[0].HLC.Compare(remote) <= 0 {
+		t.Errorf("the local edit was stamped %v, which doesn't order after the change it edited (%v)", changes[0].HLC, remote)
+	}
+
+	//so it wins on a third device too
+	other := newTestStorage(t)
+	applyAll(t, other, []changelog.Change{
+		userChange(t, "fast", ahead, "alice"),
+		workoutChange(t, "fast", ahead+1, "Easy run", 30, false),
+	})
+	applyAll(t, other, changes)
+	if got := syncedWorkout(t, other); got != "Easy run 45" {
+		t.Errorf("the third device ended with %q, want the local edit's Easy run 45", got)
+	}
+}
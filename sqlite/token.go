@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const tokenColumns = `
+		id,
+		user_id,
+		name,
+		token_hash,
+		scopes,
+		expires_at,
+		last_used_at,
+		created_at
+`
+
+// scans a single token row selected with tokenColumns
+func scanToken(r rowScanner) (*flexcreek.APIToken, error) {
+	var t flexcreek.APIToken
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+
+	if err := r.Scan(&t.ID, &t.UserID, &t.Name, &t.TokenHash, &scopes, &expiresAt, &lastUsedAt, &t.CreatedAt); err != nil {
+		return nil, translateError(err)
+	}
+
+	t.Scopes = strings.Fields(scopes)
+	t.ExpiresAt = expiresAt.Time
+	t.LastUsedAt = lastUsedAt.Time
+
+	return &t, nil
+}
+
+// zero times are stored as NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
+
+func (s *Storage) CreateAPIToken(ctx context.Context, t *flexcreek.APIToken) (int, error) {
+	qry := `
+		INSERT INTO api_tokens (
+			user_id,
+			name,
+			token_hash,
+			scopes,
+			expires_at
+		)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	res, err := s.exec(ctx, qry, t.UserID, t.Name, t.TokenHash, strings.Join(t.Scopes, " "), nullTime(t.ExpiresAt))
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// GetAPITokenByHash looks up a token by the hash of its plaintext. tokens of trashed users aren't found
+func (s *Storage) GetAPITokenByHash(ctx context.Context, hash string) (*flexcreek.APIToken, error) {
+	qry := `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		WHERE token_hash = ?
+		  AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)
+	`
+
+	return scanToken(s.queryRow(ctx, qry, hash))
+}
+
+func (s *Storage) ListAPITokens(ctx context.Context, userID int) ([]*flexcreek.APIToken, error) {
+	qry := `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		WHERE user_id = ?
+		ORDER BY created_at desc, id desc
+	`
+
+	rows, err := s.query(ctx, qry, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tokens []*flexcreek.APIToken
+	for rows.Next() {
+		t, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// TouchAPIToken records that a token was used at time t
+func (s *Storage) TouchAPIToken(ctx context.Context, id int, t time.Time) error {
+	qry := `
+		UPDATE api_tokens
+		SET last_used_at = ?
+		WHERE id = ?
+	`
+
+	_, err := s.exec(ctx, qry, t.UTC(), id)
+	return err
+}
+
+func (s *Storage) DeleteAPIToken(ctx context.Context, id int, userID int) error {
+	qry := `
+		DELETE FROM api_tokens
+		WHERE id = ?
+		  AND user_id = ?
+	`
+
+	res, err := s.exec(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
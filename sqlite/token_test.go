@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func TestAPITokens(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := s.CreateUser(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expires := time.Date(2026, 6, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	laptop := flexcreek.APIToken{UserID: alice, Name: "laptop", TokenHash: "hash-laptop", Scopes: []string{"workouts:read", "workouts:write"}, ExpiresAt: expires}
+	laptopID, err := s.CreateAPIToken(ctx, &laptop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	phoneID, err := s.CreateAPIToken(ctx, &flexcreek.APIToken{UserID: alice, Name: "phone", TokenHash: "hash-phone", Scopes: []string{"workouts:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateAPIToken(ctx, &flexcreek.APIToken{UserID: bob, Name: "laptop", TokenHash: "hash-laptop"}); !errors.Is(err, flexcreek.ErrConflict) {
+		t.Errorf("reusing a token hash returned %v, want ErrConflict", err)
+	}
+
+	got, err := s.GetAPITokenByHash(ctx, "hash-laptop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != laptopID || got.UserID != alice || got.Name != "laptop" || len(got.Scopes) != 2 || got.Scopes[1] != "workouts:write" {
+		t.Errorf("read back %+v", *got)
+	}
+	if !got.ExpiresAt.Equal(expires) || !got.LastUsedAt.IsZero() || got.CreatedAt.IsZero() {
+		t.Errorf("the laptop token expires %v, was last used %v and created %v, want %v, never and now", got.ExpiresAt, got.LastUsedAt, got.CreatedAt, expires)
+	}
+
+	phone, err := s.GetAPITokenByHash(ctx, "hash-phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !phone.ExpiresAt.IsZero() || phone.Expired(time.Now().AddDate(100, 0, 0)) {
+		t.Errorf("a token without an expiry expires %v", phone.ExpiresAt)
+	}
+	if _, err := s.GetAPITokenByHash(ctx, "hash-unknown"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("an unknown hash returned %v, want ErrNotFound", err)
+	}
+
+	used := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	if err := s.TouchAPIToken(ctx, phoneID, used); err != nil {
+		t.Fatal(err)
+	}
+	if phone, err = s.GetAPITokenByHash(ctx, "hash-phone"); err != nil || !phone.LastUsedAt.Equal(used) {
+		t.Errorf("after touching it the phone token was last used %v (%v), want %v", phone.LastUsedAt, err, used)
+	}
+
+	tokens, err := s.ListAPITokens(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != phoneID || tokens[1].ID != laptopID {
+		t.Errorf("alice's tokens are %v, want the phone then the laptop", tokens)
+	}
+	if tokens, err := s.ListAPITokens(ctx, bob); err != nil || len(tokens) != 0 {
+		t.Errorf("bob's tokens are %v (%v), want none", tokens, err)
+	}
+
+	//revoking is scoped to the owner
+	if err := s.DeleteAPIToken(ctx, laptopID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("bob revoking alice's token returned %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteAPIToken(ctx, laptopID, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetAPITokenByHash(ctx, "hash-laptop"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("a revoked token was found (%v)", err)
+	}
+	if err := s.DeleteAPIToken(ctx, laptopID, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("revoking a token twice returned %v, want ErrNotFound", err)
+	}
+
+	//a trashed user's tokens stop working, and work again once they're restored
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetAPITokenByHash(ctx, "hash-phone"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("a trashed user's token returned %v, want ErrNotFound", err)
+	}
+	if err := s.RestoreUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetAPITokenByHash(ctx, "hash-phone"); err != nil {
+		t.Errorf("after restoring alice her token isn't found: %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// the time stored in deleted_at. it's kept to whole seconds in UTC so the stored text compares in time order
+func trashTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// ListTrashedUsers returns every user in the trash, most recently deleted first
+func (s *Storage) ListTrashedUsers(ctx context.Context) ([]*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at desc, id desc
+	`
+
+	rows, err := s.query(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []*flexcreek.User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// ListTrashedWorkouts returns a user's workouts in the trash, most recently deleted first
+func (s *Storage) ListTrashedWorkouts(ctx context.Context, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = ?
+		  AND deleted_at IS NOT NULL
+		ORDER BY deleted_at desc, id desc
+	`
+
+	rows, err := s.query(ctx, qry, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// RestoreUser takes a user out of the trash, along with the workouts that were trashed with them.
+// workouts deleted individually beforehand stay in the trash
+func (s *Storage) RestoreUser(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	//just the workouts DeleteUser trashed. if the user isn't in the trash nothing is flagged,
+	//and the user's update below finds nothing and rolls this back anyway
+	workoutsQry := `
+		UPDATE workouts
+		SET deleted_at = NULL, deleted_with_user = 0
+		WHERE user_id = ?
+		  AND deleted_with_user = 1
+	`
+
+	if _, err := tx.ExecContext(ctx, workoutsQry, id); err != nil {
+		return err
+	}
+
+	qry := `
+		UPDATE users
+		SET deleted_at = NULL
+		WHERE id = ?
+		  AND deleted_at IS NOT NULL
+	`
+
+	res, err := tx.ExecContext(ctx, qry, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return tx.Commit()
+}
+
+// RestoreWorkout takes one of userID's workouts out of the trash. the user has to be restored first if they're trashed too
+func (s *Storage) RestoreWorkout(ctx context.Context, id int, userID int) error {
+	qry := `
+		UPDATE workouts
+		SET deleted_at = NULL, deleted_with_user = 0
+		WHERE id = ?
+		  AND user_id = ?
+		  AND deleted_at IS NOT NULL
+		  AND user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)
+	`
+
+	res, err := s.exec(ctx, qry, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently removes users and workouts that were trashed before the cutoff, returning how many of each went.
+// a purged user takes all of their data with them
+func (s *Storage) PurgeTrash(ctx context.Context, before time.Time) (users int, workouts int, err error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := before.UTC().Truncate(time.Second)
+
+	//the user's own rows go explicitly rather than relying on ON DELETE CASCADE
+	purgedUsers := `(SELECT id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < ?)`
+
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM workouts
+		WHERE (deleted_at IS NOT NULL AND deleted_at < ?)
+		   OR user_id IN `+purgedUsers, cutoff, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	workouts = int(n)
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM workout_revisions WHERE workout_id NOT IN (SELECT id FROM workouts)`); err != nil {
+		return 0, 0, err
+	}
+
+	for _, table := range []string{"measurements", "goals", "api_tokens"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id IN `+purgedUsers, cutoff); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	res, err = tx.ExecContext(ctx, `
+		DELETE FROM users
+		WHERE deleted_at IS NOT NULL
+		  AND deleted_at < ?`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err = res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+	users = int(n)
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return users, workouts, nil
+}
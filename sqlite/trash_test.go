@@ -0,0 +1,222 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// alice with two workouts, one of which was deleted a day before she was
+func trashFixture(t *testing.T) (s *Storage, alice int, kept int, deletedFirst int) {
+	t.Helper()
+	s = newTestStorage(t)
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if kept, err = s.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "Easy run", WorkoutDate: day}); err != nil {
+		t.Fatal(err)
+	}
+	if deletedFirst, err = s.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "KB ABC", WorkoutDate: day}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateMeasurement(ctx, &flexcreek.Measurement{UserID: alice, Metric: "run", Value: 5, Unit: "km", MeasuredOn: day}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateGoal(ctx, &flexcreek.Goal{UserID: alice, Metric: flexcreek.GoalSessions, Target: 3, Period: flexcreek.PeriodWeek}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteWorkout(ctx, deletedFirst, alice); err != nil {
+		t.Fatal(err)
+	}
+	backdate(t, s, "workouts", deletedFirst, 24*time.Hour)
+
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	return s, alice, kept, deletedFirst
+}
+
+// moves a trashed row's deleted_at back by d
+func backdate(t *testing.T, s *Storage, table string, id int, d time.Duration) {
+	t.Helper()
+	qry := `UPDATE ` + table + ` SET deleted_at = ? WHERE id = ?`
+	if _, err := s.exec(context.Background(), qry, trashTime().Add(-d), id); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTrashList(t *testing.T) {
+	s, alice, kept, deletedFirst := trashFixture(t)
+	ctx := context.Background()
+
+	users, err := s.ListTrashedUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != alice || users[0].DeletedAt.IsZero() {
+		t.Errorf("the trashed users are %v, want just alice", users)
+	}
+
+	workouts, err := s.ListTrashedWorkouts(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workouts) != 2 || workouts[0].ID != kept || workouts[1].ID != deletedFirst {
+		t.Errorf("the trashed workouts are %v, want the one trashed with alice then the one deleted first", workouts)
+	}
+
+	//nothing of a trashed user's is handed out
+	if ms, err := s.GetLatestMeasurements(ctx, 10, alice); err != nil || len(ms) != 0 {
+		t.Errorf("alice's latest measurements are %v (%v), want none while she's trashed", ms, err)
+	}
+	if ms, err := s.GetMeasurementsByMetric(ctx, "run", alice); err != nil || len(ms) != 0 {
+		t.Errorf("alice's run measurements are %v (%v), want none while she's trashed", ms, err)
+	}
+	if gs, err := s.GetGoals(ctx, alice); err != nil || len(gs) != 0 {
+		t.Errorf("alice's goals are %v (%v), want none while she's trashed", gs, err)
+	}
+}
+
+func TestRestoreUser(t *testing.T) {
+	s, alice, kept, deletedFirst := trashFixture(t)
+	ctx := context.Background()
+
+	//her workouts can't come back before she does
+	if err := s.RestoreWorkout(ctx, kept, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("restoring a workout of a trashed user returned %v, want ErrNotFound", err)
+	}
+
+	if err := s.RestoreUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RestoreUser(ctx, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("restoring alice twice returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.GetUserByUsername(ctx, "alice"); err != nil {
+		t.Errorf("alice isn't back: %v", err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, kept, alice); err != nil {
+		t.Errorf("the workout trashed with alice isn't back: %v", err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, deletedFirst, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("the workout deleted before alice came back too (%v)", err)
+	}
+	if ms, err := s.GetLatestMeasurements(ctx, 10, alice); err != nil || len(ms) != 1 {
+		t.Errorf("alice's measurements are %v (%v), want her one back", ms, err)
+	}
+
+	if err := s.RestoreWorkout(ctx, deletedFirst, alice); err != nil {
+		t.Fatal(err)
+	}
+	if trashed, _ := s.ListTrashedWorkouts(ctx, alice); len(trashed) != 0 {
+		t.Errorf("after restoring both the trash still has %v", trashed)
+	}
+}
+
+func TestRestoreUserSameSecond(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	kept, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "Easy run", WorkoutDate: day})
+	if err != nil {
+		t.Fatal(err)
+	}
+	alone, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "KB ABC", WorkoutDate: day})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteWorkout(ctx, alone, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	//make sure both went in the same second, whatever the clock did between them
+	if _, err := s.exec(ctx, `UPDATE workouts SET deleted_at = (SELECT deleted_at FROM users WHERE id = ?) WHERE id = ?`, alice, alone); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RestoreUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, kept, alice); err != nil {
+		t.Errorf("the workout trashed with alice isn't back: %v", err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, alone, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("the workout trashed on its own in the same second came back too (%v)", err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	s, alice, _, _ := trashFixture(t)
+	ctx := context.Background()
+
+	bob, err := s.CreateUser(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobs, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: bob, ShortDescription: "Easy run", WorkoutDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a cutoff 12 hours back only reaches the workout deleted a day ago
+	users, workouts, err := s.PurgeTrash(ctx, time.Now().Add(-12*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 || workouts != 1 {
+		t.Errorf("the first purge removed %d users and %d workouts, want 0 and 1", users, workouts)
+	}
+	if trashed, _ := s.ListTrashedWorkouts(ctx, alice); len(trashed) != 1 {
+		t.Errorf("after the first purge alice has %d workouts in the trash, want 1", len(trashed))
+	}
+
+	users, workouts, err = s.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users != 1 || workouts != 1 {
+		t.Errorf("the second purge removed %d users and %d workouts, want 1 and 1", users, workouts)
+	}
+
+	for _, qry := range []string{
+		`SELECT COUNT(*) FROM users WHERE id = ?`,
+		`SELECT COUNT(*) FROM workouts WHERE user_id = ?`,
+		`SELECT COUNT(*) FROM measurements WHERE user_id = ?`,
+		`SELECT COUNT(*) FROM goals WHERE user_id = ?`,
+	} {
+		var n int
+		if err := s.queryRow(ctx, qry, alice).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%s left %d of alice's rows after the purge", qry, n)
+		}
+	}
+
+	//the name is free again, and bob never went near the trash
+	if _, err := s.CreateUser(ctx, "alice"); err != nil {
+		t.Errorf("creating alice after the purge: %v", err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, bobs, bob); err != nil {
+		t.Errorf("bob's workout went in the purge: %v", err)
+	}
+}
@@ -3,21 +3,57 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"github.com/ekholme/flexcreek"
+	"github.com/google/uuid"
 )
 
-// Create a new user in the users table of the database
+// limits a user's measurements and goals to users that aren't in the trash
+const liveUser = `user_id IN (SELECT id FROM users WHERE deleted_at IS NULL)`
+
+const userColumns = `
+		id,
+		uuid,
+		username,
+		created_at,
+		weight_unit,
+		distance_unit,
+		first_day_of_week,
+		date_format,
+		time_zone,
+		deleted_at
+`
+
+// scans a single user row selected with userColumns
+func scanUser(r rowScanner) (*flexcreek.User, error) {
+	var u flexcreek.User
+	p := &u.Preferences
+	var deletedAt sql.NullTime
+	var id sql.NullString
+
+	if err := r.Scan(&u.ID, &id, &u.Username, &u.CreatedAt, &p.WeightUnit, &p.DistanceUnit, &p.FirstDayOfWeek, &p.DateFormat, &p.TimeZone, &deletedAt); err != nil {
+		return nil, err
+	}
+	u.UUID = id.String
+	u.CreatedAt = u.CreatedAt.UTC()
+	u.DeletedAt = deletedAt.Time
+
+	return &u, nil
+}
+
+// Create a new user in the users table of the database, with a new UUID
 func (s *Storage) CreateUser(ctx context.Context, username string) (int, error) {
 	qry := `
-		INSERT INTO users (username)
-		VALUES (?)	
+		INSERT INTO users (uuid, username)
+		VALUES (?, ?)
 	`
 
-	res, err := s.db.ExecContext(ctx, qry, username)
+	res, err := s.exec(ctx, qry, uuid.NewString(), username)
 
 	if err != nil {
-		return 0, err
+		return 0, s.usernameConflict(ctx, username, translateError(err))
 	}
 
 	id, err := res.LastInsertId()
@@ -29,58 +65,82 @@ func (s *Storage) CreateUser(ctx context.Context, username string) (int, error)
 	return int(id), nil
 }
 
+// usernames stay taken while their user is in the trash, so a conflict with a trashed user says so
+func (s *Storage) usernameConflict(ctx context.Context, username string, err error) error {
+	if !errors.Is(err, flexcreek.ErrConflict) {
+		return err
+	}
+
+	var trashed bool
+	qry := `SELECT EXISTS (SELECT 1 FROM users WHERE username = ? AND deleted_at IS NOT NULL)`
+	if qerr := s.queryRow(ctx, qry, username).Scan(&trashed); qerr == nil && trashed {
+		return fmt.Errorf("%w: %w", flexcreek.ErrConflict, flexcreek.ErrNameInTrash)
+	}
+
+	return err
+}
+
 func (s *Storage) GetUserByUsername(ctx context.Context, username string) (*flexcreek.User, error) {
 	qry := `
-		SELECT id,
-		username,
-		created_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE username = ?	
+		WHERE username = ?
+		  AND deleted_at IS NULL
 	`
 
-	var u flexcreek.User
-
-	res := s.db.QueryRowContext(ctx, qry, username)
+	res := s.queryRow(ctx, qry, username)
 
-	//todo later -- define a custom error type to handle cases where no rows are returned & return this instead
-	if err := res.Scan(&u.ID, &u.Username, &u.CreatedAt); err != nil {
-		return nil, err
+	u, err := scanUser(res)
+	if err != nil {
+		return nil, translateError(err)
 	}
 
-	return &u, nil
+	return u, nil
 }
 
 func (s *Storage) GetUserByID(ctx context.Context, id int) (*flexcreek.User, error) {
 	qry := `
-		SELECT id,
-		username,
-		created_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE id = ?	
+		WHERE id = ?
+		  AND deleted_at IS NULL
 	`
 
-	var u flexcreek.User
-
-	res := s.db.QueryRowContext(ctx, qry, id)
+	res := s.queryRow(ctx, qry, id)
 
-	//todo later -- define a custom error type to handle cases where no rows are returned & return this instead
-	if err := res.Scan(&u.ID, &u.Username, &u.CreatedAt); err != nil {
-		return nil, err
+	u, err := scanUser(res)
+	if err != nil {
+		return nil, translateError(err)
 	}
 
-	return &u, nil
+	return u, nil
+}
+
+// GetUserByUUID looks up a user by their public identifier
+func (s *Storage) GetUserByUUID(ctx context.Context, id string) (*flexcreek.User, error) {
+	qry := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE uuid = ?
+		  AND deleted_at IS NULL
+	`
+
+	u, err := scanUser(s.queryRow(ctx, qry, id))
+	if err != nil {
+		return nil, translateError(err)
+	}
 
+	return u, nil
 }
 
 func (s *Storage) GetAllUsers(ctx context.Context) ([]*flexcreek.User, error) {
 	qry := `
-		SELECT id,
-		username,
-		created_at
-		FROM users	
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deleted_at IS NULL
 	`
 
-	rows, err := s.db.QueryContext(ctx, qry)
+	rows, err := s.query(ctx, qry)
 	if err != nil {
 		return nil, err
 	}
@@ -89,11 +149,11 @@ func (s *Storage) GetAllUsers(ctx context.Context) ([]*flexcreek.User, error) {
 
 	var users []*flexcreek.User
 	for rows.Next() {
-		var user flexcreek.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			return nil, err
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	//check for any errors that occur during iteration
@@ -104,13 +164,25 @@ func (s *Storage) GetAllUsers(ctx context.Context) ([]*flexcreek.User, error) {
 	return users, nil
 }
 
+// DeleteUser moves a user to the trash along with their workouts.
+// everything trashed together shares a deleted_at, which is how RestoreUser knows what to bring back
 func (s *Storage) DeleteUser(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	qry := `
-		DELETE FROM users
-		WHERE id = ?	
+		UPDATE users
+		SET deleted_at = ?
+		WHERE id = ?
+		  AND deleted_at IS NULL
 	`
 
-	res, err := s.db.ExecContext(ctx, qry, id)
+	now := trashTime()
+
+	res, err := tx.ExecContext(ctx, qry, now, id)
 	if err != nil {
 		return err
 	}
@@ -123,9 +195,98 @@ func (s *Storage) DeleteUser(ctx context.Context, id int) error {
 
 	// If no rows were affected, it means the user with that ID was not found.
 	if rowsAffected == 0 {
-		// In the future, this could return a custom flexcreek.ErrNotFound.
-		return sql.ErrNoRows
+		return flexcreek.ErrNotFound
+	}
+
+	//flagged so RestoreUser can tell these from workouts that were trashed on their own
+	workoutsQry := `
+		UPDATE workouts
+		SET deleted_at = ?, deleted_with_user = 1
+		WHERE user_id = ?
+		  AND deleted_at IS NULL
+	`
+
+	if _, err := tx.ExecContext(ctx, workoutsQry, now, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateUserPreferences replaces a user's display preferences
+func (s *Storage) UpdateUserPreferences(ctx context.Context, id int, p flexcreek.Preferences) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	qry := `
+		UPDATE users
+		SET weight_unit = ?,
+		distance_unit = ?,
+		first_day_of_week = ?,
+		date_format = ?,
+		time_zone = ?
+		WHERE id = ?
+		  AND deleted_at IS NULL
+	`
+
+	res, err := s.exec(ctx, qry, p.WeightUnit, p.DistanceUnit, int(p.FirstDayOfWeek), p.DateFormat, p.TimeZone, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetPasswordHash stores a user's password hash. hashing is the caller's job (see the auth package)
+func (s *Storage) SetPasswordHash(ctx context.Context, id int, hash string) error {
+	qry := `
+		UPDATE users
+		SET password_hash = ?
+		WHERE id = ?
+		  AND deleted_at IS NULL
+	`
+
+	res, err := s.exec(ctx, qry, hash, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return flexcreek.ErrNotFound
 	}
 
 	return nil
 }
+
+// GetPasswordHash returns a user's password hash, which is empty if no password has been set.
+// it's kept off flexcreek.User so the hash isn't passed around with every user
+func (s *Storage) GetPasswordHash(ctx context.Context, id int) (string, error) {
+	qry := `
+		SELECT password_hash
+		FROM users
+		WHERE id = ?
+		  AND deleted_at IS NULL
+	`
+
+	var hash string
+	if err := s.queryRow(ctx, qry, id).Scan(&hash); err != nil {
+		return "", translateError(err)
+	}
+
+	return hash, nil
+}
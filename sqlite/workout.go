@@ -3,26 +3,127 @@ package sqlite
 import (
 	"context"
 	"database/sql"
-	"errors"
+	"strings"
 	"time"
 
 	"github.com/ekholme/flexcreek"
+	"github.com/google/uuid"
 )
 
+// columns selected by every workout read query, in the order scanWorkout expects
+const workoutColumns = `
+		id,
+		uuid,
+		user_id,
+		short_description,
+		long_description,
+		workout_date,
+		start_time,
+		duration_minutes,
+		rpe,
+		session_load,
+		created_at,
+		deleted_at
+`
+
+// workout_date is stored as a civil date
+const dateLayout = "2006-01-02"
+
+// the form sqlite's CURRENT_TIMESTAMP writes. every stored timestamp is UTC in this form
+const timestampLayout = "2006-01-02 15:04:05"
+
+// formats a workout's date for storage. the date is read in t's own zone, like CivilDate
+func civilDate(t time.Time) string {
+	return t.Format(dateLayout)
+}
+
+func utcTimestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// start times keep their offset so they read back as the local time the workout started
+func startTimeValue(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: t.Format(time.RFC3339), Valid: true}
+}
+
+func parseStartTime(s sql.NullString) (time.Time, error) {
+	if !s.Valid || s.String == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s.String)
+}
+
+// a LIKE pattern matching text that contains q, with q's own wildcards escaped so they match literally
+func containsPattern(q string) string {
+	return "%" + likeEscaper.Replace(q) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scans a single workout row selected with workoutColumns
+func scanWorkout(r rowScanner) (*flexcreek.Workout, error) {
+	var w flexcreek.Workout
+	var longDescription sql.NullString //the column is nullable
+	var workoutDate string
+	var startTime sql.NullString
+	var deletedAt sql.NullTime
+	var id sql.NullString
+
+	err := r.Scan(&w.ID, &id, &w.UserID, &w.ShortDescription, &longDescription, &workoutDate, &startTime, &w.DurationMinutes, &w.RPE, &w.SessionLoad, &w.CreatedAt, &deletedAt)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	w.UUID = id.String
+	w.LongDescription = longDescription.String
+	w.CreatedAt = w.CreatedAt.UTC()
+	w.DeletedAt = deletedAt.Time
+
+	//workout_date is a TEXT column holding YYYY-MM-DD, so the driver hands it back as a string
+	if w.WorkoutDate, err = time.Parse(dateLayout, workoutDate); err != nil {
+		return nil, err
+	}
+
+	if w.StartTime, err = parseStartTime(startTime); err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// CreateWorkout inserts a workout, giving it a new UUID unless it already has one
 func (s *Storage) CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error) {
+	if w.UUID == "" {
+		w.UUID = uuid.NewString()
+	}
+
 	qry := `
 		INSERT INTO workouts (
+			uuid,
 			user_id,
 			short_description,
 			long_description,
-			workout_date	
-		) 
-		VALUES (?, ?, ?, ?)
+			workout_date,
+			start_time,
+			duration_minutes,
+			rpe,
+			session_load
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	res, err := s.db.ExecContext(ctx, qry, w.UserID, w.ShortDescription, w.LongDescription, w.WorkoutDate)
+	res, err := s.exec(ctx, qry, w.UUID, w.UserID, w.ShortDescription, w.LongDescription, civilDate(w.WorkoutDate), startTimeValue(w.StartTime), w.DurationMinutes, w.RPE, w.SessionLoad)
 	if err != nil {
-		return 0, err
+		return 0, translateError(err)
 	}
 
 	id, err := res.LastInsertId()
@@ -35,104 +136,193 @@ func (s *Storage) CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int,
 
 func (s *Storage) GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error) {
 	qry := `
-		SELECT id,
-		user_id,
-		short_description,
-		long_description,
-		workout_date,
-		created_at
+		SELECT ` + workoutColumns + `
 		FROM workouts
 		WHERE id = ?
 		  AND user_id = ?
+		  AND deleted_at IS NULL
 	`
 
-	var w flexcreek.Workout
+	return scanWorkout(s.queryRow(ctx, qry, id, userID))
+}
 
-	if err := s.db.QueryRowContext(ctx, qry, id, userID).Scan(&w.ID, &w.UserID, &w.ShortDescription, &w.LongDescription, &w.WorkoutDate, &w.CreatedAt); err != nil {
-		return nil, err
-	}
+// GetWorkoutByUUID looks up one of userID's workouts by its public identifier
+func (s *Storage) GetWorkoutByUUID(ctx context.Context, id string, userID int) (*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE uuid = ?
+		  AND user_id = ?
+		  AND deleted_at IS NULL
+	`
 
-	return &w, nil
+	return scanWorkout(s.queryRow(ctx, qry, id, userID))
 }
 
 func (s *Storage) GetWorkoutByDate(ctx context.Context, date time.Time, userID int) (*flexcreek.Workout, error) {
 	qry := `
-		SELECT id,
-		user_id,
-		short_description,
-		long_description,
-		workout_date,
-		created_at
+		SELECT ` + workoutColumns + `
 		FROM workouts
 		WHERE user_id = ?
-		  AND date = ?
+		  AND workout_date = ?
+		  AND deleted_at IS NULL
+		ORDER BY id
+		LIMIT 1
 	`
 
-	formattedDate := date.Format("2026-04-01")
+	return scanWorkout(s.queryRow(ctx, qry, userID, civilDate(date)))
+}
 
-	var w flexcreek.Workout
+func (s *Storage) GetLatestWorkouts(ctx context.Context, n int, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = ?
+		  AND deleted_at IS NULL
+		ORDER BY workout_date desc, id desc
+		LIMIT ?;
+	`
 
-	if err := s.db.QueryRowContext(ctx, qry, formattedDate).Scan(&w.ID, &w.UserID, &w.ShortDescription, &w.LongDescription, &w.WorkoutDate, &w.CreatedAt); err != nil {
+	rows, err := s.query(ctx, qry, userID, n)
+
+	if err != nil {
 		return nil, err
 	}
 
-	return &w, nil
+	return scanWorkouts(rows)
 }
 
-func (s *Storage) GetLatestWorkouts(ctx context.Context, n int, userID int) ([]*flexcreek.Workout, error) {
+// GetWorkoutsBetween returns a user's workouts dated between start and end (inclusive), oldest first
+func (s *Storage) GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error) {
 	qry := `
-		SELECT id,
-		user_id,
-		short_description,
-		long_description,
-		workout_date,
-		created_at
+		SELECT ` + workoutColumns + `
 		FROM workouts
-		WHERE user_id = ?	
-		ORDER BY workout_date desc
-		LIMIT ?;
+		WHERE user_id = ?
+		  AND workout_date BETWEEN ? AND ?
+		  AND deleted_at IS NULL
+		ORDER BY workout_date asc, id asc
+	`
+
+	rows, err := s.query(ctx, qry, userID, civilDate(start), civilDate(end))
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWorkouts(rows)
+}
+
+// ListWorkouts returns a page of a user's workouts matching the filter, newest first
+func (s *Storage) ListWorkouts(ctx context.Context, f flexcreek.WorkoutFilter, userID int) ([]*flexcreek.Workout, error) {
+	qry := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE user_id = ?
+		  AND deleted_at IS NULL
 	`
+	args := []any{userID}
+
+	if !f.From.IsZero() {
+		qry += " AND workout_date >= ?"
+		args = append(args, civilDate(f.From))
+	}
+	if !f.To.IsZero() {
+		qry += " AND workout_date <= ?"
+		args = append(args, civilDate(f.To))
+	}
+	if f.Query != "" {
+		qry += ` AND (short_description LIKE ? ESCAPE '\' OR long_description LIKE ? ESCAPE '\')`
+		pattern := containsPattern(f.Query)
+		args = append(args, pattern, pattern)
+	}
 
-	rows, err := s.db.QueryContext(ctx, qry, userID, n)
+	//sqlite treats a negative limit as no limit
+	limit := f.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	qry += " ORDER BY workout_date desc, id desc LIMIT ? OFFSET ?"
+	args = append(args, limit, f.Offset)
 
+	rows, err := s.query(ctx, qry, args...)
 	if err != nil {
 		return nil, err
 	}
 
+	return scanWorkouts(rows)
+}
+
+// scans and closes a set of workout rows
+func scanWorkouts(rows *sql.Rows) ([]*flexcreek.Workout, error) {
 	defer rows.Close()
 
 	var workouts []*flexcreek.Workout
 
 	for rows.Next() {
-		var w flexcreek.Workout
-
-		err = rows.Scan(&w.ID, &w.UserID, &w.ShortDescription, &w.LongDescription, &w.WorkoutDate, &w.CreatedAt)
-
+		w, err := scanWorkout(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		workouts = append(workouts, &w)
+		workouts = append(workouts, w)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
 	return workouts, nil
 }
 
+// UpdateWorkout replaces a workout's editable fields, first saving the current values as a revision
 func (s *Storage) UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	current := `
+		SELECT ` + workoutColumns + `
+		FROM workouts
+		WHERE id = ?
+		  AND user_id = ?
+		  AND deleted_at IS NULL
+	`
+
+	old, err := scanWorkout(tx.QueryRowContext(ctx, current, w.ID, w.UserID))
+	if err != nil {
+		return err
+	}
+
+	//saving without changing anything leaves no revision behind
+	if len(flexcreek.DiffWorkouts(old, w)) == 0 {
+		return nil
+	}
+
+	changedBy, ok := flexcreek.ActorFrom(ctx)
+	if !ok {
+		changedBy = w.UserID
+	}
+
+	if err := saveRevision(ctx, tx.Tx, w.ID, w.UserID, changedBy); err != nil {
+		return err
+	}
+
 	qry := `
 		UPDATE workouts
 		SET short_description = ?,
 		long_description = ?,
-		workout_date = ?
+		workout_date = ?,
+		start_time = ?,
+		duration_minutes = ?,
+		rpe = ?,
+		session_load = ?
 		WHERE id = ?
 		  AND user_id = ?
+		  AND deleted_at IS NULL
 	`
 
-	res, err := s.db.ExecContext(ctx, qry, w.ShortDescription, w.LongDescription, w.WorkoutDate, w.ID, w.UserID)
+	res, err := tx.ExecContext(ctx, qry, w.ShortDescription, w.LongDescription, civilDate(w.WorkoutDate), startTimeValue(w.StartTime), w.DurationMinutes, w.RPE, w.SessionLoad, w.ID, w.UserID)
 
 	if err != nil {
 		return err
@@ -141,22 +331,27 @@ func (s *Storage) UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error
 	rowsAffected, err := res.RowsAffected()
 
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("no workout found with that ID for this user")
+		return flexcreek.ErrNotFound
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (s *Storage) DeleteWorkout(ctx context.Context, id int) error {
+// DeleteWorkout moves a workout owned by userID to the trash. workouts belonging to anyone else are reported as not found
+func (s *Storage) DeleteWorkout(ctx context.Context, id int, userID int) error {
 	qry := `
-		DELETE FROM workouts WHERE id = ?	
+		UPDATE workouts
+		SET deleted_at = ?
+		WHERE id = ?
+		  AND user_id = ?
+		  AND deleted_at IS NULL
 	`
 
-	res, err := s.db.ExecContext(ctx, qry, id)
+	res, err := s.exec(ctx, qry, trashTime(), id, userID)
 
 	if err != nil {
 		return err
@@ -169,8 +364,19 @@ func (s *Storage) DeleteWorkout(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return sql.ErrNoRows
+		return flexcreek.ErrNotFound
 	}
 
 	return nil
 }
+
+// CloneWorkout saves a copy of one of userID's workouts on date, returning the copy's ID.
+// the original's revisions stay with it, the copy starts with no history
+func (s *Storage) CloneWorkout(ctx context.Context, id int, userID int, date time.Time) (int, error) {
+	w, err := s.GetWorkoutByID(ctx, id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.CreateWorkout(ctx, w.CopyTo(date))
+}
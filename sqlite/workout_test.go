@@ -0,0 +1,252 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := NewStorage(db)
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// zones either side of UTC, including ones where local midnight is the previous or next day in UTC
+var testZones = []string{
+	"UTC",
+	"America/Los_Angeles",
+	"Pacific/Pago_Pago",
+	"Asia/Tokyo",
+	"Pacific/Kiritimati",
+}
+
+func TestWorkoutDatesRoundTrip(t *testing.T) {
+	for _, name := range testZones {
+		t.Run(name, func(t *testing.T) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				t.Skipf("zone data not available: %v", err)
+			}
+
+			//the machine's zone shouldn't matter either
+			defer func(l *time.Location) { time.Local = l }(time.Local)
+			time.Local = loc
+
+			s := newTestStorage(t)
+			ctx := context.Background()
+
+			userID, err := s.CreateUser(ctx, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cases := []struct {
+				name string
+				date time.Time
+			}{
+				{"midnight", time.Date(2026, 3, 1, 0, 0, 0, 0, loc)},
+				{"late evening", time.Date(2026, 3, 1, 23, 30, 0, 0, loc)},
+				{"civil date", flexcreek.CivilDate(time.Date(2026, 3, 1, 12, 0, 0, 0, loc))},
+			}
+
+			want := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+			start := time.Date(2026, 3, 1, 7, 30, 0, 0, loc)
+
+			for _, c := range cases {
+				id, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: userID, ShortDescription: c.name, WorkoutDate: c.date, StartTime: start})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				w, err := s.GetWorkoutByID(ctx, id, userID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !w.WorkoutDate.Equal(want) {
+					t.Errorf("%s: workout date = %v, want %v", c.name, w.WorkoutDate, want)
+				}
+
+				if !w.StartTime.Equal(start) {
+					t.Errorf("%s: start time = %v, want %v", c.name, w.StartTime, start)
+				}
+
+				_, wantOffset := start.Zone()
+				if _, offset := w.StartTime.Zone(); offset != wantOffset {
+					t.Errorf("%s: start time offset = %d, want %d", c.name, offset, wantOffset)
+				}
+
+				if w.CreatedAt.Location() != time.UTC {
+					t.Errorf("%s: created_at is in %v, want UTC", c.name, w.CreatedAt.Location())
+				}
+
+				if d := time.Since(w.CreatedAt); d < -time.Minute || d > time.Minute {
+					t.Errorf("%s: created_at = %v, want about now", c.name, w.CreatedAt)
+				}
+			}
+
+			found, err := s.GetWorkoutByDate(ctx, time.Date(2026, 3, 1, 0, 0, 0, 0, loc), userID)
+			if err != nil {
+				t.Fatalf("looking up by date: %v", err)
+			}
+			if found.ShortDescription != "midnight" {
+				t.Errorf("GetWorkoutByDate returned %q", found.ShortDescription)
+			}
+
+			between, err := s.GetWorkoutsBetween(ctx, want, want, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(between) != len(cases) {
+				t.Errorf("GetWorkoutsBetween found %d workouts, want %d", len(between), len(cases))
+			}
+		})
+	}
+}
+
+func TestUserCreatedAtIsUTC(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("zone data not available: %v", err)
+	}
+
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	time.Local = loc
+
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.CreatedAt.Location() != time.UTC {
+		t.Errorf("created_at is in %v, want UTC", u.CreatedAt.Location())
+	}
+
+	if d := time.Since(u.CreatedAt); d < -time.Minute || d > time.Minute {
+		t.Errorf("created_at = %v, want about now", u.CreatedAt)
+	}
+}
+
+// the user-scoped lookups and deletes treat another user's workouts as if they don't exist
+func TestWorkoutCrossUserIsolation(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := s.CreateUser(ctx, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	id, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "KB ABC", WorkoutDate: date})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetWorkoutByDate(ctx, date, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByDate as bob returned %v, want ErrNotFound", err)
+	}
+
+	//alice's id and an id nobody has look the same to bob
+	for _, foreign := range []int{id, id + 100} {
+		if err := s.DeleteWorkout(ctx, foreign, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+			t.Errorf("DeleteWorkout(%d) as bob returned %v, want ErrNotFound", foreign, err)
+		}
+	}
+
+	w, err := s.GetWorkoutByDate(ctx, date, alice)
+	if err != nil {
+		t.Fatalf("alice's workout is gone after bob's delete: %v", err)
+	}
+	if w.ID != id || !w.DeletedAt.IsZero() {
+		t.Errorf("alice's workout is %+v after bob's delete, want it untouched", *w)
+	}
+}
+
+func TestUpdateWorkoutWithoutChangesKeepsNoRevision(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	alice, err := s.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: alice, ShortDescription: "Easy run", WorkoutDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), DurationMinutes: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := s.GetWorkoutByID(ctx, id, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//saving it untouched, twice
+	for range 2 {
+		if err := s.UpdateWorkout(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if revs, err := s.ListWorkoutRevisions(ctx, id, alice); err != nil || len(revs) != 0 {
+		t.Errorf("after saving without changes there are %d revisions (%v), want none", len(revs), err)
+	}
+
+	w.DurationMinutes = 45
+	if err := s.UpdateWorkout(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+	revs, err := s.ListWorkoutRevisions(ctx, id, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 1 || revs[0].Workout.DurationMinutes != 30 {
+		t.Errorf("after changing the duration the revisions are %v, want one of the 30 minute version", revs)
+	}
+
+	//an edit that only adds whitespace is still an edit
+	w.ShortDescription = "Easy run "
+	if err := s.UpdateWorkout(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+	if revs, err := s.ListWorkoutRevisions(ctx, id, alice); err != nil || len(revs) != 2 {
+		t.Errorf("after a whitespace edit there are %d revisions (%v), want 2", len(revs), err)
+	}
+	if got, err := s.GetWorkoutByID(ctx, id, alice); err != nil || got.ShortDescription != "Easy run " {
+		t.Errorf("after a whitespace edit the workout is %v (%v), want the trailing space kept", got, err)
+	}
+
+	//a workout that isn't the user's is still not found
+	if err := s.UpdateWorkout(ctx, &flexcreek.Workout{ID: id, UserID: alice + 1}); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating a workout that isn't the user's returned %v, want ErrNotFound", err)
+	}
+}
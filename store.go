@@ -0,0 +1,86 @@
+package flexcreek
+
+import (
+	"context"
+	"time"
+)
+
+// UserStore is the user half of the storage contract every backend implements
+type UserStore interface {
+	CreateUser(ctx context.Context, username string) (int, error)
+	GetUserByID(ctx context.Context, id int) (*User, error)
+	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUserByUUID(ctx context.Context, id string) (*User, error)
+	GetAllUsers(ctx context.Context) ([]*User, error)
+	UpdateUserPreferences(ctx context.Context, id int, p Preferences) error
+	DeleteUser(ctx context.Context, id int) error
+}
+
+// WorkoutStore is the workout half of the storage contract. every method is scoped to the owning user
+type WorkoutStore interface {
+	CreateWorkout(ctx context.Context, w *Workout) (int, error)
+	GetWorkoutByID(ctx context.Context, id int, userID int) (*Workout, error)
+	GetWorkoutByUUID(ctx context.Context, id string, userID int) (*Workout, error)
+	GetWorkoutByDate(ctx context.Context, date time.Time, userID int) (*Workout, error)
+	GetLatestWorkouts(ctx context.Context, n int, userID int) ([]*Workout, error)
+	GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*Workout, error)
+	ListWorkouts(ctx context.Context, f WorkoutFilter, userID int) ([]*Workout, error)
+	// UpdateWorkout saves the workout's previous values as a revision before replacing them, unless nothing changed
+	UpdateWorkout(ctx context.Context, w *Workout) error
+	DeleteWorkout(ctx context.Context, id int, userID int) error
+	// CloneWorkout saves a copy of one of userID's workouts on date (see Workout.CopyTo) and returns its ID
+	CloneWorkout(ctx context.Context, id int, userID int, date time.Time) (int, error)
+}
+
+// TrashStore brings deleted users and workouts back, or removes them for good
+type TrashStore interface {
+	ListTrashedUsers(ctx context.Context) ([]*User, error)
+	ListTrashedWorkouts(ctx context.Context, userID int) ([]*Workout, error)
+	// RestoreUser also restores the workouts that were trashed along with the user
+	RestoreUser(ctx context.Context, id int) error
+	// RestoreWorkout fails with ErrNotFound while the workout's user is in the trash
+	RestoreWorkout(ctx context.Context, id int, userID int) error
+	// PurgeTrash removes what was trashed before the cutoff, and everything belonging to purged users
+	PurgeTrash(ctx context.Context, before time.Time) (users int, workouts int, err error)
+}
+
+// RevisionStore reads back the versions UpdateWorkout saved
+type RevisionStore interface {
+	ListWorkoutRevisions(ctx context.Context, workoutID int, userID int) ([]*WorkoutRevision, error)
+}
+
+// MeasurementStore holds a user's body measurements. reads skip users in the trash
+type MeasurementStore interface {
+	CreateMeasurement(ctx context.Context, m *Measurement) (int, error)
+	GetMeasurementByID(ctx context.Context, id int, userID int) (*Measurement, error)
+	// GetLatestMeasurements returns the n most recent measurements across all metrics, newest first
+	GetLatestMeasurements(ctx context.Context, n int, userID int) ([]*Measurement, error)
+	// GetMeasurementsByMetric returns every measurement of one metric, oldest first
+	GetMeasurementsByMetric(ctx context.Context, metric string, userID int) ([]*Measurement, error)
+	UpdateMeasurement(ctx context.Context, m *Measurement) error
+	DeleteMeasurement(ctx context.Context, id int, userID int) error
+}
+
+// GoalStore holds a user's goals. reads skip users in the trash
+type GoalStore interface {
+	// CreateGoal rejects a goal that fails Goal.Validate
+	CreateGoal(ctx context.Context, g *Goal) (int, error)
+	// GetGoals returns all of a user's goals, oldest first
+	GetGoals(ctx context.Context, userID int) ([]*Goal, error)
+	DeleteGoal(ctx context.Context, id int, userID int) error
+}
+
+// Store is a storage backend
+type Store interface {
+	UserStore
+	WorkoutStore
+	TrashStore
+	RevisionStore
+	MeasurementStore
+	GoalStore
+
+	// WithTx runs fn in a single transaction, committing if it returns nil and rolling back if it
+	// returns an error or panics. fn must make its calls through tx. calling WithTx on tx nests
+	// a savepoint, so an error the outer fn handles only undoes the inner fn's work
+	WithTx(ctx context.Context, fn func(tx Store) error) error
+}
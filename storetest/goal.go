@@ -0,0 +1,93 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var goalTests = []test{
+	{"Goals", testGoals},
+	{"GoalsOfTrashedUser", testGoalsOfTrashedUser},
+}
+
+func testGoals(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+
+	weekly := flexcreek.Goal{UserID: alice, Metric: flexcreek.GoalMinutes, Match: "run", Target: 150, Period: flexcreek.PeriodWeek}
+	lift := flexcreek.Goal{UserID: alice, Metric: "deadlift", Target: 200, Unit: "kg", StartDate: day(2026, 3, 1), Deadline: day(2026, 6, 30)}
+
+	var ids []int
+	for _, g := range []flexcreek.Goal{weekly, lift} {
+		id, err := s.CreateGoal(ctx, &g)
+		if err != nil {
+			t.Fatalf("creating goal %+v: %v", g, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if _, err := s.CreateGoal(ctx, &flexcreek.Goal{UserID: alice, Metric: flexcreek.GoalSessions}); err == nil {
+		t.Error("created a goal with no target or period")
+	}
+
+	goals, err := s.GetGoals(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goals) != 2 {
+		t.Fatalf("alice has %d goals, want 2", len(goals))
+	}
+
+	//oldest first, and a periodic goal reads back with no deadline
+	if g := goals[0]; g.ID != ids[0] || g.Metric != weekly.Metric || g.Match != "run" || g.Target != 150 || g.Period != flexcreek.PeriodWeek || !g.Deadline.IsZero() || !recent(g.CreatedAt) {
+		t.Errorf("the first goal is %+v, want the weekly minutes goal", *g)
+	}
+	if g := goals[1]; g.ID != ids[1] || g.Unit != "kg" || !g.StartDate.Equal(lift.StartDate) || !g.Deadline.Equal(lift.Deadline) {
+		t.Errorf("the second goal is %+v, want the deadlift goal", *g)
+	}
+
+	if goals, err := s.GetGoals(ctx, bob); err != nil || len(goals) != 0 {
+		t.Errorf("bob's goals are %v (%v), want none", goals, err)
+	}
+
+	if err := s.DeleteGoal(ctx, ids[0], bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("deleting alice's goal as bob returned %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteGoal(ctx, ids[0], alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteGoal(ctx, ids[0], alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("deleting a goal twice returned %v, want ErrNotFound", err)
+	}
+
+	if goals, err := s.GetGoals(ctx, alice); err != nil || len(goals) != 1 || goals[0].ID != ids[1] {
+		t.Errorf("after the delete alice's goals are %v (%v), want just the deadlift goal", goals, err)
+	}
+}
+
+func testGoalsOfTrashedUser(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+
+	if _, err := s.CreateGoal(ctx, &flexcreek.Goal{UserID: alice, Metric: flexcreek.GoalSessions, Target: 3, Period: flexcreek.PeriodWeek}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if goals, err := s.GetGoals(ctx, alice); err != nil || len(goals) != 0 {
+		t.Errorf("a trashed user's goals are %v (%v), want none", goals, err)
+	}
+
+	if err := s.RestoreUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if goals, err := s.GetGoals(ctx, alice); err != nil || len(goals) != 1 {
+		t.Errorf("after restoring alice her goals are %v (%v), want the one back", goals, err)
+	}
+}
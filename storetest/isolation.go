@@ -0,0 +1,104 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// every workout method takes the acting user, and another user's workouts must look like they don't exist
+var isolationTests = []test{
+	{"IsolationReads", testIsolationReads},
+	{"IsolationLists", testIsolationLists},
+	{"IsolationWrites", testIsolationWrites},
+}
+
+func testIsolationReads(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+
+	if _, err := s.GetWorkoutByID(ctx, w.ID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByID as bob returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetWorkoutByUUID(ctx, w.UUID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByUUID as bob returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetWorkoutByDate(ctx, w.WorkoutDate, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByDate as bob returned %v, want ErrNotFound", err)
+	}
+}
+
+func testIsolationLists(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	createWorkout(t, s, alice, "alice's run", day(2026, 3, 1))
+	createWorkout(t, s, bob, "bob's run", day(2026, 3, 1))
+
+	latest, err := s.GetLatestWorkouts(ctx, 10, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descriptions(latest); !equal(got, []string{"bob's run"}) {
+		t.Errorf("GetLatestWorkouts for bob got %v", got)
+	}
+
+	between, err := s.GetWorkoutsBetween(ctx, day(2026, 1, 1), day(2026, 12, 31), bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descriptions(between); !equal(got, []string{"bob's run"}) {
+		t.Errorf("GetWorkoutsBetween for bob got %v", got)
+	}
+
+	listed, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{Query: "run"}, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descriptions(listed); !equal(got, []string{"bob's run"}) {
+		t.Errorf("ListWorkouts for bob got %v", got)
+	}
+
+	found, err := s.GetWorkoutByDate(ctx, day(2026, 3, 1), bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ShortDescription != "bob's run" {
+		t.Errorf("GetWorkoutByDate for bob got %q", found.ShortDescription)
+	}
+}
+
+func testIsolationWrites(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+
+	//bob claiming the workout as his own doesn't reach alice's row
+	stolen := *w
+	stolen.UserID = bob
+	stolen.ShortDescription = "mine now"
+	if err := s.UpdateWorkout(ctx, &stolen); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("UpdateWorkout as bob returned %v, want ErrNotFound", err)
+	}
+
+	if err := s.DeleteWorkout(ctx, w.ID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("DeleteWorkout as bob returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.CloneWorkout(ctx, w.ID, bob, day(2026, 3, 8)); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("CloneWorkout as bob returned %v, want ErrNotFound", err)
+	}
+
+	got, err := s.GetWorkoutByID(ctx, w.ID, alice)
+	if err != nil {
+		t.Fatalf("alice's workout is gone after bob's attempts: %v", err)
+	}
+	if got.ShortDescription != "KB ABC" || got.UserID != alice {
+		t.Errorf("alice's workout was changed by bob: %+v", got)
+	}
+}
@@ -0,0 +1,175 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var measurementTests = []test{
+	{"Measurements", testMeasurements},
+	{"MeasurementIsolation", testMeasurementIsolation},
+	{"MeasurementsOfTrashedUser", testMeasurementsOfTrashedUser},
+}
+
+// logs a measurement for userID and returns its ID
+func createMeasurement(t *testing.T, s flexcreek.Store, userID int, metric string, value float64, unit string, on time.Time) int {
+	t.Helper()
+
+	id, err := s.CreateMeasurement(context.Background(), &flexcreek.Measurement{UserID: userID, Metric: metric, Value: value, Unit: unit, MeasuredOn: on})
+	if err != nil {
+		t.Fatalf("creating measurement %s: %v", metric, err)
+	}
+
+	return id
+}
+
+// the values of measurements, to compare results in order
+func values(ms []*flexcreek.Measurement) []float64 {
+	var out []float64
+	for _, m := range ms {
+		out = append(out, m.Value)
+	}
+
+	return out
+}
+
+func equalValues(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testMeasurements(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+
+	//logged out of order, with two on the same day
+	first := createMeasurement(t, s, alice, "bodyweight", 80.5, "kg", day(2026, 3, 1))
+	createMeasurement(t, s, alice, "bodyweight", 79.8, "kg", day(2026, 3, 8))
+	createMeasurement(t, s, alice, "waist", 84, "cm", day(2026, 3, 8))
+	createMeasurement(t, s, alice, "bodyweight", 80.1, "kg", day(2026, 3, 4))
+	createMeasurement(t, s, alice, "bodyweight", 80.0, "kg", day(2026, 3, 8))
+
+	m, err := s.GetMeasurementByID(ctx, first, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != first || m.UserID != alice || m.Metric != "bodyweight" || m.Value != 80.5 || m.Unit != "kg" || !m.MeasuredOn.Equal(day(2026, 3, 1)) || !recent(m.CreatedAt) {
+		t.Errorf("read back %+v", *m)
+	}
+
+	//newest first across every metric, the later of two on a day first
+	latest, err := s.GetLatestMeasurements(ctx, 3, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := values(latest); !equalValues(got, []float64{80.0, 84, 79.8}) {
+		t.Errorf("the latest 3 are %v, want [80 84 79.8]", got)
+	}
+
+	//oldest first for one metric
+	weights, err := s.GetMeasurementsByMetric(ctx, "bodyweight", alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := values(weights); !equalValues(got, []float64{80.5, 80.1, 79.8, 80.0}) {
+		t.Errorf("the bodyweights are %v, want [80.5 80.1 79.8 80]", got)
+	}
+	if none, err := s.GetMeasurementsByMetric(ctx, "Bodyweight", alice); err != nil || len(none) != 0 {
+		t.Errorf("a metric in another case found %v (%v), want nothing", values(none), err)
+	}
+
+	m.Value, m.Unit, m.MeasuredOn = 81, "kg", day(2026, 3, 2)
+	if err := s.UpdateMeasurement(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := s.GetMeasurementByID(ctx, first, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Value != 81 || !updated.MeasuredOn.Equal(day(2026, 3, 2)) {
+		t.Errorf("after the update it's %+v", *updated)
+	}
+
+	if err := s.DeleteMeasurement(ctx, first, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetMeasurementByID(ctx, first, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("reading a deleted measurement returned %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteMeasurement(ctx, first, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("deleting a measurement twice returned %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateMeasurement(ctx, m); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating a deleted measurement returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.CreateMeasurement(ctx, &flexcreek.Measurement{UserID: 999, Metric: "bodyweight", Value: 80, Unit: "kg", MeasuredOn: day(2026, 3, 1)}); err == nil {
+		t.Error("created a measurement for a user that doesn't exist")
+	}
+}
+
+// another user's measurements look the same as ones that don't exist
+func testMeasurementIsolation(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	id := createMeasurement(t, s, alice, "bodyweight", 80, "kg", day(2026, 3, 1))
+
+	if _, err := s.GetMeasurementByID(ctx, id, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("reading alice's measurement as bob returned %v, want ErrNotFound", err)
+	}
+	if ms, err := s.GetLatestMeasurements(ctx, 10, bob); err != nil || len(ms) != 0 {
+		t.Errorf("bob's latest measurements are %v (%v), want none", values(ms), err)
+	}
+	if ms, err := s.GetMeasurementsByMetric(ctx, "bodyweight", bob); err != nil || len(ms) != 0 {
+		t.Errorf("bob's bodyweights are %v (%v), want none", values(ms), err)
+	}
+
+	stolen := &flexcreek.Measurement{ID: id, UserID: bob, Metric: "bodyweight", Value: 1, Unit: "kg", MeasuredOn: day(2026, 3, 1)}
+	if err := s.UpdateMeasurement(ctx, stolen); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating alice's measurement as bob returned %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteMeasurement(ctx, id, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("deleting alice's measurement as bob returned %v, want ErrNotFound", err)
+	}
+
+	if m, err := s.GetMeasurementByID(ctx, id, alice); err != nil || m.Value != 80 {
+		t.Errorf("after bob's attempts alice's measurement is %v (%v), want it untouched", m, err)
+	}
+}
+
+func testMeasurementsOfTrashedUser(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	id := createMeasurement(t, s, alice, "bodyweight", 80, "kg", day(2026, 3, 1))
+
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetMeasurementByID(ctx, id, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("reading a trashed user's measurement returned %v, want ErrNotFound", err)
+	}
+	if ms, err := s.GetLatestMeasurements(ctx, 10, alice); err != nil || len(ms) != 0 {
+		t.Errorf("a trashed user's latest measurements are %v (%v), want none", values(ms), err)
+	}
+
+	if err := s.RestoreUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if ms, err := s.GetMeasurementsByMetric(ctx, "bodyweight", alice); err != nil || len(ms) != 1 {
+		t.Errorf("after restoring alice her bodyweights are %v (%v), want the one back", values(ms), err)
+	}
+}
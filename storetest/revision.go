@@ -0,0 +1,72 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var revisionTests = []test{
+	{"WorkoutRevisions", testWorkoutRevisions},
+	{"UnchangedUpdateKeepsNoRevision", testUnchangedUpdateKeepsNoRevision},
+}
+
+func testWorkoutRevisions(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	admin := createUser(t, s, "admin")
+	bob := createUser(t, s, "bob")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+
+	edited := *w
+	edited.ShortDescription = "KB ABC heavy"
+	if err := s.UpdateWorkout(ctx, &edited); err != nil {
+		t.Fatal(err)
+	}
+
+	again := edited
+	again.RPE = 9
+	if err := s.UpdateWorkout(flexcreek.WithActor(ctx, admin), &again); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := s.ListWorkoutRevisions(ctx, w.ID, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("after two edits there are %d revisions, want 2", len(revs))
+	}
+
+	//newest first, each holding the values from before its edit
+	if r := revs[0]; r.Workout.ShortDescription != "KB ABC heavy" || r.Workout.RPE != w.RPE || r.ChangedBy != admin {
+		t.Errorf("the newest revision is %+v by %d, want the heavy version before the rpe change, by admin", r.Workout, r.ChangedBy)
+	}
+	if r := revs[1]; r.Workout.ShortDescription != "KB ABC" || r.ChangedBy != alice || !recent(r.ChangedAt) {
+		t.Errorf("the oldest revision is %+v by %d at %v, want the original by alice just now", r.Workout, r.ChangedBy, r.ChangedAt)
+	}
+	if r := revs[1].Workout; r.ID != w.ID || r.UserID != alice || !r.WorkoutDate.Equal(w.WorkoutDate) || r.DurationMinutes != w.DurationMinutes || r.SessionLoad != w.SessionLoad {
+		t.Errorf("the oldest revision is %+v, want the rest of it to match %+v", r, *w)
+	}
+
+	if revs, err := s.ListWorkoutRevisions(ctx, w.ID, bob); err != nil || len(revs) != 0 {
+		t.Errorf("bob sees %d revisions of alice's workout (%v), want none", len(revs), err)
+	}
+}
+
+func testUnchangedUpdateKeepsNoRevision(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+
+	for range 2 {
+		if err := s.UpdateWorkout(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if revs, err := s.ListWorkoutRevisions(ctx, w.ID, alice); err != nil || len(revs) != 0 {
+		t.Errorf("saving without changes left %d revisions (%v), want none", len(revs), err)
+	}
+}
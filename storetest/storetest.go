@@ -0,0 +1,106 @@
+// Package storetest is a conformance suite for flexcreek.Store implementations.
+// every backend runs it from its own tests, so they all behave the same way behind the contract
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// a single conformance test. it gets a store with nothing in it
+type test struct {
+	name string
+	fn   func(t *testing.T, s flexcreek.Store)
+}
+
+// Run runs the whole suite. open must return a freshly migrated, empty store each time it's called,
+// and clean it up when t finishes
+func Run(t *testing.T, open func(t *testing.T) flexcreek.Store) {
+	var tests []test
+	tests = append(tests, userTests...)
+	tests = append(tests, workoutTests...)
+	tests = append(tests, isolationTests...)
+	tests = append(tests, trashTests...)
+	tests = append(tests, revisionTests...)
+	tests = append(tests, measurementTests...)
+	tests = append(tests, goalTests...)
+	tests = append(tests, txTests...)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, open(t))
+		})
+	}
+}
+
+func createUser(t *testing.T, s flexcreek.Store, username string) int {
+	t.Helper()
+
+	id, err := s.CreateUser(context.Background(), username)
+	if err != nil {
+		t.Fatalf("creating user %s: %v", username, err)
+	}
+
+	return id
+}
+
+// creates a workout for userID on the given day and returns it as stored
+func createWorkout(t *testing.T, s flexcreek.Store, userID int, desc string, date time.Time) *flexcreek.Workout {
+	t.Helper()
+	ctx := context.Background()
+
+	id, err := s.CreateWorkout(ctx, &flexcreek.Workout{
+		UserID:           userID,
+		ShortDescription: desc,
+		WorkoutDate:      date,
+		DurationMinutes:  45,
+		RPE:              7,
+		SessionLoad:      315,
+	})
+	if err != nil {
+		t.Fatalf("creating workout %s: %v", desc, err)
+	}
+
+	w, err := s.GetWorkoutByID(ctx, id, userID)
+	if err != nil {
+		t.Fatalf("reading back workout %s: %v", desc, err)
+	}
+
+	return w
+}
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// the short descriptions of workouts, to compare results in order
+func descriptions(workouts []*flexcreek.Workout) []string {
+	var out []string
+	for _, w := range workouts {
+		out = append(out, w.ShortDescription)
+	}
+
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// reports whether t is within a minute of now, for columns the database fills in
+func recent(t time.Time) bool {
+	d := time.Since(t)
+	return d > -time.Minute && d < time.Minute
+}
@@ -0,0 +1,170 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var trashTests = []test{
+	{"ListTrash", testListTrash},
+	{"RestoreUser", testRestoreUser},
+	{"RestoreWorkout", testRestoreWorkout},
+	{"PurgeTrash", testPurgeTrash},
+}
+
+func testListTrash(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	createUser(t, s, "bob")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := s.ListTrashedUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != alice || users[0].DeletedAt.IsZero() {
+		t.Errorf("the trashed users are %v, want just alice", users)
+	}
+
+	workouts, err := s.ListTrashedWorkouts(ctx, alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workouts) != 1 || workouts[0].ID != w.ID || workouts[0].DeletedAt.IsZero() {
+		t.Errorf("alice's trashed workouts are %v, want the one she had", workouts)
+	}
+}
+
+func testRestoreUser(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+	createWorkout(t, s, alice, "Easy run", day(2026, 3, 2))
+	swim := createWorkout(t, s, alice, "Swim", day(2026, 3, 3))
+
+	//trashed on its own just before alice, likely in the same second
+	if err := s.DeleteWorkout(ctx, swim.ID, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	//a second time round checks that restoring leaves nothing behind to confuse the next restore
+	for range 2 {
+		if err := s.DeleteUser(ctx, alice); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.RestoreUser(ctx, alice); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.GetUserByID(ctx, alice); err != nil {
+			t.Errorf("alice isn't back: %v", err)
+		}
+		got, err := s.GetLatestWorkouts(ctx, 10, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"Easy run", "KB ABC"}; !equal(descriptions(got), want) {
+			t.Errorf("after restoring alice her workouts are %v, want %v", descriptions(got), want)
+		}
+		trashed, err := s.ListTrashedWorkouts(ctx, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"Swim"}; !equal(descriptions(trashed), want) {
+			t.Errorf("after restoring alice her trash holds %v, want %v", descriptions(trashed), want)
+		}
+	}
+
+	if err := s.RestoreUser(ctx, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("restoring a user who isn't in the trash returned %v, want ErrNotFound", err)
+	}
+	if err := s.RestoreUser(ctx, alice+100); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("restoring a user who doesn't exist returned %v, want ErrNotFound", err)
+	}
+}
+
+func testRestoreWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+
+	if err := s.DeleteWorkout(ctx, w.ID, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RestoreWorkout(ctx, w.ID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("bob restoring alice's workout returned %v, want ErrNotFound", err)
+	}
+	if err := s.RestoreWorkout(ctx, w.ID, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, w.ID, alice); err != nil {
+		t.Errorf("the workout isn't back: %v", err)
+	}
+	if err := s.RestoreWorkout(ctx, w.ID, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("restoring a workout that isn't in the trash returned %v, want ErrNotFound", err)
+	}
+
+	//a trashed user's workouts wait for the user
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RestoreWorkout(ctx, w.ID, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("restoring a workout of a trashed user returned %v, want ErrNotFound", err)
+	}
+}
+
+func testPurgeTrash(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+	trashed := createWorkout(t, s, bob, "Easy run", day(2026, 3, 1))
+	kept := createWorkout(t, s, bob, "Long run", day(2026, 3, 2))
+
+	if err := s.DeleteWorkout(ctx, trashed.ID, bob); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	//everything was trashed after this cutoff
+	users, workouts, err := s.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 || workouts != 0 {
+		t.Errorf("purging before anything was trashed removed %d users and %d workouts", users, workouts)
+	}
+
+	users, workouts, err = s.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users != 1 || workouts != 2 {
+		t.Errorf("the purge removed %d users and %d workouts, want alice and 2 workouts", users, workouts)
+	}
+
+	if trash, _ := s.ListTrashedUsers(ctx); len(trash) != 0 {
+		t.Errorf("after the purge the trash still holds %v", trash)
+	}
+	if trash, _ := s.ListTrashedWorkouts(ctx, bob); len(trash) != 0 {
+		t.Errorf("after the purge bob's trash still holds %v", trash)
+	}
+	if _, err := s.GetWorkoutByID(ctx, kept.ID, bob); err != nil {
+		t.Errorf("bob's workout outside the trash went in the purge: %v", err)
+	}
+
+	//a purged user's name is free again
+	createUser(t, s, "alice")
+}
@@ -0,0 +1,128 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var txTests = []test{
+	{"WithTxCommits", testWithTxCommits},
+	{"WithTxRollsBack", testWithTxRollsBack},
+	{"WithTxNested", testWithTxNested},
+}
+
+func usernames(t *testing.T, s flexcreek.Store) []string {
+	t.Helper()
+
+	users, err := s.GetAllUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+
+	return names
+}
+
+func testWithTxCommits(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		id, err := tx.CreateUser(ctx, "alice")
+		if err != nil {
+			return err
+		}
+
+		w := createWorkout(t, tx, id, "KB ABC", day(2026, 3, 1))
+		w.ShortDescription = "KB ABC, heavier"
+		return tx.UpdateWorkout(ctx, w)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workouts, err := s.GetLatestWorkouts(ctx, 10, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descriptions(workouts); !equal(got, []string{"KB ABC, heavier"}) {
+		t.Errorf("got workouts %v after commit", got)
+	}
+}
+
+func testWithTxRollsBack(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	createUser(t, s, "alice")
+	failed := errors.New("failed")
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		if _, err := tx.CreateUser(ctx, "bob"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("WithTx returned %v, want %v", err, failed)
+	}
+
+	//a failing statement has to roll back cleanly too
+	err = s.WithTx(ctx, func(tx flexcreek.Store) error {
+		if _, err := tx.CreateUser(ctx, "carol"); err != nil {
+			return err
+		}
+		_, err := tx.CreateUser(ctx, "alice")
+		return err
+	})
+	if !errors.Is(err, flexcreek.ErrConflict) {
+		t.Fatalf("WithTx returned %v, want ErrConflict", err)
+	}
+
+	if got := usernames(t, s); !equal(got, []string{"alice"}) {
+		t.Errorf("got users %v after rolling back, want [alice]", got)
+	}
+}
+
+func testWithTxNested(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+
+	err := s.WithTx(ctx, func(tx flexcreek.Store) error {
+		if _, err := tx.CreateUser(ctx, "alice"); err != nil {
+			return err
+		}
+
+		//a failed inner unit only undoes its own work, even when the failure came from the database
+		inner := tx.WithTx(ctx, func(tx flexcreek.Store) error {
+			if _, err := tx.CreateUser(ctx, "bob"); err != nil {
+				return err
+			}
+			_, err := tx.CreateUser(ctx, "alice")
+			return err
+		})
+		if !errors.Is(inner, flexcreek.ErrConflict) {
+			t.Errorf("the inner WithTx returned %v, want ErrConflict", inner)
+		}
+
+		return tx.WithTx(ctx, func(tx flexcreek.Store) error {
+			_, err := tx.CreateUser(ctx, "carol")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := usernames(t, s); !equal(got, []string{"alice", "carol"}) {
+		t.Errorf("got users %v, want [alice carol]", got)
+	}
+}
@@ -0,0 +1,320 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var userTests = []test{
+	{"CreateUser", testCreateUser},
+	{"CreateUserConflict", testCreateUserConflict},
+	{"CreateUserNameInTrash", testCreateUserNameInTrash},
+	{"GetUserNotFound", testGetUserNotFound},
+	{"GetUserByUUID", testGetUserByUUID},
+	{"GetAllUsers", testGetAllUsers},
+	{"UpdateUserPreferences", testUpdateUserPreferences},
+	{"UpdateUserPreferencesInvalid", testUpdateUserPreferencesInvalid},
+	{"DeleteUser", testDeleteUser},
+	{"DeletedUserLookups", testDeletedUserLookups},
+}
+
+func testCreateUser(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	id := createUser(t, s, "alice")
+
+	u, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.ID != id || u.Username != "alice" {
+		t.Errorf("got user %d %q, want %d alice", u.ID, u.Username, id)
+	}
+	if u.UUID == "" {
+		t.Error("the user wasn't given a uuid")
+	}
+	if u.CreatedAt.Location() != time.UTC || !recent(u.CreatedAt) {
+		t.Errorf("created_at = %v, want about now in UTC", u.CreatedAt)
+	}
+	if !u.DeletedAt.IsZero() {
+		t.Errorf("a new user has deleted_at %v", u.DeletedAt)
+	}
+	if u.Preferences != flexcreek.DefaultPreferences() {
+		t.Errorf("got preferences %+v, want the defaults", u.Preferences)
+	}
+
+	byName, err := s.GetUserByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byName.ID != id {
+		t.Errorf("GetUserByUsername returned user %d, want %d", byName.ID, id)
+	}
+
+	byUUID, err := s.GetUserByUUID(ctx, u.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byUUID.ID != id {
+		t.Errorf("GetUserByUUID returned user %d, want %d", byUUID.ID, id)
+	}
+}
+
+func testCreateUserConflict(t *testing.T, s flexcreek.Store) {
+	createUser(t, s, "alice")
+
+	if _, err := s.CreateUser(context.Background(), "alice"); !errors.Is(err, flexcreek.ErrConflict) {
+		t.Errorf("creating a second alice returned %v, want ErrConflict", err)
+	}
+}
+
+func testCreateUserNameInTrash(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	id := createUser(t, s, "alice")
+	if err := s.DeleteUser(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.CreateUser(ctx, "alice")
+	if !errors.Is(err, flexcreek.ErrConflict) || !errors.Is(err, flexcreek.ErrNameInTrash) {
+		t.Errorf("reusing a trashed user's name returned %v, want ErrConflict and ErrNameInTrash", err)
+	}
+
+	//a live user's name is just taken
+	createUser(t, s, "bob")
+	if _, err := s.CreateUser(ctx, "bob"); errors.Is(err, flexcreek.ErrNameInTrash) {
+		t.Errorf("reusing a live user's name returned %v, which blames the trash", err)
+	}
+}
+
+func testGetUserNotFound(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+
+	if _, err := s.GetUserByID(ctx, 999); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetUserByID returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetUserByUsername(ctx, "nobody"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetUserByUsername returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetUserByUUID(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetUserByUUID returned %v, want ErrNotFound", err)
+	}
+}
+
+func testGetUserByUUID(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice, err := s.GetUserByID(ctx, createUser(t, s, "alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := s.GetUserByID(ctx, createUser(t, s, "bob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alice.UUID == "" || alice.UUID == bob.UUID {
+		t.Fatalf("alice has uuid %q and bob %q, want two different ones", alice.UUID, bob.UUID)
+	}
+
+	for _, want := range []*flexcreek.User{alice, bob} {
+		got, err := s.GetUserByUUID(ctx, want.UUID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != want.ID || got.Username != want.Username || got.UUID != want.UUID || !got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("looking up %s returned %+v, want %+v", want.UUID, *got, *want)
+		}
+	}
+
+	//it's an exact match, not a prefix or a case-folded one
+	for _, id := range []string{alice.UUID[:8], strings.ToUpper(alice.UUID), "", "not-a-uuid"} {
+		if _, err := s.GetUserByUUID(ctx, id); !errors.Is(err, flexcreek.ErrNotFound) {
+			t.Errorf("GetUserByUUID(%q) returned %v, want ErrNotFound", id, err)
+		}
+	}
+
+	if err := s.DeleteUser(ctx, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetUserByUUID(ctx, alice.UUID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("a trashed user's uuid returned %v, want ErrNotFound", err)
+	}
+	if err := s.RestoreUser(ctx, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetUserByUUID(ctx, alice.UUID); err != nil || got.ID != alice.ID {
+		t.Errorf("after restoring alice her uuid returned %v, %v", got, err)
+	}
+}
+
+func testGetAllUsers(t *testing.T, s flexcreek.Store) {
+	users, err := s.GetAllUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("a new store has %d users", len(users))
+	}
+
+	createUser(t, s, "alice")
+	createUser(t, s, "bob")
+
+	users, err = s.GetAllUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	if !equal(names, []string{"alice", "bob"}) {
+		t.Errorf("got users %v, want [alice bob]", names)
+	}
+}
+
+func testUpdateUserPreferences(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	id := createUser(t, s, "alice")
+
+	p := flexcreek.Preferences{
+		WeightUnit:     "lb",
+		DistanceUnit:   "mi",
+		FirstDayOfWeek: time.Sunday,
+		DateFormat:     "01/02/2006",
+		TimeZone:       "America/New_York",
+	}
+	if err := s.UpdateUserPreferences(ctx, id, p); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Preferences != p {
+		t.Errorf("got preferences %+v, want %+v", u.Preferences, p)
+	}
+
+	if err := s.UpdateUserPreferences(ctx, 999, p); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating a missing user returned %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdateUserPreferencesInvalid(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	id := createUser(t, s, "alice")
+
+	p := flexcreek.DefaultPreferences()
+	p.WeightUnit = "stone"
+	if err := s.UpdateUserPreferences(ctx, id, p); err == nil {
+		t.Fatal("an unsupported weight unit was accepted")
+	}
+
+	u, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Preferences != flexcreek.DefaultPreferences() {
+		t.Errorf("a rejected update changed the preferences to %+v", u.Preferences)
+	}
+}
+
+func testDeleteUser(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	w := createWorkout(t, s, alice, "KB ABC", day(2026, 3, 1))
+	createWorkout(t, s, bob, "Run", day(2026, 3, 1))
+
+	if err := s.DeleteUser(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetUserByID(ctx, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetUserByID on a deleted user returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, w.ID, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("a deleted user's workout is still found: %v", err)
+	}
+	if err := s.DeleteUser(ctx, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("deleting alice again returned %v, want ErrNotFound", err)
+	}
+
+	//nobody else is touched
+	users, err := s.GetAllUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != bob {
+		t.Errorf("got %d users after deleting alice, want just bob", len(users))
+	}
+
+	workouts, err := s.GetLatestWorkouts(ctx, 10, bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workouts) != 1 {
+		t.Errorf("bob has %d workouts after deleting alice, want 1", len(workouts))
+	}
+}
+
+// a deleted user can't be reached any way a live one can, and nor can their workouts
+func testDeletedUserLookups(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	id := createUser(t, s, "alice")
+	w := createWorkout(t, s, id, "KB ABC", day(2026, 3, 1))
+
+	u, err := s.GetUserByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteUser(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetUserByUsername(ctx, "alice"); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetUserByUsername returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetUserByUUID(ctx, u.UUID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetUserByUUID returned %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateUserPreferences(ctx, id, flexcreek.DefaultPreferences()); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("UpdateUserPreferences returned %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.GetWorkoutByUUID(ctx, w.UUID, id); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByUUID returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetWorkoutByDate(ctx, w.WorkoutDate, id); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByDate returned %v, want ErrNotFound", err)
+	}
+	if err := s.UpdateWorkout(ctx, w); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("UpdateWorkout returned %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteWorkout(ctx, w.ID, id); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("DeleteWorkout returned %v, want ErrNotFound", err)
+	}
+
+	latest, err := s.GetLatestWorkouts(ctx, 10, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	between, err := s.GetWorkoutsBetween(ctx, day(2026, 1, 1), day(2026, 12, 31), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{}, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest)+len(between)+len(listed) != 0 {
+		t.Errorf("the deleted user's workouts are still listed: %d, %d and %d", len(latest), len(between), len(listed))
+	}
+}
@@ -0,0 +1,566 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+var workoutTests = []test{
+	{"CreateWorkout", testCreateWorkout},
+	{"CreateWorkoutUnknownUser", testCreateWorkoutUnknownUser},
+	{"GetWorkoutByUUID", testGetWorkoutByUUID},
+	{"WorkoutDates", testWorkoutDates},
+	{"GetLatestWorkouts", testGetLatestWorkouts},
+	{"GetWorkoutsBetween", testGetWorkoutsBetween},
+	{"ListWorkouts", testListWorkouts},
+	{"ListWorkoutsQueryIsLiteral", testListWorkoutsQueryIsLiteral},
+	{"ListWorkoutsLimits", testListWorkoutsLimits},
+	{"SameDayOrdering", testSameDayOrdering},
+	{"NoWorkouts", testNoWorkouts},
+	{"UpdateWorkout", testUpdateWorkout},
+	{"UpdateMissingWorkout", testUpdateMissingWorkout},
+	{"DeleteWorkout", testDeleteWorkout},
+	{"CloneWorkout", testCloneWorkout},
+	{"CloneMissingWorkout", testCloneMissingWorkout},
+}
+
+func testCreateWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+
+	in := &flexcreek.Workout{
+		UserID:           userID,
+		ShortDescription: "KB ABC",
+		LongDescription:  "5 rounds\n10 swings, 5 cleans",
+		WorkoutDate:      day(2026, 3, 1),
+		DurationMinutes:  40,
+		RPE:              8,
+		SessionLoad:      320.5,
+	}
+	id, err := s.CreateWorkout(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.UUID == "" {
+		t.Error("CreateWorkout didn't fill in the uuid")
+	}
+
+	w, err := s.GetWorkoutByID(ctx, id, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.ID != id || w.UUID != in.UUID || w.UserID != userID {
+		t.Errorf("got ids %d %s %d, want %d %s %d", w.ID, w.UUID, w.UserID, id, in.UUID, userID)
+	}
+	if w.ShortDescription != in.ShortDescription || w.LongDescription != in.LongDescription {
+		t.Errorf("got descriptions %q %q", w.ShortDescription, w.LongDescription)
+	}
+	if w.DurationMinutes != 40 || w.RPE != 8 || w.SessionLoad != 320.5 {
+		t.Errorf("got duration %d, rpe %d, load %v", w.DurationMinutes, w.RPE, w.SessionLoad)
+	}
+	if !w.StartTime.IsZero() {
+		t.Errorf("start time = %v, want zero when it wasn't recorded", w.StartTime)
+	}
+	if w.CreatedAt.Location() != time.UTC || !recent(w.CreatedAt) {
+		t.Errorf("created_at = %v, want about now in UTC", w.CreatedAt)
+	}
+
+	byUUID, err := s.GetWorkoutByUUID(ctx, in.UUID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byUUID.ID != id {
+		t.Errorf("GetWorkoutByUUID returned workout %d, want %d", byUUID.ID, id)
+	}
+
+	//a uuid that's already set is kept, which is how imports keep rows matched up
+	kept := &flexcreek.Workout{UUID: "0b9cf1a6-4a5e-4c2b-9a53-6f4b1f1d2e3c", UserID: userID, ShortDescription: "Run", WorkoutDate: day(2026, 3, 2)}
+	keptID, err := s.CreateWorkout(ctx, kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, err := s.GetWorkoutByUUID(ctx, kept.UUID, userID); err != nil || w.ID != keptID {
+		t.Errorf("looking up the given uuid returned %v, %v", w, err)
+	}
+
+	kept.ShortDescription = "Run again"
+	if _, err := s.CreateWorkout(ctx, kept); !errors.Is(err, flexcreek.ErrConflict) {
+		t.Errorf("reusing a uuid returned %v, want ErrConflict", err)
+	}
+}
+
+func testCreateWorkoutUnknownUser(t *testing.T, s flexcreek.Store) {
+	_, err := s.CreateWorkout(context.Background(), &flexcreek.Workout{UserID: 999, ShortDescription: "KB ABC", WorkoutDate: day(2026, 3, 1)})
+	if !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("creating a workout for a missing user returned %v, want ErrNotFound", err)
+	}
+}
+
+// zones either side of UTC, including ones where late evening is already the next day in UTC
+var zones = []string{
+	"UTC",
+	"America/Los_Angeles",
+	"Pacific/Pago_Pago",
+	"Asia/Tokyo",
+	"Pacific/Kiritimati",
+}
+
+// workout dates are calendar dates, so they must come back as the same day whatever zone they went in as
+func testGetWorkoutByUUID(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	alice := createUser(t, s, "alice")
+	bob := createUser(t, s, "bob")
+	run := createWorkout(t, s, alice, "Run", day(2026, 3, 1))
+	lift := createWorkout(t, s, alice, "Lift", day(2026, 3, 1))
+
+	if run.UUID == "" || run.UUID == lift.UUID {
+		t.Fatalf("the run has uuid %q and the lift %q, want two different ones", run.UUID, lift.UUID)
+	}
+
+	for _, want := range []*flexcreek.Workout{run, lift} {
+		got, err := s.GetWorkoutByUUID(ctx, want.UUID, alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ID != want.ID || got.UUID != want.UUID || got.ShortDescription != want.ShortDescription || !got.WorkoutDate.Equal(want.WorkoutDate) {
+			t.Errorf("looking up %s returned %+v, want %+v", want.UUID, *got, *want)
+		}
+	}
+
+	for _, id := range []string{run.UUID[:8], strings.ToUpper(run.UUID), ""} {
+		if _, err := s.GetWorkoutByUUID(ctx, id, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+			t.Errorf("GetWorkoutByUUID(%q) returned %v, want ErrNotFound", id, err)
+		}
+	}
+	if _, err := s.GetWorkoutByUUID(ctx, run.UUID, bob); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("bob looking up alice's run returned %v, want ErrNotFound", err)
+	}
+
+	if err := s.DeleteWorkout(ctx, run.ID, alice); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetWorkoutByUUID(ctx, run.UUID, alice); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("a trashed workout's uuid returned %v, want ErrNotFound", err)
+	}
+	if err := s.RestoreWorkout(ctx, run.ID, alice); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetWorkoutByUUID(ctx, run.UUID, alice); err != nil || got.ID != run.ID {
+		t.Errorf("after restoring the run its uuid returned %v, %v", got, err)
+	}
+}
+
+func testWorkoutDates(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+
+	want := day(2026, 3, 1)
+	for _, name := range zones {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Logf("skipping %s, zone data not available: %v", name, err)
+			continue
+		}
+
+		start := time.Date(2026, 3, 1, 23, 30, 0, 0, loc)
+		id, err := s.CreateWorkout(ctx, &flexcreek.Workout{UserID: userID, ShortDescription: name, WorkoutDate: start, StartTime: start})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w, err := s.GetWorkoutByID(ctx, id, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !w.WorkoutDate.Equal(want) {
+			t.Errorf("%s: workout date = %v, want %v", name, w.WorkoutDate, want)
+		}
+		if !w.StartTime.Equal(start) {
+			t.Errorf("%s: start time = %v, want %v", name, w.StartTime, start)
+		}
+		_, wantOffset := start.Zone()
+		if _, offset := w.StartTime.Zone(); offset != wantOffset {
+			t.Errorf("%s: start time offset = %d, want %d", name, offset, wantOffset)
+		}
+	}
+
+	found, err := s.GetWorkoutByDate(ctx, want, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ShortDescription != "UTC" {
+		t.Errorf("GetWorkoutByDate returned %q, want the first workout that day", found.ShortDescription)
+	}
+
+	if _, err := s.GetWorkoutByDate(ctx, day(2026, 3, 2), userID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByDate on an empty day returned %v, want ErrNotFound", err)
+	}
+}
+
+func testGetLatestWorkouts(t *testing.T, s flexcreek.Store) {
+	userID := createUser(t, s, "alice")
+	createWorkout(t, s, userID, "first", day(2026, 3, 1))
+	createWorkout(t, s, userID, "third", day(2026, 3, 3))
+	createWorkout(t, s, userID, "second", day(2026, 3, 2))
+
+	workouts, err := s.GetLatestWorkouts(context.Background(), 2, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := descriptions(workouts); !equal(got, []string{"third", "second"}) {
+		t.Errorf("got %v, want [third second]", got)
+	}
+}
+
+func testGetWorkoutsBetween(t *testing.T, s flexcreek.Store) {
+	userID := createUser(t, s, "alice")
+	createWorkout(t, s, userID, "before", day(2026, 2, 28))
+	createWorkout(t, s, userID, "end", day(2026, 3, 7))
+	createWorkout(t, s, userID, "start", day(2026, 3, 1))
+	createWorkout(t, s, userID, "after", day(2026, 3, 8))
+
+	workouts, err := s.GetWorkoutsBetween(context.Background(), day(2026, 3, 1), day(2026, 3, 7), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := descriptions(workouts); !equal(got, []string{"start", "end"}) {
+		t.Errorf("got %v, want [start end]", got)
+	}
+}
+
+// a query's % and _ are plain characters, not LIKE wildcards
+func testListWorkoutsQueryIsLiteral(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	createWorkout(t, s, userID, "1000m repeats", day(2026, 3, 1))
+	createWorkout(t, s, userID, "100% effort", day(2026, 3, 2))
+	createWorkout(t, s, userID, "KB swings", day(2026, 3, 3))
+	createWorkout(t, s, userID, "KB_swings", day(2026, 3, 4))
+	createWorkout(t, s, userID, `run\walk`, day(2026, 3, 5))
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"100%", []string{"100% effort"}},
+		{"%", []string{"100% effort"}},
+		{"_", []string{"KB_swings"}},
+		{"b_s", []string{"KB_swings"}},
+		{`\`, []string{`run\walk`}},
+		{`\%`, nil},
+	}
+
+	for _, c := range cases {
+		workouts, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{Query: c.query}, userID)
+		if err != nil {
+			t.Fatalf("%s: %v", c.query, err)
+		}
+
+		if got := descriptions(workouts); !equal(got, c.want) {
+			t.Errorf("query %q: got %v, want %v", c.query, got, c.want)
+		}
+	}
+}
+
+func testListWorkouts(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	createWorkout(t, s, userID, "Easy run", day(2026, 3, 1))
+	createWorkout(t, s, userID, "KB swings", day(2026, 3, 2))
+	createWorkout(t, s, userID, "Long run", day(2026, 3, 3))
+	createWorkout(t, s, userID, "Rest day walk", day(2026, 3, 4))
+
+	cases := []struct {
+		name string
+		f    flexcreek.WorkoutFilter
+		want []string
+	}{
+		{"everything", flexcreek.WorkoutFilter{}, []string{"Rest day walk", "Long run", "KB swings", "Easy run"}},
+		{"from", flexcreek.WorkoutFilter{From: day(2026, 3, 3)}, []string{"Rest day walk", "Long run"}},
+		{"to", flexcreek.WorkoutFilter{To: day(2026, 3, 2)}, []string{"KB swings", "Easy run"}},
+		{"query ignores case", flexcreek.WorkoutFilter{Query: "RUN"}, []string{"Long run", "Easy run"}},
+		{"first page", flexcreek.WorkoutFilter{Limit: 3}, []string{"Rest day walk", "Long run", "KB swings"}},
+		{"second page", flexcreek.WorkoutFilter{Limit: 3, Offset: 3}, []string{"Easy run"}},
+		{"combined", flexcreek.WorkoutFilter{From: day(2026, 3, 2), Query: "run", Limit: 1}, []string{"Long run"}},
+	}
+
+	for _, c := range cases {
+		workouts, err := s.ListWorkouts(ctx, c.f, userID)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		if got := descriptions(workouts); !equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func testListWorkoutsLimits(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	for i := 1; i <= 5; i++ {
+		createWorkout(t, s, userID, fmt.Sprintf("day %d", i), day(2026, 3, i))
+	}
+
+	latest, err := s.GetLatestWorkouts(ctx, 10, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest) != 5 {
+		t.Errorf("GetLatestWorkouts(10) returned %d of 5 workouts", len(latest))
+	}
+
+	latest, err = s.GetLatestWorkouts(ctx, 0, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest) != 0 {
+		t.Errorf("GetLatestWorkouts(0) returned %d workouts", len(latest))
+	}
+
+	cases := []struct {
+		name string
+		f    flexcreek.WorkoutFilter
+		want int
+	}{
+		{"no limit", flexcreek.WorkoutFilter{}, 5},
+		{"negative limit", flexcreek.WorkoutFilter{Limit: -1}, 5},
+		{"limit above the count", flexcreek.WorkoutFilter{Limit: 50}, 5},
+		{"offset without a limit", flexcreek.WorkoutFilter{Offset: 2}, 3},
+		{"partial last page", flexcreek.WorkoutFilter{Limit: 2, Offset: 4}, 1},
+		{"offset past the end", flexcreek.WorkoutFilter{Limit: 2, Offset: 5}, 0},
+	}
+
+	for _, c := range cases {
+		workouts, err := s.ListWorkouts(ctx, c.f, userID)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(workouts) != c.want {
+			t.Errorf("%s: got %d workouts, want %d", c.name, len(workouts), c.want)
+		}
+	}
+}
+
+// workouts on the same day are ordered by when they were added, so paging never skips or repeats one
+func testSameDayOrdering(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	createWorkout(t, s, userID, "morning", day(2026, 3, 1))
+	createWorkout(t, s, userID, "evening", day(2026, 3, 1))
+	createWorkout(t, s, userID, "next day", day(2026, 3, 2))
+
+	newestFirst := []string{"next day", "evening", "morning"}
+
+	latest, err := s.GetLatestWorkouts(ctx, 10, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descriptions(latest); !equal(got, newestFirst) {
+		t.Errorf("GetLatestWorkouts got %v, want %v", got, newestFirst)
+	}
+
+	var paged []*flexcreek.Workout
+	for offset := 0; offset < 3; offset++ {
+		page, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{Limit: 1, Offset: offset}, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paged = append(paged, page...)
+	}
+	if got := descriptions(paged); !equal(got, newestFirst) {
+		t.Errorf("paging through ListWorkouts got %v, want %v", got, newestFirst)
+	}
+
+	between, err := s.GetWorkoutsBetween(ctx, day(2026, 3, 1), day(2026, 3, 2), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := descriptions(between), []string{"morning", "evening", "next day"}; !equal(got, want) {
+		t.Errorf("GetWorkoutsBetween got %v, want %v", got, want)
+	}
+}
+
+// a user with no workouts gets empty results rather than errors, except from the single workout lookups
+func testNoWorkouts(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+
+	latest, err := s.GetLatestWorkouts(ctx, 10, userID)
+	if err != nil || len(latest) != 0 {
+		t.Errorf("GetLatestWorkouts returned %d workouts and %v", len(latest), err)
+	}
+
+	between, err := s.GetWorkoutsBetween(ctx, day(2026, 1, 1), day(2026, 12, 31), userID)
+	if err != nil || len(between) != 0 {
+		t.Errorf("GetWorkoutsBetween returned %d workouts and %v", len(between), err)
+	}
+
+	listed, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{Query: "run"}, userID)
+	if err != nil || len(listed) != 0 {
+		t.Errorf("ListWorkouts returned %d workouts and %v", len(listed), err)
+	}
+
+	if _, err := s.GetWorkoutByID(ctx, 999, userID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByID returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetWorkoutByUUID(ctx, "00000000-0000-0000-0000-000000000000", userID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByUUID returned %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdateWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	w := createWorkout(t, s, userID, "KB ABC", day(2026, 3, 1))
+
+	w.ShortDescription = "KB ABC, heavier"
+	w.LongDescription = "24kg"
+	w.WorkoutDate = day(2026, 3, 2)
+	w.StartTime = time.Date(2026, 3, 2, 6, 15, 0, 0, time.FixedZone("", -5*3600))
+	w.DurationMinutes = 30
+	w.RPE = 9
+	w.SessionLoad = 270
+	if err := s.UpdateWorkout(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetWorkoutByID(ctx, w.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ShortDescription != w.ShortDescription || got.LongDescription != w.LongDescription ||
+		!got.WorkoutDate.Equal(w.WorkoutDate) || !got.StartTime.Equal(w.StartTime) ||
+		got.DurationMinutes != w.DurationMinutes || got.RPE != w.RPE || got.SessionLoad != w.SessionLoad {
+		t.Errorf("got %+v after the update, want %+v", got, w)
+	}
+	if got.UUID != w.UUID || !got.CreatedAt.Equal(w.CreatedAt) {
+		t.Error("the update changed the uuid or created_at")
+	}
+
+	w.ID = 999
+	if err := s.UpdateWorkout(ctx, w); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating a missing workout returned %v, want ErrNotFound", err)
+	}
+}
+
+func testUpdateMissingWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+
+	missing := &flexcreek.Workout{ID: 999, UserID: userID, ShortDescription: "KB ABC", WorkoutDate: day(2026, 3, 1)}
+	if err := s.UpdateWorkout(ctx, missing); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating a workout that never existed returned %v, want ErrNotFound", err)
+	}
+
+	//nor can a deleted one be brought back by editing it
+	w := createWorkout(t, s, userID, "Run", day(2026, 3, 1))
+	if err := s.DeleteWorkout(ctx, w.ID, userID); err != nil {
+		t.Fatal(err)
+	}
+	w.ShortDescription = "Run, edited"
+	if err := s.UpdateWorkout(ctx, w); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("updating a deleted workout returned %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetWorkoutByID(ctx, w.ID, userID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("the deleted workout came back after an update: %v", err)
+	}
+}
+
+func testDeleteWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	w := createWorkout(t, s, userID, "KB ABC", day(2026, 3, 1))
+	createWorkout(t, s, userID, "Run", day(2026, 3, 2))
+
+	if err := s.DeleteWorkout(ctx, w.ID, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetWorkoutByID(ctx, w.ID, userID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("GetWorkoutByID on a deleted workout returned %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteWorkout(ctx, w.ID, userID); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("deleting it again returned %v, want ErrNotFound", err)
+	}
+
+	workouts, err := s.ListWorkouts(ctx, flexcreek.WorkoutFilter{}, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := descriptions(workouts); !equal(got, []string{"Run"}) {
+		t.Errorf("got %v after the delete, want [Run]", got)
+	}
+}
+
+func testCloneWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+	w := createWorkout(t, s, userID, "KB ABC", day(2026, 3, 3))
+	w.LongDescription = "5 rounds"
+	w.StartTime = time.Date(2026, 3, 3, 6, 15, 0, 0, time.FixedZone("", -5*3600))
+	if err := s.UpdateWorkout(ctx, w); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := s.CloneWorkout(ctx, w.ID, userID, day(2026, 3, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone, err := s.GetWorkoutByID(ctx, id, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clone.ID == w.ID || clone.UUID == "" || clone.UUID == w.UUID {
+		t.Errorf("the clone has id %d and uuid %q, the same as the original or blank", clone.ID, clone.UUID)
+	}
+	if clone.ShortDescription != w.ShortDescription || clone.LongDescription != w.LongDescription ||
+		clone.DurationMinutes != w.DurationMinutes || clone.RPE != w.RPE || clone.SessionLoad != w.SessionLoad {
+		t.Errorf("got clone %+v, want a copy of %+v", clone, w)
+	}
+	if !clone.WorkoutDate.Equal(day(2026, 3, 10)) {
+		t.Errorf("the clone is dated %s, want 2026-03-10", clone.WorkoutDate)
+	}
+	if want := time.Date(2026, 3, 10, 6, 15, 0, 0, time.FixedZone("", -5*3600)); !clone.StartTime.Equal(want) {
+		t.Errorf("the clone starts at %s, want %s", clone.StartTime, want)
+	}
+
+	//the original is left as it was
+	got, err := s.GetWorkoutByID(ctx, w.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.WorkoutDate.Equal(day(2026, 3, 3)) || got.UUID != w.UUID {
+		t.Errorf("cloning changed the original: %+v", got)
+	}
+}
+
+func testCloneMissingWorkout(t *testing.T, s flexcreek.Store) {
+	ctx := context.Background()
+	userID := createUser(t, s, "alice")
+
+	if _, err := s.CloneWorkout(ctx, 999, userID, day(2026, 3, 10)); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("cloning a workout that never existed returned %v, want ErrNotFound", err)
+	}
+
+	w := createWorkout(t, s, userID, "Run", day(2026, 3, 1))
+	if err := s.DeleteWorkout(ctx, w.ID, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CloneWorkout(ctx, w.ID, userID, day(2026, 3, 10)); !errors.Is(err, flexcreek.ErrNotFound) {
+		t.Errorf("cloning a deleted workout returned %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,34 @@
+package flexcreek
+
+import (
+	"time"
+)
+
+// APIToken is a per-user credential for the API.
+// only a hash of the token is ever stored, the plaintext is shown once when it's created
+type APIToken struct {
+	ID         int       `db:"id"`
+	UserID     int       `db:"user_id"`
+	Name       string    `db:"name"`
+	TokenHash  string    `db:"token_hash"`
+	Scopes     []string  `db:"scopes"`
+	ExpiresAt  time.Time `db:"expires_at"` // zero means the token never expires
+	LastUsedAt time.Time `db:"last_used_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// Expired reports whether the token has passed its expiry at time now
+func (t *APIToken) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// HasScope reports whether the token was granted scope
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
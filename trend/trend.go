@@ -0,0 +1,44 @@
+// Package trend smooths noisy day-to-day series such as bodyweight into trend lines
+package trend
+
+import (
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// default window, in days, for moving averages
+const DefaultWindow = 7
+
+// Point is a single value on a trend line
+type Point struct {
+	Date    time.Time
+	Value   float64
+	Average float64 // moving average over the trailing window, inclusive of Date
+}
+
+// MovingAverage returns one point per measurement with the average of all measurements
+// taken in the trailing window of days. measurements must be sorted oldest first and share a unit
+func MovingAverage(ms []*flexcreek.Measurement, window int) []Point {
+	points := make([]Point, len(ms))
+
+	//two pointers -- lo trails behind i marking the oldest measurement still in the window
+	lo := 0
+	var sum float64
+	for i, m := range ms {
+		sum += m.Value
+		cutoff := m.MeasuredOn.AddDate(0, 0, -window)
+		for !ms[lo].MeasuredOn.After(cutoff) {
+			sum -= ms[lo].Value
+			lo++
+		}
+
+		points[i] = Point{
+			Date:    m.MeasuredOn,
+			Value:   m.Value,
+			Average: sum / float64(i-lo+1),
+		}
+	}
+
+	return points
+}
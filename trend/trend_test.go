@@ -0,0 +1,80 @@
+package trend
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// bodyweights on the given days of March 2026
+func weights(days []int, values []float64) []*flexcreek.Measurement {
+	ms := make([]*flexcreek.Measurement, len(days))
+	for i, d := range days {
+		ms[i] = &flexcreek.Measurement{Metric: "bodyweight", Value: values[i], Unit: "kg", MeasuredOn: time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)}
+	}
+
+	return ms
+}
+
+func TestMovingAverage(t *testing.T) {
+	tests := []struct {
+		name   string
+		days   []int
+		values []float64
+		want   []float64
+	}{
+		{"nothing", nil, nil, nil},
+		{"a single point", []int{1}, []float64{80}, []float64{80}},
+		{"fewer than 7 points average what there is", []int{1, 2, 3}, []float64{80, 81, 85}, []float64{80, 80.5, 82}},
+		{
+			"a full week, then the first day drops out",
+			[]int{1, 2, 3, 4, 5, 6, 7, 8},
+			[]float64{70, 80, 80, 80, 80, 80, 80, 94},
+			[]float64{70, 75, 230.0 / 3, 77.5, 78, 470.0 / 6, 550.0 / 7, 80 + 14.0/7},
+		},
+		{"a point 7 days back is outside the window", []int{1, 8}, []float64{80, 90}, []float64{80, 90}},
+		{"a point 6 days back is inside it", []int{1, 7}, []float64{80, 90}, []float64{80, 85}},
+		{"a gap longer than the window starts over", []int{1, 2, 20}, []float64{80, 82, 76}, []float64{80, 81, 76}},
+		{"two on the same day both count", []int{1, 1, 2}, []float64{80, 82, 84}, []float64{80, 81, 82}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := weights(tt.days, tt.values)
+			points := MovingAverage(ms, DefaultWindow)
+
+			if len(points) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(points), len(tt.want))
+			}
+			for i, p := range points {
+				if !p.Date.Equal(ms[i].MeasuredOn) || p.Value != ms[i].Value {
+					t.Errorf("point %d is %v on %v, want the measurement's %v on %v", i, p.Value, p.Date, ms[i].Value, ms[i].MeasuredOn)
+				}
+				if math.Abs(p.Average-tt.want[i]) > 1e-9 {
+					t.Errorf("point %d averages %v, want %v", i, p.Average, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMovingAverageWindow(t *testing.T) {
+	ms := weights([]int{1, 2, 3, 4}, []float64{80, 82, 84, 86})
+
+	//a 1 day window is just each day's own value
+	for i, p := range MovingAverage(ms, 1) {
+		if p.Average != ms[i].Value {
+			t.Errorf("with a 1 day window point %d averages %v, want %v", i, p.Average, ms[i].Value)
+		}
+	}
+
+	//and a 2 day window is each day with the one before
+	want := []float64{80, 81, 83, 85}
+	for i, p := range MovingAverage(ms, 2) {
+		if p.Average != want[i] {
+			t.Errorf("with a 2 day window point %d averages %v, want %v", i, p.Average, want[i])
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the TUI's look and keys, read from a YAML file:
+//
+//	theme: dark            # auto (the default), dark or light
+//	colors:                # any of the preset's colors
+//	  accent: "#00AFAF"
+//	keys:                  # any binding, by name, with the keys that trigger it
+//	  new: [a]
+//	  back: [esc, backspace]
+type Config struct {
+	Theme Theme
+	Keys  KeyMap
+}
+
+// the file as written
+type configFile struct {
+	Theme  string              `yaml:"theme"`
+	Colors Palette             `yaml:"colors"`
+	Keys   map[string][]string `yaml:"keys"`
+}
+
+// DefaultConfig is what runs without a config file
+func DefaultConfig() Config {
+	return Config{Theme: DefaultTheme(), Keys: DefaultKeyMap()}
+}
+
+// ConfigPath is where the config file lives: $FLEXCREEK_CONFIG, or flexcreek/config.yaml under the
+// user's config directory (~/.config on linux)
+func ConfigPath() string {
+	if p := os.Getenv("FLEXCREEK_CONFIG"); p != "" {
+		return p
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "flexcreek", "config.yaml")
+}
+
+// LoadConfig reads the config file at path. a missing file just means the defaults.
+// setting NO_COLOR (to anything) turns colors off whatever the file says, see https://no-color.org
+func LoadConfig(path string) (Config, error) {
+	var f configFile
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return Config{}, err
+		}
+
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		//an empty file, or one that's all comments, decodes to EOF
+		if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	theme, err := NewTheme(f.Theme, f.Colors, os.Getenv("NO_COLOR") != "")
+	if err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := DefaultKeyMap()
+	if err := keys.rebind(f.Keys); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return Config{Theme: theme, Keys: keys}, nil
+}
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func writeConfig(t *testing.T, text string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() on a missing file: %v", err)
+	}
+
+	if got := cfg.Keys.New.Keys(); !slices.Equal(got, []string{"n"}) {
+		t.Errorf("new is bound to %v, want the default [n]", got)
+	}
+}
+
+func TestLoadConfigCommentsOnly(t *testing.T) {
+	path := writeConfig(t, "# nothing set yet\n")
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("LoadConfig() on a file of comments: %v", err)
+	}
+}
+
+func TestLoadConfigRebindsKeys(t *testing.T) {
+	path := writeConfig(t, "theme: light\ncolors:\n  accent: \"#00AFAF\"\nkeys:\n  new: [a]\n  back: [esc, backspace]\n")
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.Keys.New.Keys(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("new is bound to %v, want [a]", got)
+	}
+	if got := cfg.Keys.Back.Help(); got.Key != "esc/backspace" || got.Desc != "back" {
+		t.Errorf("back's help is %+v, want esc/backspace back", got)
+	}
+
+	s := newFakeStore()
+	s.addUser("alice")
+	m := NewUserModel(s, cfg)
+	staticCursor(&m.input.Cursor)
+	staticCursor(&m.list.FilterInput.Cursor)
+	h := newHarness(t, m)
+
+	if view := h.model.View(); !strings.Contains(view, "a new user") {
+		t.Errorf("the help doesn't show the rebound key:\n%s", view)
+	}
+
+	//n is just a key now
+	h.keys("n")
+	if strings.Contains(h.model.View(), "Create New User") {
+		t.Error("n still opens the create form")
+	}
+
+	h.keys("a")
+	if !strings.Contains(h.model.View(), "Create New User") {
+		t.Errorf("a didn't open the create form:\n%s", h.model.View())
+	}
+
+	h.keys("backspace")
+	if strings.Contains(h.model.View(), "Create New User") {
+		t.Error("backspace didn't go back from the create form")
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"unknown theme", "theme: solarized\n", `unknown theme "solarized"`},
+		{"unknown binding", "keys:\n  jump: [g]\n", `unknown key binding "jump"`},
+		{"no keys", "keys:\n  new: []\n", `"new" needs at least one key`},
+		{"unknown field", "colour: red\n", "field colour not found"},
+		{"unknown color", "colors:\n  background: \"#000000\"\n", "field background not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.text)
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadConfig() error = %v, want one containing %q", err, tt.want)
+			}
+			if err != nil && !strings.Contains(err.Error(), path) {
+				t.Errorf("LoadConfig() error = %v doesn't name the file", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	cfg, err := LoadConfig(writeConfig(t, "theme: dark\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fg := cfg.Theme.Selected.GetForeground(); fg != (lipgloss.NoColor{}) {
+		t.Errorf("selected foreground = %#v with NO_COLOR set", fg)
+	}
+}
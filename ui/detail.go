@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ekholme/flexcreek"
+)
+
+// workoutDetail shows a single workout: its metadata in a header, and its long description rendered
+// as markdown in a viewport underneath, so long notes wrap to the terminal and scroll
+type workoutDetail struct {
+	viewport   viewport.Model
+	prefs      flexcreek.Preferences
+	workout    *flexcreek.Workout
+	lastEdited time.Time // when the workout was last changed, zero if it never has been
+	width      int
+	height     int
+	renderer   *glamour.TermRenderer // built for the current width, nil until it's needed
+	keys       KeyMap
+	theme      Theme
+}
+
+func newWorkoutDetail(prefs flexcreek.Preferences, cfg Config) workoutDetail {
+	vp := viewport.New(0, 0)
+	vp.KeyMap.Up = cfg.Keys.Up
+	vp.KeyMap.Down = cfg.Keys.Down
+	//left and right step between workouts, and the notes are wrapped so there's nothing to pan to
+	vp.KeyMap.Left.SetEnabled(false)
+	vp.KeyMap.Right.SetEnabled(false)
+
+	return workoutDetail{viewport: vp, prefs: prefs, keys: cfg.Keys, theme: cfg.Theme}
+}
+
+func (d *workoutDetail) setSize(width, height int) {
+	if width != d.width {
+		d.renderer = nil
+	}
+	d.width, d.height = width, height
+	d.layout()
+}
+
+func (d *workoutDetail) setWorkout(w *flexcreek.Workout) {
+	d.workout = w
+	d.lastEdited = time.Time{}
+	d.layout()
+	d.viewport.GotoTop()
+}
+
+func (d *workoutDetail) setLastEdited(t time.Time) {
+	d.lastEdited = t
+	d.layout()
+}
+
+// sizes the viewport to whatever the header and footer leave, and fills it
+func (d *workoutDetail) layout() {
+	if d.workout == nil {
+		return
+	}
+
+	d.viewport.Width = d.width
+	d.viewport.Height = max(d.height-lipgloss.Height(d.header())-lipgloss.Height(d.footer()), 1)
+	d.viewport.SetContent(d.body())
+}
+
+func (d workoutDetail) update(msg tea.Msg) (workoutDetail, tea.Cmd) {
+	var cmd tea.Cmd
+	d.viewport, cmd = d.viewport.Update(msg)
+	return d, cmd
+}
+
+func (d workoutDetail) view() string {
+	if d.workout == nil {
+		return "Error: No workout selected."
+	}
+
+	return d.header() + "\n" + d.viewport.View() + "\n" + d.footer()
+}
+
+// when the workout was and what went into its load, e.g. Tue 2026-03-03 at 06:30 · 90 min · RPE 5 · load 450
+func workoutFacts(w *flexcreek.Workout, prefs flexcreek.Preferences) string {
+	when := w.WorkoutDate.Format("Mon") + " " + prefs.FormatDate(w.WorkoutDate)
+	if !w.StartTime.IsZero() {
+		when += " at " + w.StartTime.In(prefs.Location()).Format("15:04")
+	}
+	facts := []string{when}
+	if w.DurationMinutes > 0 {
+		facts = append(facts, strconv.Itoa(w.DurationMinutes)+" min")
+	}
+	if w.RPE > 0 {
+		facts = append(facts, "RPE "+strconv.Itoa(w.RPE))
+	}
+	if load := w.Load(); load > 0 {
+		facts = append(facts, fmt.Sprintf("load %.0f", load))
+	}
+
+	return strings.Join(facts, " · ")
+}
+
+func (d workoutDetail) header() string {
+	w := d.workout
+
+	var history []string
+	if !w.CreatedAt.IsZero() {
+		history = append(history, "logged "+d.timestamp(w.CreatedAt))
+	}
+	if !d.lastEdited.IsZero() {
+		history = append(history, "edited "+d.timestamp(d.lastEdited))
+	}
+
+	title := d.theme.Heading.Padding(0, 2)
+	meta := d.theme.Muted.Padding(0, 2)
+	lines := []string{"", title.Render(w.ShortDescription), meta.Render(workoutFacts(w, d.prefs))}
+	if len(history) > 0 {
+		lines = append(lines, meta.Render(strings.Join(history, " · ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (d workoutDetail) timestamp(t time.Time) string {
+	t = t.In(d.prefs.Location())
+	return d.prefs.FormatDate(t) + " " + t.Format("15:04")
+}
+
+func (d workoutDetail) footer() string {
+	scrolled := ""
+	if !d.viewport.AtTop() || !d.viewport.AtBottom() {
+		scrolled = d.theme.Muted.Render(fmt.Sprintf("%3.f%% • ", d.viewport.ScrollPercent()*100))
+	}
+
+	help := d.theme.helpBar(relabel(d.keys.Down, "scroll"), d.keys.NextWorkout, d.keys.PrevWorkout, d.keys.Duplicate, d.keys.History, d.keys.Back)
+	return "  " + scrolled + help
+}
+
+// the long description as markdown, wrapped to the terminal. anything glamour can't render is shown as it is
+func (d *workoutDetail) body() string {
+	if strings.TrimSpace(d.workout.LongDescription) == "" {
+		return "\n" + d.theme.Muted.Padding(0, 2).Render("No notes.")
+	}
+
+	if d.renderer == nil {
+		//the style's margins take two columns either side
+		wrap := d.width - 4
+		if wrap <= 0 {
+			wrap = 76
+		}
+
+		//notes written before they were rendered as markdown rely on their line breaks, so keep them
+		r, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(d.theme.markdownStyle()),
+			glamour.WithWordWrap(wrap),
+			glamour.WithPreservedNewLines(),
+		)
+		if err != nil {
+			return d.workout.LongDescription
+		}
+		d.renderer = r
+	}
+
+	out, err := d.renderer.Render(d.workout.LongDescription)
+	if err != nil {
+		return d.workout.LongDescription
+	}
+
+	return "\n" + strings.Trim(out, "\n")
+}
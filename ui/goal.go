@@ -0,0 +1,332 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/goals"
+)
+
+const (
+	stateGoalList sessionState = iota
+	stateCreateGoal
+)
+
+// widest a goal's progress bar gets
+const maxProgressBarWidth = 40
+
+// defining the interface that the goal model requires
+type GoalStore interface {
+	goals.Store
+	GetGoals(ctx context.Context, userID int) ([]*flexcreek.Goal, error)
+	CreateGoal(ctx context.Context, g *flexcreek.Goal) (int, error)
+	DeleteGoal(ctx context.Context, id int, userID int) error
+}
+
+// lists a user's goals with a progress bar each, and adds and deletes them
+type GoalModel struct {
+	store          GoalStore
+	list           list.Model
+	input          textinput.Model
+	state          sessionState
+	loading        bool
+	err            error
+	selectedUserID int
+	prefs          flexcreek.Preferences
+	today          time.Time // the day progress is measured on
+	keys           KeyMap
+	theme          Theme
+}
+
+func NewGoalModel(s GoalStore, userID int, prefs flexcreek.Preferences, cfg Config) GoalModel {
+	l := newList("Goals", goalDelegate{cfg.Theme}, cfg.Theme, cfg.Keys,
+		relabel(cfg.Keys.New, "new goal"),
+		cfg.Keys.Delete,
+	)
+	l.SetStatusBarItemName("goal", "goals")
+
+	ti := textinput.New()
+	ti.Placeholder = "4 sessions a week, run 500 km this year, deadlift 200 kg by june"
+
+	return GoalModel{
+		store:          s,
+		list:           l,
+		input:          ti,
+		state:          stateGoalList,
+		loading:        true,
+		selectedUserID: userID,
+		prefs:          prefs,
+		today:          prefs.Today(),
+		keys:           cfg.Keys,
+		theme:          cfg.Theme,
+	}
+}
+
+func fetchGoalsCmd(s GoalStore, userID int, today time.Time, prefs flexcreek.Preferences) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		gs, err := s.GetGoals(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		progress, err := goals.NewService(s).Progress(ctx, gs, today, prefs)
+		if err != nil {
+			return err
+		}
+
+		return goalsLoadedMsg{progress}
+	}
+}
+
+func createGoalCmd(s GoalStore, g *flexcreek.Goal) tea.Cmd {
+	return func() tea.Msg {
+		_, err := s.CreateGoal(context.Background(), g)
+		if err != nil {
+			return err
+		}
+
+		return goalCreatedMsg{}
+	}
+}
+
+func deleteGoalCmd(s GoalStore, id int, userID int) tea.Cmd {
+	return func() tea.Msg {
+		if err := s.DeleteGoal(context.Background(), id, userID); err != nil {
+			return err
+		}
+
+		return goalDeletedMsg{}
+	}
+}
+
+type goalsLoadedMsg struct {
+	progress []goals.Progress
+}
+
+type goalCreatedMsg struct{}
+
+type goalDeletedMsg struct{}
+
+// a goal in the list, with its text worked out for the user up front
+type goalItem struct {
+	goals.Progress
+	title   string
+	summary string // e.g. 3 / 4 sessions · this week
+}
+
+func (i goalItem) FilterValue() string { return i.title }
+
+func (m GoalModel) newGoalItem(p goals.Progress) goalItem {
+	due := "this " + p.Goal.Period
+	switch days := p.DaysLeft(m.today); {
+	case p.Goal.Period != "":
+	case days == 0:
+		due = "ended " + m.prefs.FormatDate(p.To)
+	case days == 1:
+		due = "last day"
+	default:
+		due = fmt.Sprintf("%d days left", days)
+	}
+
+	return goalItem{
+		Progress: p,
+		title:    p.Goal.Describe(m.prefs),
+		summary:  p.Summary(m.prefs) + " · " + due,
+	}
+}
+
+// draws each goal as its title and status over a progress bar
+type goalDelegate struct {
+	theme Theme
+}
+
+func (d goalDelegate) Height() int                             { return 2 }
+func (d goalDelegate) Spacing() int                            { return 1 }
+func (d goalDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d goalDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	i, ok := item.(goalItem)
+	if !ok {
+		return
+	}
+
+	//the bar and the status share a color: the accent while it's going well
+	colors := d.theme.colors()
+	fill, status := colors.Accent, d.theme.Selected
+	switch i.Status {
+	case goals.Behind:
+		fill, status = colors.Warning, d.theme.Warning
+	case goals.Missed:
+		fill, status = colors.Error, d.theme.Error
+	}
+
+	prefix, title := "  ", d.theme.Text
+	if index == m.Index() {
+		prefix, title = d.theme.Selected.Render("│")+" ", d.theme.Selected
+	}
+
+	bar := d.theme.progressBar(min(m.Width()/2, maxProgressBarWidth), fill)
+
+	fmt.Fprintf(w, "%s%s  %s\n%s%s  %s",
+		prefix, title.Render(i.title), status.Render(string(i.Status)),
+		prefix, bar.ViewAs(i.Fraction()), d.theme.Muted.Render(i.summary))
+}
+
+// bubbletea model requirements
+func (m GoalModel) Init() tea.Cmd {
+	return fetchGoalsCmd(m.store, m.selectedUserID, m.today, m.prefs)
+}
+
+func (m GoalModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case error:
+		m.loading = false
+		m.err = msg
+		return m, nil
+
+	case goalsLoadedMsg:
+		m.loading = false
+		items := make([]list.Item, len(msg.progress))
+		for i, p := range msg.progress {
+			items[i] = m.newGoalItem(p)
+		}
+		return m, m.list.SetItems(items)
+
+	case goalCreatedMsg, goalDeletedMsg:
+		m.state = stateGoalList
+		m.loading = true
+		m.input.Reset()
+		return m, fetchGoalsCmd(m.store, m.selectedUserID, m.today, m.prefs)
+	}
+
+	if m.state == stateCreateGoal {
+		return m.updateGoalForm(msg)
+	}
+	return m.updateGoalList(msg)
+}
+
+func (m GoalModel) View() string {
+	if m.err != nil {
+		return m.theme.Error.Render("Error: " + m.err.Error())
+	}
+
+	if m.state == stateCreateGoal {
+		return "\n New Goal \n\n " + m.input.View() + "\n\n" +
+			m.goalPreview() + "\n\n" +
+			m.theme.helpBar(m.keys.Submit, m.keys.Back)
+	}
+
+	if m.loading {
+		return " Loading goals..."
+	}
+
+	return "\n" + m.list.View()
+}
+
+// the goal enter would add, or why the line isn't one yet
+func (m GoalModel) goalPreview() string {
+	muted := m.theme.Muted.Padding(0, 3)
+	if strings.TrimSpace(m.input.Value()) == "" {
+		return muted.Render("a target and a metric, then how often it starts over (a week, a month, a year)\n" +
+			"or when it's due (this year, by june, by " + m.prefs.FormatDate(m.today.AddDate(0, 3, 0)) + ").\n" +
+			"sessions, minutes and load count workouts, add matching <words> to count only some.\n" +
+			"anything else counts measurements logged under that name (m), not workouts:\n" +
+			"distances add up, the rest go by the best one")
+	}
+
+	g, err := flexcreek.ParseGoal(m.input.Value(), m.selectedUserID, m.prefs)
+	if err != nil {
+		return "   " + m.theme.Error.Render(err.Error())
+	}
+
+	start := ""
+	if g.Period == "" {
+		start = ", counting from " + m.prefs.FormatDate(g.StartDate)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.theme.Heading.Padding(0, 3).Render(g.Describe(m.prefs)),
+		muted.Render(goalKind(g)+start),
+	)
+}
+
+// how a goal's progress is counted, in words
+func goalKind(g *flexcreek.Goal) string {
+	switch {
+	case g.CountsWorkouts() && g.Match != "":
+		return g.Metric + " of workouts with " + g.Match + " in the title"
+	case g.CountsWorkouts():
+		return g.Metric + " of every workout"
+	case g.Totals():
+		return "the total of your measurements named " + g.Metric + ", not workouts"
+	default:
+		return "your best measurement named " + g.Metric
+	}
+}
+
+// update helpers
+func (m GoalModel) updateGoalList(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.list.SetSize(size.Width, size.Height)
+		m.input.Width = max(size.Width-4, 0)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.list.FilterState() != list.Filtering {
+		switch {
+		case key.Matches(msg, m.keys.New):
+			m.state = stateCreateGoal
+			return m, m.input.Focus()
+
+		case key.Matches(msg, m.keys.Delete):
+			if i, ok := m.list.SelectedItem().(goalItem); ok {
+				return m, deleteGoalCmd(m.store, i.Goal.ID, m.selectedUserID)
+			}
+
+		case key.Matches(msg, m.keys.Back):
+			//nothing to clear, so esc heads back to the workouts
+			if m.list.FilterState() == list.Unfiltered {
+				return m, func() tea.Msg { return showWorkoutsMsg{} }
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m GoalModel) updateGoalForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.list.SetSize(size.Width, size.Height)
+		m.input.Width = max(size.Width-4, 0)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.state = stateGoalList
+			return m, nil
+
+		case key.Matches(msg, m.keys.Submit):
+			g, err := flexcreek.ParseGoal(m.input.Value(), m.selectedUserID, m.prefs)
+			if err != nil {
+				//the preview is already showing why
+				return m, nil
+			}
+			return m, createGoalCmd(m.store, g)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
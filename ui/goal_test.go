@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// the day goals are measured on in these tests, a Wednesday
+var goalToday = time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+
+func newGoalHarness(t *testing.T, s *fakeStore) *harness {
+	t.Helper()
+
+	m := NewGoalModel(s, testUserID, testPrefs, DefaultConfig())
+	m.today = goalToday
+	staticCursor(&m.input.Cursor)
+	staticCursor(&m.list.FilterInput.Cursor)
+
+	return newHarness(t, m)
+}
+
+// the workout fixture with two workouts this week, plus measurements and a goal on each way of counting
+func goalFixture() *fakeStore {
+	s := workoutFixture()
+	w := s.addWorkout(testUserID, "Easy run", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC))
+	w.DurationMinutes = 30
+	s.addWorkout(testUserID, "KB ABC", time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+
+	measure := func(metric string, value float64, unit string, day time.Time) {
+		s.measurements = append(s.measurements, &flexcreek.Measurement{
+			ID: s.id(), UserID: testUserID, Metric: metric, Value: value, Unit: unit, MeasuredOn: day,
+		})
+	}
+	measure("run", 12, "km", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	measure("run", 8, "mi", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC))
+	measure("deadlift", 180, "kg", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC))
+	measure("deadlift", 190, "kg", time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC))
+
+	for _, g := range []flexcreek.Goal{
+		{Metric: flexcreek.GoalSessions, Target: 3, Period: flexcreek.PeriodWeek},
+		{Metric: flexcreek.GoalMinutes, Match: "run", Target: 120, Period: flexcreek.PeriodWeek},
+		{Metric: flexcreek.GoalSessions, Match: "run", Target: 2, Period: flexcreek.PeriodMonth},
+		{Metric: "run", Target: 500, Unit: "km",
+			StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Deadline: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{Metric: "deadlift", Target: 200, Unit: "kg",
+			StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Deadline: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+	} {
+		g.UserID = testUserID
+		s.CreateGoal(context.Background(), &g)
+	}
+
+	return s
+}
+
+func TestGoalList(t *testing.T) {
+	h := newGoalHarness(t, goalFixture())
+	h.expectMsgs("ui.goalsLoadedMsg")
+	h.golden("goal_list")
+}
+
+func TestGoalListEmpty(t *testing.T) {
+	h := newGoalHarness(t, workoutFixture())
+	if view := h.model.View(); !strings.Contains(view, "No goals") {
+		t.Errorf("the empty list doesn't say so:\n%s", view)
+	}
+}
+
+func TestGoalCreate(t *testing.T) {
+	s := workoutFixture()
+	h := newGoalHarness(t, s)
+
+	h.keys("n")
+	h.golden("goal_new_empty")
+
+	h.keys("deadlift 200 kg by 2099-06-30")
+	h.golden("goal_new")
+
+	h.clearMsgs()
+	h.keys("enter")
+	h.expectMsgs("ui.goalCreatedMsg", "ui.goalsLoadedMsg")
+
+	if len(s.goals) != 1 {
+		t.Fatalf("the store has %d goals, want 1", len(s.goals))
+	}
+	g := s.goals[0]
+	if g.Metric != "deadlift" || g.Target != 200 || g.Unit != "kg" || g.UserID != testUserID ||
+		!g.Deadline.Equal(time.Date(2099, 6, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("created %+v, want a 200 kg deadlift by 2099-06-30", *g)
+	}
+	if got := h.model.(GoalModel).state; got != stateGoalList {
+		t.Errorf("after adding the state is %v, want the list", got)
+	}
+}
+
+func TestGoalCreateErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"4 sessions", "say how often or by when"},
+		{"sessions a week", "the goal has no target"},
+		{"4 kg sessions a week", "sessions are counted without a unit"},
+		{"200 kg deadlift matching heavy by june", "matching only works for sessions, minutes and load"},
+		{"200 kg deadlift by someday", "someday isn't a month or a date"},
+		{"0 sessions a week", "the target must be more than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s := workoutFixture()
+			h := newGoalHarness(t, s)
+
+			h.keys("n", tt.line)
+			if view := h.model.View(); !strings.Contains(view, tt.want) {
+				t.Errorf("the preview doesn't show %q:\n%s", tt.want, view)
+			}
+
+			h.clearMsgs()
+			h.keys("enter")
+			h.expectMsgs()
+			if len(s.goals) != 0 {
+				t.Errorf("the store has %d goals, want none", len(s.goals))
+			}
+		})
+	}
+}
+
+func TestGoalPreviewSaysWhatCounts(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"run 500 km this year", "the total of your measurements named run, not workouts"},
+		{"deadlift 200 kg by june", "your best measurement named deadlift"},
+		{"150 minutes matching run a week", "minutes of workouts with run in the title"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			h := newGoalHarness(t, workoutFixture())
+			h.keys("n", tt.line)
+			if view := h.model.View(); !strings.Contains(view, tt.want) {
+				t.Errorf("the preview doesn't say %q:\n%s", tt.want, view)
+			}
+		})
+	}
+}
+
+func TestGoalDelete(t *testing.T) {
+	s := goalFixture()
+	h := newGoalHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("down", "x")
+	h.expectMsgs("ui.goalDeletedMsg", "ui.goalsLoadedMsg")
+
+	if len(s.goals) != 4 || s.goals[1].Metric != flexcreek.GoalSessions {
+		t.Errorf("after deleting the minutes goal the store holds %d goals, starting %v", len(s.goals), s.goals)
+	}
+}
+
+func TestGoalBack(t *testing.T) {
+	h := newGoalHarness(t, goalFixture())
+
+	h.keys("n", "4 sessions", "esc")
+	h.golden("goal_list")
+
+	h.clearMsgs()
+	h.keys("esc")
+	h.expectMsgs("ui.showWorkoutsMsg")
+}
@@ -0,0 +1,444 @@
+package ui
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ekholme/flexcreek"
+	"github.com/muesli/termenv"
+)
+
+// go test ./ui -update rewrites the golden files from the current output
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestMain(m *testing.M) {
+	//golden files hold plain text, whatever terminal the tests run in
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+// the size every test's terminal starts at
+var testSize = tea.WindowSizeMsg{Width: 80, Height: 24}
+
+// preferences with a fixed zone, so dates render the same on every machine
+var testPrefs = flexcreek.Preferences{
+	WeightUnit:     "kg",
+	DistanceUnit:   "km",
+	FirstDayOfWeek: time.Monday,
+	DateFormat:     "2006-01-02",
+	TimeZone:       "UTC",
+}
+
+// fakeStore keeps users, workouts, revisions, measurements and goals in memory. setting fail makes the named method return that error
+type fakeStore struct {
+	users        []*flexcreek.User
+	workouts     []*flexcreek.Workout
+	revisions    map[int][]*flexcreek.WorkoutRevision
+	measurements []*flexcreek.Measurement
+	goals        []*flexcreek.Goal
+	fail         map[string]error
+	nextID       int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		revisions: map[int][]*flexcreek.WorkoutRevision{},
+		fail:      map[string]error{},
+		nextID:    1,
+	}
+}
+
+func (s *fakeStore) id() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func (s *fakeStore) addUser(username string) *flexcreek.User {
+	u := &flexcreek.User{ID: s.id(), Username: username, Preferences: testPrefs}
+	s.users = append(s.users, u)
+	return u
+}
+
+func (s *fakeStore) addWorkout(userID int, desc string, date time.Time) *flexcreek.Workout {
+	w := &flexcreek.Workout{ID: s.id(), UserID: userID, ShortDescription: desc, WorkoutDate: date}
+	s.workouts = append(s.workouts, w)
+	return w
+}
+
+func (s *fakeStore) GetAllUsers(ctx context.Context) ([]*flexcreek.User, error) {
+	if err := s.fail["GetAllUsers"]; err != nil {
+		return nil, err
+	}
+
+	var users []*flexcreek.User
+	for _, u := range s.users {
+		if u.DeletedAt.IsZero() {
+			users = append(users, u)
+		}
+	}
+
+	return users, nil
+}
+
+func (s *fakeStore) CreateUser(ctx context.Context, username string) (int, error) {
+	if err := s.fail["CreateUser"]; err != nil {
+		return 0, err
+	}
+
+	return s.addUser(username).ID, nil
+}
+
+func (s *fakeStore) DeleteUser(ctx context.Context, id int) error {
+	if err := s.fail["DeleteUser"]; err != nil {
+		return err
+	}
+
+	for _, u := range s.users {
+		if u.ID == id && u.DeletedAt.IsZero() {
+			u.DeletedAt = time.Now()
+			return nil
+		}
+	}
+
+	return flexcreek.ErrNotFound
+}
+
+// the user's live workouts, newest first like the real stores
+func (s *fakeStore) userWorkouts(userID int) []*flexcreek.Workout {
+	var out []*flexcreek.Workout
+	for i := len(s.workouts) - 1; i >= 0; i-- {
+		w := s.workouts[i]
+		if w.UserID == userID && w.DeletedAt.IsZero() {
+			out = append(out, w)
+		}
+	}
+
+	//stable, so same day workouts stay newest first
+	for i := 1; i < len(out); i++ {
+		for j := i; j > 0 && out[j].WorkoutDate.After(out[j-1].WorkoutDate); j-- {
+			out[j], out[j-1] = out[j-1], out[j]
+		}
+	}
+
+	return out
+}
+
+func (s *fakeStore) GetLatestWorkouts(ctx context.Context, n int, userID int) ([]*flexcreek.Workout, error) {
+	if err := s.fail["GetLatestWorkouts"]; err != nil {
+		return nil, err
+	}
+
+	workouts := s.userWorkouts(userID)
+	if len(workouts) > n {
+		workouts = workouts[:n]
+	}
+
+	return workouts, nil
+}
+
+func (s *fakeStore) GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error) {
+	if err := s.fail["GetWorkoutByID"]; err != nil {
+		return nil, err
+	}
+
+	for _, w := range s.userWorkouts(userID) {
+		if w.ID == id {
+			copied := *w
+			return &copied, nil
+		}
+	}
+
+	return nil, flexcreek.ErrNotFound
+}
+
+func (s *fakeStore) GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error) {
+	if err := s.fail["GetWorkoutsBetween"]; err != nil {
+		return nil, err
+	}
+
+	var out []*flexcreek.Workout
+	workouts := s.userWorkouts(userID)
+	for i := len(workouts) - 1; i >= 0; i-- {
+		if w := workouts[i]; !w.WorkoutDate.Before(start) && !w.WorkoutDate.After(end) {
+			out = append(out, w)
+		}
+	}
+
+	return out, nil
+}
+
+func (s *fakeStore) CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error) {
+	if err := s.fail["CreateWorkout"]; err != nil {
+		return 0, err
+	}
+
+	created := *w
+	created.ID = s.id()
+	s.workouts = append(s.workouts, &created)
+	return created.ID, nil
+}
+
+func (s *fakeStore) UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error {
+	if err := s.fail["UpdateWorkout"]; err != nil {
+		return err
+	}
+
+	for _, existing := range s.userWorkouts(w.UserID) {
+		if existing.ID == w.ID {
+			*existing = *w
+			return nil
+		}
+	}
+
+	return flexcreek.ErrNotFound
+}
+
+func (s *fakeStore) DeleteWorkout(ctx context.Context, id int, userID int) error {
+	if err := s.fail["DeleteWorkout"]; err != nil {
+		return err
+	}
+
+	for _, w := range s.userWorkouts(userID) {
+		if w.ID == id {
+			w.DeletedAt = time.Now()
+			return nil
+		}
+	}
+
+	return flexcreek.ErrNotFound
+}
+
+func (s *fakeStore) ListWorkoutRevisions(ctx context.Context, workoutID int, userID int) ([]*flexcreek.WorkoutRevision, error) {
+	if err := s.fail["ListWorkoutRevisions"]; err != nil {
+		return nil, err
+	}
+
+	return s.revisions[workoutID], nil
+}
+
+func (s *fakeStore) GetMeasurementsByMetric(ctx context.Context, metric string, userID int) ([]*flexcreek.Measurement, error) {
+	if err := s.fail["GetMeasurementsByMetric"]; err != nil {
+		return nil, err
+	}
+
+	var out []*flexcreek.Measurement
+	for _, m := range s.measurements {
+		if m.UserID == userID && m.Metric == metric {
+			out = append(out, m)
+		}
+	}
+
+	return out, nil
+}
+
+func (s *fakeStore) GetGoals(ctx context.Context, userID int) ([]*flexcreek.Goal, error) {
+	if err := s.fail["GetGoals"]; err != nil {
+		return nil, err
+	}
+
+	var out []*flexcreek.Goal
+	for _, g := range s.goals {
+		if g.UserID == userID {
+			out = append(out, g)
+		}
+	}
+
+	return out, nil
+}
+
+func (s *fakeStore) CreateGoal(ctx context.Context, g *flexcreek.Goal) (int, error) {
+	if err := s.fail["CreateGoal"]; err != nil {
+		return 0, err
+	}
+
+	created := *g
+	created.ID = s.id()
+	s.goals = append(s.goals, &created)
+	return created.ID, nil
+}
+
+func (s *fakeStore) DeleteGoal(ctx context.Context, id int, userID int) error {
+	if err := s.fail["DeleteGoal"]; err != nil {
+		return err
+	}
+
+	for i, g := range s.goals {
+		if g.ID == id && g.UserID == userID {
+			s.goals = append(s.goals[:i], s.goals[i+1:]...)
+			return nil
+		}
+	}
+
+	return flexcreek.ErrNotFound
+}
+
+// stops a cursor blinking. blinking is driven by timers, which would slow every test down and make
+// the view depend on when it was taken
+func staticCursor(c *cursor.Model) {
+	c.SetMode(cursor.CursorStatic)
+}
+
+// harness drives a model the way the bubbletea runtime would, but synchronously: every command a
+// message produces is run straight away and its message fed back in, until things settle
+type harness struct {
+	t     *testing.T
+	model tea.Model
+	msgs  []tea.Msg // every message commands have emitted, in order
+}
+
+func newHarness(t *testing.T, m tea.Model) *harness {
+	t.Helper()
+
+	h := &harness{t: t, model: m}
+	h.run(m.Init())
+	h.send(testSize)
+
+	return h
+}
+
+// send feeds msg to the model and then runs whatever commands follow from it
+func (h *harness) send(msg tea.Msg) {
+	h.t.Helper()
+
+	var cmd tea.Cmd
+	h.model, cmd = h.model.Update(msg)
+	h.run(cmd)
+}
+
+// run executes cmd and feeds its messages back to the model
+func (h *harness) run(cmd tea.Cmd) {
+	h.t.Helper()
+
+	if cmd == nil {
+		return
+	}
+
+	msg := h.exec(cmd)
+	switch msg := msg.(type) {
+	case nil:
+		return
+	case tea.BatchMsg:
+		for _, c := range msg {
+			h.run(c)
+		}
+		return
+	}
+
+	h.msgs = append(h.msgs, msg)
+	h.send(msg)
+}
+
+// runs cmd, failing rather than hanging if it turns out to be a timer
+func (h *harness) exec(cmd tea.Cmd) tea.Msg {
+	h.t.Helper()
+
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	select {
+	case msg := <-done:
+		return msg
+	case <-time.After(time.Second):
+		h.t.Fatal("a command didn't return, it's probably a timer the harness should skip")
+		return nil
+	}
+}
+
+// keys types each key in turn. names bubbletea knows ("enter", "esc", "shift+tab"...) are sent as
+// that key, anything else is typed as text
+func (h *harness) keys(keys ...string) {
+	h.t.Helper()
+
+	for _, k := range keys {
+		h.send(keyMsg(k))
+	}
+}
+
+var namedKeys = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEsc,
+	"tab":       tea.KeyTab,
+	"shift+tab": tea.KeyShiftTab,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"backspace": tea.KeyBackspace,
+	"ctrl+e":    tea.KeyCtrlE,
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	if t, ok := namedKeys[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// clearMsgs forgets the messages seen so far, so a test can check just what the next step emits
+func (h *harness) clearMsgs() {
+	h.msgs = nil
+}
+
+// msgTypes names the emitted messages' types, e.g. [ui.usersLoadedMsg]
+func (h *harness) msgTypes() []string {
+	var types []string
+	for _, msg := range h.msgs {
+		types = append(types, reflect.TypeOf(msg).String())
+	}
+
+	return types
+}
+
+func (h *harness) expectMsgs(want ...string) {
+	h.t.Helper()
+
+	got := h.msgTypes()
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		h.t.Errorf("emitted %v, want %v", got, want)
+	}
+}
+
+// golden compares the model's view with testdata/<name>.golden
+func (h *harness) golden(name string) {
+	h.t.Helper()
+
+	//the date placeholder shows today, which would change the file every day
+	view := strings.ReplaceAll(h.model.View(), testPrefs.FormatDate(testPrefs.Today()), "<today>")
+	//trailing spaces are padding, and editors strip them from golden files
+	var lines []string
+	for _, line := range strings.Split(view, "\n") {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	got := strings.Join(lines, "\n") + "\n"
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			h.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			h.t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		h.t.Fatalf("%v (run go test ./ui -update to create it)", err)
+	}
+
+	if got != string(want) {
+		h.t.Errorf("view doesn't match %s (run go test ./ui -update if the change is intended)\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
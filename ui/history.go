@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek"
+)
+
+// a command to fetch the saved versions of a workout
+func fetchRevisionsCmd(s WorkoutStore, workoutID int, userID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		revisions, err := s.ListWorkoutRevisions(ctx, workoutID, userID)
+		if err != nil {
+			return err
+		}
+
+		return revisionsLoadedMsg{workoutID, revisions}
+	}
+}
+
+// reverting is just another edit, so it's recorded in the history and can itself be undone
+func revertWorkoutCmd(s WorkoutStore, rev *flexcreek.WorkoutRevision) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		w := rev.Workout
+		if err := s.UpdateWorkout(ctx, &w); err != nil {
+			return err
+		}
+
+		reverted, err := s.GetWorkoutByID(ctx, w.ID, w.UserID)
+		if err != nil {
+			return err
+		}
+
+		return workoutRevertedMsg{reverted}
+	}
+}
+
+type revisionsLoadedMsg struct {
+	workoutID int
+	revisions []*flexcreek.WorkoutRevision
+}
+
+type workoutRevertedMsg struct {
+	workout *flexcreek.Workout
+}
+
+func (m WorkoutModel) updateWorkoutHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(keyMsg, m.keys.Back):
+		m.state = stateViewWorkout
+
+	case key.Matches(keyMsg, m.keys.Up):
+		if m.revisionIndex > 0 {
+			m.revisionIndex--
+		}
+
+	case key.Matches(keyMsg, m.keys.Down):
+		if m.revisionIndex < len(m.revisions)-1 {
+			m.revisionIndex++
+		}
+
+	case key.Matches(keyMsg, m.keys.Revert):
+		if len(m.revisions) > 0 {
+			m.loading = true
+			return m, revertWorkoutCmd(m.store, m.revisions[m.revisionIndex])
+		}
+	}
+
+	return m, nil
+}
+
+// lists the revisions with the selected one's changes underneath.
+// revisions hold the values from before an edit, so each is compared with the version that replaced it
+func (m WorkoutModel) viewWorkoutHistory() string {
+	if m.selectedWorkout == nil {
+		return "Error: No workout selected."
+	}
+
+	var b strings.Builder
+	b.WriteString("\n History: " + m.selectedWorkout.ShortDescription + "\n\n")
+
+	if m.loading {
+		b.WriteString(" Loading history...\n")
+		return b.String()
+	}
+
+	if len(m.revisions) == 0 {
+		b.WriteString(" This workout hasn't been edited.\n\n" + m.theme.helpBar(m.keys.Back))
+		return b.String()
+	}
+
+	for i, rev := range m.revisions {
+		cursor := "  "
+		if i == m.revisionIndex {
+			cursor = "> "
+		}
+
+		changedAt := rev.ChangedAt.In(m.prefs.Location())
+		by := "you"
+		if rev.ChangedBy != m.selectedUserID {
+			by = "user #" + strconv.Itoa(rev.ChangedBy)
+		}
+
+		b.WriteString(cursor + "edited " + m.prefs.FormatDate(changedAt) + " " + changedAt.Format("15:04") + " by " + by + "\n")
+	}
+
+	rev := m.revisions[m.revisionIndex]
+	next := m.selectedWorkout
+	if m.revisionIndex > 0 {
+		next = &m.revisions[m.revisionIndex-1].Workout
+	}
+
+	b.WriteString("\n Changes in this edit:\n\n")
+	changes := flexcreek.DiffWorkouts(&rev.Workout, next)
+	if len(changes) == 0 {
+		b.WriteString("  (no changes)\n")
+	}
+	for _, c := range changes {
+		b.WriteString(formatChange(c))
+	}
+
+	b.WriteString("\n" + m.theme.helpBar(relabel(m.keys.Up, "newer"), relabel(m.keys.Down, "older"), relabel(m.keys.Revert, "revert to this version"), m.keys.Back))
+	return b.String()
+}
+
+// single line values read as old -> new, multi-line ones as removed and added lines
+func formatChange(c flexcreek.FieldChange) string {
+	if !strings.Contains(c.Old, "\n") && !strings.Contains(c.New, "\n") {
+		return "  " + c.Field + ": " + c.Old + " -> " + c.New + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("  " + c.Field + ":\n")
+	for _, line := range strings.Split(c.Old, "\n") {
+		b.WriteString("    - " + line + "\n")
+	}
+	for _, line := range strings.Split(c.New, "\n") {
+		b.WriteString("    + " + line + "\n")
+	}
+
+	return b.String()
+}
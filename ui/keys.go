@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// KeyMap holds every key binding the TUI uses. each model matches keys against it rather than
+// spelling them out, so rebinding a key in the config file changes both what it does and the help bar
+type KeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+	Back   key.Binding
+	Filter key.Binding
+	Quit   key.Binding
+	Submit key.Binding // on a form's last field. elsewhere on a form it moves on a field
+
+	NextField   key.Binding
+	PrevField   key.Binding
+	NextWorkout key.Binding // in the detail view, down the list
+	PrevWorkout key.Binding
+
+	New          key.Binding
+	Delete       key.Binding
+	Trash        key.Binding
+	Restore      key.Binding
+	SwitchUser   key.Binding
+	Measurements key.Binding
+	Goals        key.Binding
+	TrainingLoad key.Binding
+	FoldWeek     key.Binding
+	History      key.Binding
+	Revert       key.Binding
+	EditNotes    key.Binding
+	ConvertUnits key.Binding
+	Palette      key.Binding
+	Duplicate    key.Binding
+}
+
+// DefaultKeyMap returns the bindings flexcreek ships with
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:     binding("up", []string{"up", "k"}),
+		Down:   binding("down", []string{"down", "j"}),
+		Select: binding("select", []string{"enter"}),
+		Back:   binding("back", []string{"esc"}),
+		Filter: binding("filter", []string{"/"}),
+		Quit:   binding("quit", []string{"q"}),
+		Submit: binding("save", []string{"enter"}),
+
+		NextField:   binding("next field", []string{"tab", "down"}),
+		PrevField:   binding("previous field", []string{"shift+tab", "up"}),
+		NextWorkout: binding("next workout", []string{"n", "right"}),
+		PrevWorkout: binding("previous workout", []string{"p", "left"}),
+
+		New:          binding("new", []string{"n"}),
+		Delete:       binding("delete", []string{"x"}),
+		Trash:        binding("trash", []string{"T"}),
+		Restore:      binding("restore", []string{"r", "enter"}),
+		SwitchUser:   binding("switch user", []string{"s"}),
+		Measurements: binding("measurements", []string{"m"}),
+		Goals:        binding("goals", []string{"o"}),
+		TrainingLoad: binding("training load", []string{"t"}),
+		FoldWeek:     binding("fold week", []string{" "}),
+		History:      binding("edit history", []string{"h"}),
+		Revert:       binding("revert", []string{"r"}),
+		EditNotes:    binding("notes in $EDITOR", []string{"ctrl+e"}),
+		ConvertUnits: binding("kg/lb cm/in", []string{"c"}),
+		Palette:      binding("commands", []string{":"}),
+		Duplicate:    binding("duplicate", []string{"d"}),
+	}
+}
+
+// a binding whose help lists all its keys, e.g. ↑/k
+func binding(desc string, keys []string) key.Binding {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = keyName(k)
+	}
+
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(names, "/"), desc))
+}
+
+// the same keys described for one screen, e.g. new as "new workout"
+func relabel(b key.Binding, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(b.Keys()...), key.WithHelp(b.Help().Key, desc))
+}
+
+// how a key is written in the help bar
+func keyName(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+
+	return k
+}
+
+// the bindings by the name the config file uses for them
+func (k *KeyMap) named() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":            &k.Up,
+		"down":          &k.Down,
+		"select":        &k.Select,
+		"back":          &k.Back,
+		"filter":        &k.Filter,
+		"quit":          &k.Quit,
+		"submit":        &k.Submit,
+		"next_field":    &k.NextField,
+		"prev_field":    &k.PrevField,
+		"next_workout":  &k.NextWorkout,
+		"prev_workout":  &k.PrevWorkout,
+		"new":           &k.New,
+		"delete":        &k.Delete,
+		"trash":         &k.Trash,
+		"restore":       &k.Restore,
+		"switch_user":   &k.SwitchUser,
+		"measurements":  &k.Measurements,
+		"goals":         &k.Goals,
+		"training_load": &k.TrainingLoad,
+		"fold_week":     &k.FoldWeek,
+		"history":       &k.History,
+		"revert":        &k.Revert,
+		"edit_notes":    &k.EditNotes,
+		"convert_units": &k.ConvertUnits,
+		"palette":       &k.Palette,
+		"duplicate":     &k.Duplicate,
+	}
+}
+
+// rebind replaces the keys of the named bindings, keeping their help descriptions
+func (k *KeyMap) rebind(overrides map[string][]string) error {
+	named := k.named()
+	for name, keys := range overrides {
+		b, ok := named[name]
+		if !ok {
+			valid := make([]string, 0, len(named))
+			for n := range named {
+				valid = append(valid, n)
+			}
+			slices.Sort(valid)
+			return fmt.Errorf("unknown key binding %q, the bindings are %s", name, strings.Join(valid, ", "))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("key binding %q needs at least one key", name)
+		}
+
+		*b = binding(b.Help().Desc, keys)
+	}
+
+	return nil
+}
+
+// points a bubbles list's own navigation at the keymap, and adds the screen's extra keys to its help
+func (k KeyMap) applyToList(l *list.Model, extra ...key.Binding) {
+	l.KeyMap.CursorUp = k.Up
+	l.KeyMap.CursorDown = k.Down
+	l.KeyMap.Filter = k.Filter
+	l.KeyMap.Quit = k.Quit
+	l.KeyMap.CancelWhileFiltering = relabel(k.Back, "cancel")
+	l.KeyMap.ClearFilter = relabel(k.Back, "clear filter")
+	l.AdditionalShortHelpKeys = func() []key.Binding { return extra }
+}
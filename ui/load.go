@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek/load"
+)
+
+// number of days shown in the training load charts
+const loadChartDays = 42
+
+// a command to compute training load metrics for the selected user
+func fetchLoadCmd(s WorkoutStore, today time.Time, userID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		days, err := load.NewService(s).Daily(ctx, today, loadChartDays, userID)
+		if err != nil {
+			return err
+		}
+
+		return loadLoadedMsg{days}
+	}
+}
+
+type loadLoadedMsg struct {
+	days []load.Day
+}
+
+// view helper for the training load screen
+func (m WorkoutModel) viewLoad() string {
+	if m.loading {
+		return " Calculating training load..."
+	}
+
+	if len(m.loadDays) == 0 {
+		return "\n No training load yet.\n\n" + m.theme.helpBar(m.keys.Back)
+	}
+
+	series := func(f func(load.Day) float64) []float64 {
+		vals := make([]float64, len(m.loadDays))
+		for i, d := range m.loadDays {
+			vals[i] = f(d)
+		}
+		return vals
+	}
+
+	latest := m.loadDays[len(m.loadDays)-1]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n Training Load (last %d days)\n\n", len(m.loadDays))
+	fmt.Fprintf(&b, " Load  %s  %.0f\n", sparkline(series(func(d load.Day) float64 { return d.Load })), latest.Load)
+	fmt.Fprintf(&b, " ACWR  %s  %.2f\n", sparkline(series(func(d load.Day) float64 { return d.ACWR })), latest.ACWR)
+	fmt.Fprintf(&b, " CTL   %s  %.1f\n", sparkline(series(func(d load.Day) float64 { return d.CTL })), latest.CTL)
+	fmt.Fprintf(&b, " ATL   %s  %.1f\n", sparkline(series(func(d load.Day) float64 { return d.ATL })), latest.ATL)
+	fmt.Fprintf(&b, " TSB   %s  %.1f\n", sparkline(series(func(d load.Day) float64 { return d.TSB })), latest.TSB)
+
+	if w := latest.Warning(); w != "" {
+		b.WriteString("\n " + m.theme.Warning.Render("! "+w) + "\n")
+	}
+
+	b.WriteString("\n" + m.theme.helpBar(m.keys.Back))
+	return b.String()
+}
+
+func (m WorkoutModel) updateViewLoad(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && key.Matches(msg, m.keys.Back) {
+		m.state = stateWorkoutList
+	}
+	return m, nil
+}
+
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// renders values as a one-line sparkline scaled between the series min and max
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,425 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/trend"
+	"github.com/ekholme/flexcreek/units"
+)
+
+const (
+	stateMeasurementList sessionState = iota
+	stateCreateMeasurement
+	stateMeasurementTrend
+)
+
+// the create form's inputs, in focus order
+const (
+	inputMetric = iota
+	inputValue
+	inputUnit
+	inputMeasuredOn
+	numMeasurementInputs
+)
+
+// number of trend rows listed under the sparklines
+const trendTableLength = 10
+
+// defining interfaces that the measurement model requires
+type MeasurementProvider interface {
+	GetLatestMeasurements(ctx context.Context, n int, userID int) ([]*flexcreek.Measurement, error)
+	GetMeasurementsByMetric(ctx context.Context, metric string, userID int) ([]*flexcreek.Measurement, error)
+}
+
+type MeasurementCreator interface {
+	CreateMeasurement(ctx context.Context, m *flexcreek.Measurement) (int, error)
+}
+
+type MeasurementStore interface {
+	MeasurementProvider
+	MeasurementCreator
+}
+
+// handles logging, listing and trending body measurements
+type MeasurementModel struct {
+	store           MeasurementStore
+	list            list.Model
+	inputs          []textinput.Model
+	inputFocusIndex int
+	state           sessionState
+	loading         bool
+	err             error
+	selectedUserID  int
+	listLength      int
+	trendMetric     string
+	trend           []*flexcreek.Measurement
+	prefs           flexcreek.Preferences
+	converted       bool //show values in the counterpart of the preferred unit (kg <-> lb, cm <-> in)
+	keys            KeyMap
+	theme           Theme
+}
+
+func NewMeasurementModel(s MeasurementStore, userID int, prefs flexcreek.Preferences, listLength int, cfg Config) MeasurementModel {
+	l := newList("Measurements", nil, cfg.Theme, cfg.Keys,
+		relabel(cfg.Keys.New, "log measurement"),
+		cfg.Keys.ConvertUnits,
+	)
+
+	placeholders := []string{
+		"Metric (e.g. bodyweight, body fat, waist)",
+		"Value (e.g. 81.4)",
+		"Unit (kg, lb, cm, in, %)",
+		"Date (e.g. " + prefs.FormatDate(prefs.Today()) + ", defaults to today)",
+	}
+	inputs := make([]textinput.Model, numMeasurementInputs)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = placeholders[i]
+	}
+	inputs[inputMeasuredOn].CharLimit = 12
+	inputs[inputMetric].Focus()
+
+	return MeasurementModel{
+		store:          s,
+		list:           l,
+		inputs:         inputs,
+		state:          stateMeasurementList,
+		loading:        true,
+		selectedUserID: userID,
+		prefs:          prefs,
+		listLength:     listLength,
+		keys:           cfg.Keys,
+		theme:          cfg.Theme,
+	}
+}
+
+func fetchLatestMeasurementsCmd(s MeasurementStore, n int, userID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		measurements, err := s.GetLatestMeasurements(ctx, n, userID)
+		if err != nil {
+			return err
+		}
+
+		return measurementsLoadedMsg{measurements}
+	}
+}
+
+func fetchMeasurementTrendCmd(s MeasurementStore, metric string, userID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		measurements, err := s.GetMeasurementsByMetric(ctx, metric, userID)
+		if err != nil {
+			return err
+		}
+
+		return measurementTrendLoadedMsg{measurements}
+	}
+}
+
+func createMeasurementCmd(s MeasurementStore, m *flexcreek.Measurement) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		_, err := s.CreateMeasurement(ctx, m)
+		if err != nil {
+			return err
+		}
+
+		return measurementCreatedMsg{}
+	}
+}
+
+type measurementsLoadedMsg struct {
+	measurements []*flexcreek.Measurement
+}
+
+type measurementTrendLoadedMsg struct {
+	measurements []*flexcreek.Measurement
+}
+
+type measurementCreatedMsg struct {
+}
+
+type measurementItem struct {
+	flexcreek.Measurement
+	displayUnit string
+	date        string
+}
+
+func (i measurementItem) Title() string {
+	return i.Metric + ": " + formatQuantity(i.Value, i.Unit, i.displayUnit)
+}
+func (i measurementItem) Description() string { return i.date }
+func (i measurementItem) FilterValue() string { return i.Metric }
+
+// wraps a measurement for the list, formatted for the user
+func (m MeasurementModel) newMeasurementItem(ms flexcreek.Measurement) measurementItem {
+	return measurementItem{
+		Measurement: ms,
+		displayUnit: m.displayUnit(ms.Unit),
+		date:        m.prefs.FormatDate(ms.MeasuredOn),
+	}
+}
+
+// the unit a stored (canonical) unit is shown in, honouring the preference and the convert toggle
+func (m MeasurementModel) displayUnit(canonical string) string {
+	u := m.prefs.DisplayUnit(canonical)
+	if m.converted {
+		u = units.Counterpart(u)
+	}
+	return u
+}
+
+// formats a value stored in one unit converted into another
+func formatQuantity(value float64, from string, to string) string {
+	if v, err := units.Convert(value, from, to); err == nil {
+		value, from = v, to
+	}
+
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + from
+}
+
+// bubbletea model requirements
+func (m MeasurementModel) Init() tea.Cmd {
+	return fetchLatestMeasurementsCmd(m.store, m.listLength, m.selectedUserID)
+}
+
+func (m MeasurementModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case error:
+		m.loading = false
+		m.err = msg
+		return m, nil
+
+	case measurementsLoadedMsg:
+		m.loading = false
+		items := make([]list.Item, len(msg.measurements))
+		for i, ms := range msg.measurements {
+			items[i] = m.newMeasurementItem(*ms)
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case measurementTrendLoadedMsg:
+		m.loading = false
+		m.trend = msg.measurements
+		return m, nil
+
+	case measurementCreatedMsg:
+		m.state = stateMeasurementList
+		m.loading = true
+		for i := range m.inputs {
+			m.inputs[i].Reset()
+		}
+		return m, fetchLatestMeasurementsCmd(m.store, m.listLength, m.selectedUserID)
+	}
+
+	switch m.state {
+	case stateCreateMeasurement:
+		return m.updateMeasurementForm(msg)
+	case stateMeasurementTrend:
+		return m.updateMeasurementTrend(msg)
+	default:
+		return m.updateMeasurementList(msg)
+	}
+}
+
+func (m MeasurementModel) View() string {
+	if m.err != nil {
+		return m.theme.Error.Render("Error: " + m.err.Error())
+	}
+
+	switch m.state {
+	case stateCreateMeasurement:
+		var b strings.Builder
+		b.WriteString("\n Log Measurement \n\n")
+		for _, in := range m.inputs {
+			b.WriteString(in.View() + "\n\n")
+		}
+		b.WriteString(m.theme.helpBar(m.keys.NextField, m.keys.PrevField, m.keys.Submit, m.keys.Back))
+		return b.String()
+
+	case stateMeasurementTrend:
+		return m.viewMeasurementTrend()
+
+	default:
+		if m.loading {
+			return " Loading measurements..."
+		}
+
+		return "\n" + m.list.View()
+	}
+}
+
+// view helper for the trend screen -- raw values and their 7 day moving average
+func (m MeasurementModel) viewMeasurementTrend() string {
+	if m.loading {
+		return " Loading " + m.trendMetric + "..."
+	}
+
+	if len(m.trend) == 0 {
+		return "\n No " + m.trendMetric + " measurements yet.\n\n" + m.theme.helpBar(m.keys.Back)
+	}
+
+	points := trend.MovingAverage(m.trend, trend.DefaultWindow)
+	unit := m.trend[0].Unit
+
+	values := make([]float64, len(points))
+	averages := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+		averages[i] = p.Average
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n %s trend\n\n", m.trendMetric)
+	fmt.Fprintf(&b, " value   %s\n", sparkline(values))
+	fmt.Fprintf(&b, " %d-day   %s\n\n", trend.DefaultWindow, sparkline(averages))
+
+	start := max(0, len(points)-trendTableLength)
+	for i := len(points) - 1; i >= start; i-- {
+		p := points[i]
+		fmt.Fprintf(&b, " %s  %12s  avg %12s\n",
+			m.prefs.FormatDate(p.Date),
+			formatQuantity(p.Value, unit, m.displayUnit(unit)),
+			formatQuantity(p.Average, unit, m.displayUnit(unit)))
+	}
+
+	b.WriteString("\n" + m.theme.helpBar(relabel(m.keys.ConvertUnits, "convert units"), m.keys.Back))
+	return b.String()
+}
+
+// update helpers
+func (m MeasurementModel) updateMeasurementList(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.list.SetSize(size.Width, size.Height)
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keys.New):
+			m.state = stateCreateMeasurement
+			m.inputFocusIndex = inputMetric
+			return m, m.inputs[inputMetric].Focus()
+
+		case key.Matches(msg, m.keys.ConvertUnits):
+			m.converted = !m.converted
+			items := m.list.Items()
+			for i, it := range items {
+				if mi, ok := it.(measurementItem); ok {
+					items[i] = m.newMeasurementItem(mi.Measurement)
+				}
+			}
+			return m, m.list.SetItems(items)
+
+		case key.Matches(msg, m.keys.Select):
+			if i, ok := m.list.SelectedItem().(measurementItem); ok {
+				m.state = stateMeasurementTrend
+				m.loading = true
+				m.trendMetric = i.Metric
+				return m, fetchMeasurementTrendCmd(m.store, i.Metric, m.selectedUserID)
+			}
+
+		case key.Matches(msg, m.keys.Back):
+			//nothing to clear, so esc heads back to the workouts
+			if m.list.FilterState() == list.Unfiltered {
+				return m, func() tea.Msg { return showWorkoutsMsg{} }
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m MeasurementModel) updateMeasurementTrend(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.state = stateMeasurementList
+		case key.Matches(msg, m.keys.ConvertUnits):
+			m.converted = !m.converted
+		}
+	}
+	return m, nil
+}
+
+func (m MeasurementModel) updateMeasurementForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.state = stateMeasurementList
+			return m, nil
+
+		case key.Matches(msg, m.keys.NextField, m.keys.PrevField, m.keys.Submit):
+			if key.Matches(msg, m.keys.Submit) && m.inputFocusIndex == numMeasurementInputs-1 {
+				return m.submitMeasurementForm()
+			}
+
+			if key.Matches(msg, m.keys.PrevField) {
+				m.inputFocusIndex--
+			} else {
+				m.inputFocusIndex++
+			}
+
+			if m.inputFocusIndex > numMeasurementInputs-1 {
+				m.inputFocusIndex = 0
+			} else if m.inputFocusIndex < 0 {
+				m.inputFocusIndex = numMeasurementInputs - 1
+			}
+
+			for i := range m.inputs {
+				m.inputs[i].Blur()
+			}
+			return m, m.inputs[m.inputFocusIndex].Focus()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.inputFocusIndex], cmd = m.inputs[m.inputFocusIndex].Update(msg)
+	return m, cmd
+}
+
+// validates the form and fires off the create command
+func (m MeasurementModel) submitMeasurementForm() (tea.Model, tea.Cmd) {
+	metric := strings.TrimSpace(m.inputs[inputMetric].Value())
+	unit := strings.TrimSpace(m.inputs[inputUnit].Value())
+	value, err := strconv.ParseFloat(strings.TrimSpace(m.inputs[inputValue].Value()), 64)
+	if metric == "" || unit == "" || err != nil {
+		//stay on the form until it's filled in properly
+		return m, nil
+	}
+
+	date := m.prefs.Today()
+	if v := m.inputs[inputMeasuredOn].Value(); v != "" {
+		if t, err := m.prefs.ParseDate(v); err == nil {
+			date = t
+		}
+	}
+
+	//measurements are always stored in canonical units
+	value, unit = units.Canonical(value, unit)
+
+	ms := flexcreek.Measurement{
+		UserID:     m.selectedUserID,
+		Metric:     metric,
+		Value:      value,
+		Unit:       unit,
+		MeasuredOn: date,
+	}
+	m.loading = true
+	return m, createMeasurementCmd(m.store, &ms)
+}
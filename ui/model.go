@@ -1,7 +1,8 @@
 package ui
 
 import (
-	"github.com/ekholme/flexcreek/sqlite"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek"
 )
 
 // root model that manages which view is currently active and delegates bubbletea calls to sub-models
@@ -11,16 +12,151 @@ type sessionState int
 const (
 	stateUserManager sessionState = iota
 	stateWorkoutManager
+	stateMeasurementManager
+	stateTrashManager
+	stateGoalManager
 )
 
+// messages sub-models send to ask the root model to switch views
+type showUsersMsg struct{}
+
+type showWorkoutsMsg struct{}
+
+type showMeasurementsMsg struct{}
+
+type showTrashMsg struct{}
+
+type showGoalsMsg struct{}
+
 type RootModel struct {
-	state        sessionState
-	userModel    UserModel
-	workoutModel WorkoutModel
+	state            sessionState
+	store            flexcreek.Store
+	listLength       int
+	cfg              Config
+	size             tea.WindowSizeMsg
+	selectedUser     *flexcreek.User
+	userModel        UserModel
+	workoutModel     WorkoutModel
+	measurementModel MeasurementModel
+	trashModel       TrashModel
+	goalModel        GoalModel
 }
 
 // constructor function
-func NewRootModel(s *sqlite.Storage) RootModel {
-	//TODO
-	return RootModel{}
+// cfg is the theme and keys every view is built with, see LoadConfig
+func NewRootModel(s flexcreek.Store, listLength int, cfg Config) RootModel {
+	return RootModel{
+		state:      stateUserManager,
+		store:      s,
+		listLength: listLength,
+		cfg:        cfg,
+		userModel:  NewUserModel(s, cfg),
+	}
+}
+
+func (m RootModel) Init() tea.Cmd {
+	return m.userModel.Init()
+}
+
+func (m RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+	case tea.WindowSizeMsg:
+		//remember the size so views created later can be sized straight away
+		m.size = msg
+
+	case userSelectedMsg:
+		m.selectedUser = msg.user
+		m.workoutModel = NewWorkoutModel(m.store, msg.user.ID, msg.user.Preferences, m.listLength, m.cfg)
+		m.workoutModel = m.resize(m.workoutModel).(WorkoutModel)
+		m.state = stateWorkoutManager
+		return m, m.workoutModel.Init()
+
+	case switchUserMsg:
+		return m, findUserCmd(m.store, msg.username)
+
+	case showUsersMsg:
+		m.userModel = NewUserModel(m.store, m.cfg)
+		m.userModel = m.resize(m.userModel).(UserModel)
+		m.state = stateUserManager
+		return m, m.userModel.Init()
+
+	case showWorkoutsMsg:
+		m.state = stateWorkoutManager
+		return m, m.workoutModel.Init()
+
+	case showMeasurementsMsg:
+		m.measurementModel = NewMeasurementModel(m.store, m.selectedUser.ID, m.selectedUser.Preferences, m.listLength, m.cfg)
+		m.measurementModel = m.resize(m.measurementModel).(MeasurementModel)
+		m.state = stateMeasurementManager
+		return m, m.measurementModel.Init()
+
+	case showGoalsMsg:
+		m.goalModel = NewGoalModel(m.store, m.selectedUser.ID, m.selectedUser.Preferences, m.cfg)
+		m.goalModel = m.resize(m.goalModel).(GoalModel)
+		m.state = stateGoalManager
+		return m, m.goalModel.Init()
+
+	case showTrashMsg:
+		//from the user list the trash holds deleted users, otherwise the selected user's deleted workouts
+		if m.state == stateUserManager {
+			m.trashModel = NewTrashModel(m.store, 0, flexcreek.DefaultPreferences(), m.cfg)
+		} else {
+			m.trashModel = NewTrashModel(m.store, m.selectedUser.ID, m.selectedUser.Preferences, m.cfg)
+		}
+		m.trashModel = m.resize(m.trashModel).(TrashModel)
+		m.state = stateTrashManager
+		return m, m.trashModel.Init()
+	}
+
+	var cmd tea.Cmd
+	var sub tea.Model
+	switch m.state {
+	case stateWorkoutManager:
+		sub, cmd = m.workoutModel.Update(msg)
+		m.workoutModel = sub.(WorkoutModel)
+	case stateMeasurementManager:
+		sub, cmd = m.measurementModel.Update(msg)
+		m.measurementModel = sub.(MeasurementModel)
+	case stateTrashManager:
+		sub, cmd = m.trashModel.Update(msg)
+		m.trashModel = sub.(TrashModel)
+	case stateGoalManager:
+		sub, cmd = m.goalModel.Update(msg)
+		m.goalModel = sub.(GoalModel)
+	default:
+		sub, cmd = m.userModel.Update(msg)
+		m.userModel = sub.(UserModel)
+	}
+
+	return m, cmd
+}
+
+func (m RootModel) View() string {
+	switch m.state {
+	case stateWorkoutManager:
+		return m.workoutModel.View()
+	case stateMeasurementManager:
+		return m.measurementModel.View()
+	case stateTrashManager:
+		return m.trashModel.View()
+	case stateGoalManager:
+		return m.goalModel.View()
+	default:
+		return m.userModel.View()
+	}
+}
+
+// passes the last known window size on to a freshly created sub-model
+func (m RootModel) resize(sub tea.Model) tea.Model {
+	if m.size.Width == 0 && m.size.Height == 0 {
+		return sub
+	}
+
+	sub, _ = sub.Update(m.size)
+	return sub
 }
@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/editor"
+)
+
+// the command palette: ":" on the workout list opens a line that runs a command, or, when it
+// doesn't start with one, logs a workout written the quick-add way (see editor.ParseQuickAdd).
+// what enter will do is previewed underneath as the line is typed
+
+var paletteCommands = []struct{ usage, desc string }{
+	{"user <name>", "switch to another user"},
+	{"goto <day>", "jump the list to a day, e.g. goto yesterday"},
+	{"stats", "training load"},
+	{"add <workout>", "log a workout whose title starts with a command's name"},
+}
+
+// what the palette's line asks for
+type paletteEntry struct {
+	command string             // empty for a workout
+	arg     string             // the user command's username
+	day     time.Time          // goto's
+	workout *flexcreek.Workout // the workout to log
+}
+
+// asks the root model to switch to the named user
+type switchUserMsg struct {
+	username string
+}
+
+// a palette command that failed, shown in the palette rather than taking over the screen
+type paletteErrorMsg struct {
+	err error
+}
+
+func newPaletteInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ": "
+	ti.Placeholder = "KB ABC @yesterday #conditioning 20min rpe8 -- notes, or a command"
+	return ti
+}
+
+// reads the palette's line. an empty line is an empty entry, which just closes the palette
+func (m WorkoutModel) parsePalette(line string) (paletteEntry, error) {
+	line = strings.TrimSpace(line)
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "":
+		return paletteEntry{}, nil
+
+	case "user":
+		return paletteEntry{command: name, arg: arg}, nil
+
+	case "goto":
+		example := m.prefs.FormatDate(m.prefs.Today())
+		if arg == "" {
+			return paletteEntry{}, fmt.Errorf("goto needs a day, e.g. goto yesterday, goto mon or goto %s", example)
+		}
+		day, err := m.prefs.ParseDay(arg)
+		if err != nil {
+			return paletteEntry{}, fmt.Errorf("%s isn't a day, try yesterday, mon or %s", arg, example)
+		}
+		return paletteEntry{command: name, day: day}, nil
+
+	case "stats":
+		if arg != "" {
+			return paletteEntry{}, errors.New("stats doesn't take anything after it")
+		}
+		return paletteEntry{command: name}, nil
+
+	case "add":
+		line = arg
+	}
+
+	d, err := editor.ParseQuickAdd(line, m.prefs)
+	if err != nil {
+		return paletteEntry{}, err
+	}
+	w, err := d.Workout(m.selectedUserID, m.prefs)
+	if err != nil {
+		return paletteEntry{}, err
+	}
+
+	return paletteEntry{workout: w}, nil
+}
+
+func (m WorkoutModel) updatePalette(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.state = stateWorkoutList
+			return m, nil
+
+		case key.Matches(msg, m.keys.Submit):
+			entry, err := m.parsePalette(m.palette.Value())
+			if err != nil {
+				//the preview is already showing why
+				return m, nil
+			}
+			return m.runPalette(entry)
+		}
+
+		//a failed command is forgotten as soon as the line changes
+		m.paletteErr = nil
+	}
+
+	var cmd tea.Cmd
+	m.palette, cmd = m.palette.Update(msg)
+	return m, cmd
+}
+
+func (m WorkoutModel) runPalette(entry paletteEntry) (tea.Model, tea.Cmd) {
+	switch entry.command {
+	case "user":
+		if entry.arg == "" {
+			return m, func() tea.Msg { return showUsersMsg{} }
+		}
+		//the palette stays open in case there's no such user
+		return m, func() tea.Msg { return switchUserMsg{entry.arg} }
+
+	case "goto":
+		return m.gotoDay(entry.day)
+
+	case "stats":
+		m.state = stateViewLoad
+		m.loading = true
+		return m, fetchLoadCmd(m.store, m.prefs.Today(), m.selectedUserID)
+	}
+
+	if entry.workout == nil {
+		m.state = stateWorkoutList
+		return m, nil
+	}
+
+	m.loading = true
+	return m, createWorkoutCmd(m.store, entry.workout)
+}
+
+// moves the list's cursor to day's first workout, or to the nearest day before it with any.
+// a day older than the list reaches loads the list back that far first
+func (m WorkoutModel) gotoDay(day time.Time) (tea.Model, tea.Cmd) {
+	m.state = stateWorkoutList
+	m.list.ResetFilter()
+
+	if n := len(m.workouts); n > 0 && day.Before(m.workouts[n-1].WorkoutDate) {
+		m.from = day
+		m.jumpTo = day
+		m.loading = true
+		return m, fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID, m.prefs, m.from)
+	}
+
+	return m.selectDay(day), nil
+}
+
+func (m WorkoutModel) selectDay(day time.Time) WorkoutModel {
+	//workouts are newest first, so this is the latest on or before day, falling back to the oldest
+	i := len(m.workouts) - 1
+	for j, w := range m.workouts {
+		if !w.WorkoutDate.After(day) {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return m
+	}
+	date := m.workouts[i].WorkoutDate
+
+	delete(m.collapsed, m.prefs.StartOfWeek(date))
+	m.list.SetItems(groupWorkouts(m.workouts, m.prefs, m.collapsed))
+	for j, item := range m.list.Items() {
+		if d, ok := item.(dayItem); ok && d.date.Equal(date) {
+			m.list.Select(j + 1)
+			break
+		}
+	}
+
+	return m
+}
+
+// view helper for the palette
+func (m WorkoutModel) viewPalette() string {
+	return "\n " + m.palette.View() + "\n\n" +
+		m.palettePreview() + "\n\n" +
+		m.theme.helpBar(relabel(m.keys.Submit, "run"), m.keys.Back)
+}
+
+// what enter would do with the line as it stands
+func (m WorkoutModel) palettePreview() string {
+	entry, err := m.parsePalette(m.palette.Value())
+	if m.paletteErr != nil {
+		err = m.paletteErr
+	}
+	if err != nil {
+		return "   " + m.theme.Error.Render(err.Error())
+	}
+
+	muted := m.theme.Muted.Padding(0, 3)
+	switch entry.command {
+	case "user":
+		if entry.arg == "" {
+			return muted.Render("switch user")
+		}
+		return muted.Render("switch to " + entry.arg)
+
+	case "goto":
+		return muted.Render("jump to " + entry.day.Format("Mon") + " " + m.prefs.FormatDate(entry.day))
+
+	case "stats":
+		return muted.Render("show the training load")
+	}
+
+	w := entry.workout
+	if w == nil {
+		lines := make([]string, len(paletteCommands))
+		for i, c := range paletteCommands {
+			lines[i] = fmt.Sprintf("%-14s %s", c.usage, c.desc)
+		}
+		lines = append(lines, "", "anything else logs a workout: @day or @07:30, 45min or 1h30, rpe8, load300, -- notes")
+		return muted.Render(strings.Join(lines, "\n"))
+	}
+
+	lines := []string{
+		m.theme.Heading.Padding(0, 3).Render("Log " + w.ShortDescription),
+		muted.Render(workoutFacts(w, m.prefs)),
+	}
+	//workouts don't have tags of their own, so they're kept in the title where the filter finds them
+	var tags []string
+	for _, word := range strings.Fields(w.ShortDescription) {
+		if strings.HasPrefix(word, "#") && len(word) > 1 {
+			tags = append(tags, word)
+		}
+	}
+	if len(tags) > 0 {
+		lines = append(lines, muted.Render("tags "+strings.Join(tags, " ")))
+	}
+	if w.LongDescription != "" {
+		lines = append(lines, muted.Render(w.LongDescription))
+	}
+
+	return strings.Join(lines, "\n")
+}
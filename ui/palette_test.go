@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+func TestPaletteQuickAdd(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	h.keys(":")
+	h.golden("palette_empty")
+
+	h.keys("Tempo run @2026-03-04 @06:45 #threshold 1h rpe7 -- 3 x 10 min")
+	h.golden("palette_quick_add")
+
+	h.clearMsgs()
+	h.keys("enter")
+	h.expectMsgs("ui.workoutCreatedMsg", "ui.workoutsLoadedMsg")
+
+	created := s.workouts[len(s.workouts)-1]
+	want := flexcreek.Workout{
+		ID:               created.ID,
+		UserID:           testUserID,
+		ShortDescription: "Tempo run #threshold",
+		LongDescription:  "3 x 10 min",
+		WorkoutDate:      time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		StartTime:        time.Date(2026, 3, 4, 6, 45, 0, 0, time.UTC),
+		DurationMinutes:  60,
+		RPE:              7,
+	}
+	if *created != want {
+		t.Errorf("created %+v, want %+v", *created, want)
+	}
+	if got := h.model.(WorkoutModel).state; got != stateWorkoutList {
+		t.Errorf("after logging the state is %v, want the list", got)
+	}
+}
+
+func TestPaletteQuickAddRelativeDay(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	h.keys(":", "Easy run @yesterday 30min", "enter")
+
+	created := s.workouts[len(s.workouts)-1]
+	if want := testPrefs.Today().AddDate(0, 0, -1); !created.WorkoutDate.Equal(want) || created.DurationMinutes != 30 {
+		t.Errorf("created %s on %s for %d min, want yesterday (%s) for 30", created.ShortDescription,
+			created.WorkoutDate, created.DurationMinutes, want)
+	}
+}
+
+func TestPaletteErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"Run @someday", "@someday isn't a day or a time"},
+		{"Run rpe11", "rpe must be between 1 and 10"},
+		{"@today 20min", "the workout has no title"},
+		{"goto", "goto needs a day"},
+		{"goto someday", "someday isn't a day"},
+		{"stats please", "stats doesn't take anything"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			s := workoutFixture()
+			h := newWorkoutHarness(t, s)
+
+			h.keys(":", tt.line)
+			if view := h.model.View(); !strings.Contains(view, tt.want) {
+				t.Errorf("the preview doesn't show %q:\n%s", tt.want, view)
+			}
+
+			h.clearMsgs()
+			h.keys("enter")
+			h.expectMsgs()
+			if got := h.model.(WorkoutModel).state; got != stateCommandPalette {
+				t.Errorf("enter on a bad line left the palette, state %v", got)
+			}
+			if len(s.workouts) != 3 {
+				t.Errorf("the store has %d workouts, want the fixture's 3", len(s.workouts))
+			}
+		})
+	}
+}
+
+func TestPaletteBack(t *testing.T) {
+	h := newWorkoutHarness(t, workoutFixture())
+
+	h.keys(":", "Half a thought", "esc")
+	h.golden("workout_list")
+
+	//the line starts empty next time
+	h.keys(":")
+	h.golden("palette_empty")
+}
+
+func selectedWorkout(t *testing.T, h *harness) flexcreek.Workout {
+	t.Helper()
+
+	i, ok := h.model.(WorkoutModel).list.SelectedItem().(workoutItem)
+	if !ok {
+		t.Fatalf("the cursor is on %v, not a workout", h.model.(WorkoutModel).list.SelectedItem())
+	}
+
+	return i.Workout
+}
+
+func TestPaletteGoto(t *testing.T) {
+	h := newWorkoutHarness(t, trainingLogFixture())
+
+	//the week holding the 11th is folded, and goto opens it back up
+	h.keys(" ", ":", "goto 2026-03-11", "enter")
+	if w := selectedWorkout(t, h); !w.WorkoutDate.Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("goto 2026-03-11 selected %s on %s", w.ShortDescription, w.WorkoutDate)
+	}
+
+	//a day without workouts lands on the closest day before it
+	h.keys(":", "goto 2026-03-04", "enter")
+	if w := selectedWorkout(t, h); !w.WorkoutDate.Equal(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("goto 2026-03-04 selected %s on %s, want a workout from the 3rd", w.ShortDescription, w.WorkoutDate)
+	}
+	h.golden("workout_list_goto")
+}
+
+func TestPaletteGotoLoadsOlder(t *testing.T) {
+	s := trainingLogFixture()
+	s.addWorkout(testUserID, "Hill repeats", time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC))
+	m := NewWorkoutModel(s, testUserID, testPrefs, 4, DefaultConfig())
+	staticCursor(&m.palette.Cursor)
+	h := newHarness(t, m)
+
+	h.clearMsgs()
+	h.keys(":", "goto 2026-02-21", "enter")
+	h.expectMsgs("ui.workoutsLoadedMsg")
+	if w := selectedWorkout(t, h); w.ShortDescription != "Hill repeats" {
+		t.Errorf("goto 2026-02-21 selected %s, want Hill repeats", w.ShortDescription)
+	}
+
+	//the list keeps reaching back that far when it reloads
+	h.keys("x")
+	if n := len(h.model.(WorkoutModel).workouts); n != 6 {
+		t.Errorf("after a delete the list holds %d workouts, want the other 6", n)
+	}
+}
+
+func TestPaletteStats(t *testing.T) {
+	h := newWorkoutHarness(t, workoutFixture())
+
+	h.clearMsgs()
+	h.keys(":", "stats", "enter")
+	h.expectMsgs("ui.loadLoadedMsg")
+	if view := h.model.View(); !strings.Contains(view, "Training Load") {
+		t.Errorf("stats didn't show the training load:\n%s", view)
+	}
+}
+
+func TestPaletteUser(t *testing.T) {
+	h := newWorkoutHarness(t, workoutFixture())
+
+	h.clearMsgs()
+	h.keys(":", "user bob", "enter")
+	h.expectMsgs("ui.switchUserMsg")
+
+	//the root model looks bob up, and a miss comes back to the palette
+	h.send(findUserCmd(newFakeStore(), "bob")())
+	if view := h.model.View(); !strings.Contains(view, "there's no user called bob") {
+		t.Errorf("the palette doesn't show the failed lookup:\n%s", view)
+	}
+
+	h.clearMsgs()
+	h.keys("backspace", "enter")
+	h.expectMsgs("ui.switchUserMsg")
+
+	h.clearMsgs()
+	h.keys("esc", ":", "user", "enter")
+	h.expectMsgs("ui.showUsersMsg")
+}
+
+func TestFindUser(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	bob := s.addUser("Bob")
+
+	msg, ok := findUserCmd(s, "bob")().(userSelectedMsg)
+	if !ok || msg.user.ID != bob.ID {
+		t.Errorf("finding bob returned %#v, want Bob selected", msg)
+	}
+
+	if _, ok := findUserCmd(s, "carol")().(paletteErrorMsg); !ok {
+		t.Error("finding carol didn't report that there's no such user")
+	}
+}
@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Palette is the handful of colors every style is built from. values are anything lipgloss takes as
+// a color: hex ("#EE6FF8") or an ANSI number ("62")
+type Palette struct {
+	Text            string `yaml:"text"`
+	Muted           string `yaml:"muted"`        // dates, details and help
+	Accent          string `yaml:"accent"`       // the row under the cursor
+	AccentMuted     string `yaml:"accent_muted"` // the description under the selected row
+	TitleText       string `yaml:"title_text"`
+	TitleBackground string `yaml:"title_background"`
+	Error           string `yaml:"error"`
+	Warning         string `yaml:"warning"`
+}
+
+// the presets. these are the colors bubbles' lists use out of the box
+var (
+	darkPalette = Palette{
+		Text:            "#dddddd",
+		Muted:           "#777777",
+		Accent:          "#EE6FF8",
+		AccentMuted:     "#AD58B4",
+		TitleText:       "230",
+		TitleBackground: "62",
+		Error:           "#FF5F87",
+		Warning:         "#FFAF00",
+	}
+	lightPalette = Palette{
+		Text:            "#1a1a1a",
+		Muted:           "#A49FA5",
+		Accent:          "#EE6FF8",
+		AccentMuted:     "#F793FF",
+		TitleText:       "230",
+		TitleBackground: "62",
+		Error:           "#D70000",
+		Warning:         "#AF8700",
+	}
+)
+
+// the theme names the config file takes
+const (
+	ThemeAuto  = "auto" // dark or light to suit the terminal's background
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// Theme holds the lipgloss styles the models draw with
+type Theme struct {
+	Title    lipgloss.Style // list titles
+	Heading  lipgloss.Style // week headers and the detail view's title
+	Text     lipgloss.Style
+	Muted    lipgloss.Style
+	Selected lipgloss.Style // the cursor on the grouped workout list
+	Error    lipgloss.Style
+	Warning  lipgloss.Style
+
+	delegate list.DefaultDelegate // for the plain lists
+	list     list.Styles
+	help     help.Styles
+	markdown string // glamour's style for workout notes
+
+	//the colors themselves, for what's drawn without lipgloss
+	preset      string
+	light, dark Palette
+	noColor     bool
+}
+
+// NewTheme builds the named preset (auto, dark or light) with any colors overrides sets.
+// noColor drops every color, for NO_COLOR, leaving just bold and the like
+func NewTheme(name string, overrides Palette, noColor bool) (Theme, error) {
+	var color func(light, dark string) lipgloss.TerminalColor
+	var markdown string
+
+	switch name {
+	case ThemeAuto, "":
+		color = func(light, dark string) lipgloss.TerminalColor {
+			return lipgloss.AdaptiveColor{Light: light, Dark: dark}
+		}
+	case ThemeDark:
+		color = func(light, dark string) lipgloss.TerminalColor { return lipgloss.Color(dark) }
+		markdown = styles.DarkStyle
+	case ThemeLight:
+		color = func(light, dark string) lipgloss.TerminalColor { return lipgloss.Color(light) }
+		markdown = styles.LightStyle
+	default:
+		return Theme{}, fmt.Errorf("unknown theme %q, the themes are %s, %s and %s", name, ThemeAuto, ThemeDark, ThemeLight)
+	}
+
+	if noColor {
+		color = func(light, dark string) lipgloss.TerminalColor { return lipgloss.NoColor{} }
+		markdown = styles.NoTTYStyle
+	}
+
+	light, dark := lightPalette.with(overrides), darkPalette.with(overrides)
+	var (
+		text        = color(light.Text, dark.Text)
+		muted       = color(light.Muted, dark.Muted)
+		accent      = color(light.Accent, dark.Accent)
+		accentMuted = color(light.AccentMuted, dark.AccentMuted)
+		titleText   = color(light.TitleText, dark.TitleText)
+		titleBg     = color(light.TitleBackground, dark.TitleBackground)
+	)
+
+	t := Theme{
+		Title:    lipgloss.NewStyle().Foreground(titleText).Background(titleBg).Padding(0, 1),
+		Heading:  lipgloss.NewStyle().Bold(true),
+		Text:     lipgloss.NewStyle().Foreground(text),
+		Muted:    lipgloss.NewStyle().Foreground(muted),
+		Selected: lipgloss.NewStyle().Foreground(accent),
+		Error:    lipgloss.NewStyle().Foreground(color(light.Error, dark.Error)),
+		Warning:  lipgloss.NewStyle().Foreground(color(light.Warning, dark.Warning)),
+		markdown: markdown,
+		preset:   name,
+		light:    light,
+		dark:     dark,
+		noColor:  noColor,
+	}
+
+	t.list = list.DefaultStyles()
+	t.list.Title = t.Title
+	t.list.StatusBar = t.list.StatusBar.Foreground(muted)
+	t.list.StatusEmpty = t.Muted
+	t.list.NoItems = t.Muted
+	t.list.FilterPrompt = t.list.FilterPrompt.Foreground(accent)
+	t.list.FilterCursor = t.list.FilterCursor.Foreground(accent)
+	t.list.ActivePaginationDot = t.list.ActivePaginationDot.Foreground(text)
+	t.list.InactivePaginationDot = t.list.InactivePaginationDot.Foreground(muted)
+
+	t.help = help.New().Styles
+	t.help.ShortKey = t.help.ShortKey.Foreground(muted)
+	t.help.ShortDesc = t.help.ShortDesc.Foreground(muted)
+	t.help.ShortSeparator = t.help.ShortSeparator.Foreground(muted)
+	t.help.FullKey = t.help.FullKey.Foreground(muted)
+	t.help.FullDesc = t.help.FullDesc.Foreground(muted)
+	t.help.FullSeparator = t.help.FullSeparator.Foreground(muted)
+	t.help.Ellipsis = t.help.Ellipsis.Foreground(muted)
+	t.list.HelpStyle = t.list.HelpStyle.Inherit(t.help.ShortDesc)
+
+	t.delegate = list.NewDefaultDelegate()
+	s := &t.delegate.Styles
+	s.NormalTitle = s.NormalTitle.Foreground(text)
+	s.NormalDesc = s.NormalDesc.Foreground(muted)
+	s.SelectedTitle = s.SelectedTitle.Foreground(accent).BorderForeground(accent)
+	s.SelectedDesc = s.SelectedDesc.Foreground(accentMuted).BorderForeground(accent)
+	s.DimmedTitle = s.DimmedTitle.Foreground(muted)
+	s.DimmedDesc = s.DimmedDesc.Foreground(muted)
+	s.FilterMatch = s.FilterMatch.Underline(true)
+
+	return t, nil
+}
+
+// DefaultTheme is the auto preset
+func DefaultTheme() Theme {
+	t, _ := NewTheme(ThemeAuto, Palette{}, false)
+	return t
+}
+
+// the palette with every color overrides sets replaced
+func (p Palette) with(overrides Palette) Palette {
+	for _, f := range []struct{ dst, src *string }{
+		{&p.Text, &overrides.Text},
+		{&p.Muted, &overrides.Muted},
+		{&p.Accent, &overrides.Accent},
+		{&p.AccentMuted, &overrides.AccentMuted},
+		{&p.TitleText, &overrides.TitleText},
+		{&p.TitleBackground, &overrides.TitleBackground},
+		{&p.Error, &overrides.Error},
+		{&p.Warning, &overrides.Warning},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+
+	return p
+}
+
+// newList makes a bubbles list drawn in the theme and driven by keys, with extra added to its help
+func newList(title string, delegate list.ItemDelegate, theme Theme, keys KeyMap, extra ...key.Binding) list.Model {
+	if delegate == nil {
+		delegate = theme.delegate
+	}
+
+	l := list.New([]list.Item{}, delegate, 0, 0)
+	l.Title = title
+	l.Styles = theme.list
+	l.Help.Styles = theme.help
+	keys.applyToList(&l, extra...)
+
+	return l
+}
+
+// helpBar renders bindings the way the lists' help does, for the screens that aren't lists
+func (t Theme) helpBar(bindings ...key.Binding) string {
+	h := help.New()
+	h.Styles = t.help
+	return h.ShortHelpView(bindings)
+}
+
+// markdownStyle picks glamour's style for the theme. auto follows the terminal: lipgloss has already
+// worked out the background for the rest of the ui, so asking it again costs nothing
+func (t Theme) markdownStyle() string {
+	switch {
+	case lipgloss.ColorProfile() == termenv.Ascii:
+		return styles.NoTTYStyle
+	case t.markdown != "":
+		return t.markdown
+	case lipgloss.HasDarkBackground():
+		return styles.DarkStyle
+	default:
+		return styles.LightStyle
+	}
+}
+
+// colors is the palette the theme draws with on this terminal
+func (t Theme) colors() Palette {
+	switch {
+	case t.preset == ThemeDark:
+		return t.dark
+	case t.preset == ThemeLight:
+		return t.light
+	case lipgloss.HasDarkBackground():
+		return t.dark
+	default:
+		return t.light
+	}
+}
+
+// progressBar is a bubbles progress bar width wide, filled with fill (one of colors()) on muted
+func (t Theme) progressBar(width int, fill string) progress.Model {
+	profile := lipgloss.ColorProfile()
+	if t.noColor {
+		profile = termenv.Ascii
+	}
+
+	bar := progress.New(progress.WithoutPercentage(), progress.WithWidth(width), progress.WithSolidFill(fill), progress.WithColorProfile(profile))
+	bar.EmptyColor = t.colors().Muted
+	return bar
+}
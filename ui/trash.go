@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"context"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ekholme/flexcreek"
+)
+
+// defining the store operations the trash view requires
+type TrashStore interface {
+	ListTrashedUsers(ctx context.Context) ([]*flexcreek.User, error)
+	ListTrashedWorkouts(ctx context.Context, userID int) ([]*flexcreek.Workout, error)
+	RestoreUser(ctx context.Context, id int) error
+	RestoreWorkout(ctx context.Context, id int, userID int) error
+}
+
+// lists deleted users, or a user's deleted workouts, and restores them
+type TrashModel struct {
+	store   TrashStore
+	list    list.Model
+	userID  int //0 when showing deleted users
+	prefs   flexcreek.Preferences
+	loading bool
+	err     error
+	keys    KeyMap
+	theme   Theme
+}
+
+// userID picks whose deleted workouts to show. passing 0 shows deleted users instead
+func NewTrashModel(s TrashStore, userID int, prefs flexcreek.Preferences, cfg Config) TrashModel {
+	title := "Trash: Deleted Workouts"
+	if userID == 0 {
+		title = "Trash: Deleted Users"
+	}
+	l := newList(title, nil, cfg.Theme, cfg.Keys, cfg.Keys.Restore, cfg.Keys.Back)
+
+	return TrashModel{
+		store:   s,
+		list:    l,
+		userID:  userID,
+		prefs:   prefs,
+		loading: true,
+		keys:    cfg.Keys,
+		theme:   cfg.Theme,
+	}
+}
+
+func fetchTrashCmd(s TrashStore, userID int, prefs flexcreek.Preferences) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		if userID == 0 {
+			users, err := s.ListTrashedUsers(ctx)
+			if err != nil {
+				return err
+			}
+
+			items := make([]list.Item, len(users))
+			for i, u := range users {
+				items[i] = trashedUserItem{*u, prefs}
+			}
+			return trashLoadedMsg{items}
+		}
+
+		workouts, err := s.ListTrashedWorkouts(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		items := make([]list.Item, len(workouts))
+		for i, w := range workouts {
+			items[i] = trashedWorkoutItem{*w, prefs}
+		}
+		return trashLoadedMsg{items}
+	}
+}
+
+func restoreCmd(s TrashStore, item list.Item) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		var err error
+		switch i := item.(type) {
+		case trashedUserItem:
+			err = s.RestoreUser(ctx, i.ID)
+		case trashedWorkoutItem:
+			err = s.RestoreWorkout(ctx, i.ID, i.UserID)
+		}
+		if err != nil {
+			return err
+		}
+
+		return itemRestoredMsg{}
+	}
+}
+
+type trashLoadedMsg struct {
+	items []list.Item
+}
+
+type itemRestoredMsg struct{}
+
+type trashedUserItem struct {
+	flexcreek.User
+	prefs flexcreek.Preferences
+}
+
+func (i trashedUserItem) Title() string { return i.Username }
+func (i trashedUserItem) Description() string {
+	return "deleted " + i.prefs.FormatDate(i.DeletedAt.In(i.prefs.Location()))
+}
+func (i trashedUserItem) FilterValue() string { return i.Username }
+
+type trashedWorkoutItem struct {
+	flexcreek.Workout
+	prefs flexcreek.Preferences
+}
+
+func (i trashedWorkoutItem) Title() string { return i.ShortDescription }
+func (i trashedWorkoutItem) Description() string {
+	return i.prefs.FormatDate(i.WorkoutDate) + " · deleted " + i.prefs.FormatDate(i.DeletedAt.In(i.prefs.Location()))
+}
+func (i trashedWorkoutItem) FilterValue() string { return i.ShortDescription }
+
+func (m TrashModel) Init() tea.Cmd {
+	return fetchTrashCmd(m.store, m.userID, m.prefs)
+}
+
+func (m TrashModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case error:
+		m.loading = false
+		m.err = msg
+		return m, nil
+
+	case trashLoadedMsg:
+		m.loading = false
+		m.list.SetItems(msg.items)
+		return m, nil
+
+	case itemRestoredMsg:
+		return m, fetchTrashCmd(m.store, m.userID, m.prefs)
+
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, msg.Height)
+
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			if m.userID == 0 {
+				return m, func() tea.Msg { return showUsersMsg{} }
+			}
+			return m, func() tea.Msg { return showWorkoutsMsg{} }
+
+		case key.Matches(msg, m.keys.Restore):
+			if item := m.list.SelectedItem(); item != nil {
+				return m, restoreCmd(m.store, item)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m TrashModel) View() string {
+	if m.err != nil {
+		return m.theme.Error.Render("Error: " + m.err.Error())
+	}
+
+	if m.loading {
+		return " Loading trash..."
+	}
+
+	return "\n" + m.list.View()
+}
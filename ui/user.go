@@ -2,6 +2,9 @@ package ui
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -43,23 +46,19 @@ type UserModel struct {
 	loading  bool
 	err      error
 	selected *flexcreek.User
+	keys     KeyMap
+	theme    Theme
 }
 
 // constructor for usermodel
-func NewUserModel(s UserStore) UserModel {
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	l.Title = "Select a User"
-
-	//add an entry in the help keybinds to create a new user
-	var createUserKey = key.NewBinding(
-		key.WithKeys("n"),
-		key.WithHelp("n", "new user"),
+func NewUserModel(s UserStore, cfg Config) UserModel {
+	//add entries in the help keybinds for the user actions
+	keys := cfg.Keys
+	l := newList("Select a User", nil, cfg.Theme, keys,
+		relabel(keys.New, "new user"),
+		relabel(keys.Delete, "delete user"),
+		keys.Trash,
 	)
-	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			createUserKey,
-		}
-	}
 
 	//text input stuff
 	ti := textinput.New()
@@ -72,6 +71,8 @@ func NewUserModel(s UserStore) UserModel {
 		input:   ti,
 		state:   stateUserList,
 		loading: true,
+		keys:    keys,
+		theme:   cfg.Theme,
 	}
 }
 
@@ -89,12 +90,34 @@ func fetchUsersCmd(s UserStore) tea.Cmd {
 	}
 }
 
+// a command to find a user by name for the palette's user command. a miss goes back to the palette
+func findUserCmd(s UserStore, username string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		users, err := s.GetAllUsers(ctx)
+		if err != nil {
+			return paletteErrorMsg{err}
+		}
+
+		for _, u := range users {
+			if strings.EqualFold(u.Username, username) {
+				return userSelectedMsg{u}
+			}
+		}
+
+		return paletteErrorMsg{fmt.Errorf("there's no user called %s", username)}
+	}
+}
+
 // a command to create a new user
 func createUserCmd(s UserStore, username string) tea.Cmd {
 
 	return func() tea.Msg {
 		ctx := context.Background()
 		_, err := s.CreateUser(ctx, username)
+		if errors.Is(err, flexcreek.ErrNameInTrash) {
+			return fmt.Errorf("can't create %s: %w", username, flexcreek.ErrNameInTrash)
+		}
 		if err != nil {
 			return err
 		}
@@ -103,6 +126,18 @@ func createUserCmd(s UserStore, username string) tea.Cmd {
 	}
 }
 
+// a command to move a user to the trash
+func deleteUserCmd(s UserStore, id int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := s.DeleteUser(ctx, id); err != nil {
+			return err
+		}
+
+		return userDeletedMsg{}
+	}
+}
+
 type usersLoadedMsg struct {
 	users []*flexcreek.User
 }
@@ -114,6 +149,8 @@ type userSelectedMsg struct {
 type userCreatedMsg struct {
 }
 
+type userDeletedMsg struct{}
+
 type userItem struct {
 	flexcreek.User
 }
@@ -150,6 +187,9 @@ func (m UserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.input.Reset()
 		return m, fetchUsersCmd(m.store)
 
+	case userDeletedMsg:
+		return m, fetchUsersCmd(m.store)
+
 	case tea.WindowSizeMsg:
 		switch m.state {
 		case stateUserList:
@@ -170,14 +210,14 @@ func (m UserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m UserModel) View() string {
 	if m.err != nil {
-		return "Error: " + m.err.Error()
+		return m.theme.Error.Render("Error: " + m.err.Error())
 	}
 
 	switch m.state {
 	case stateCreateUser:
 		return "\n Create New User \n\n" +
 			m.input.View() +
-			"\n\n (esc to go back)"
+			"\n\n " + m.theme.helpBar(relabel(m.keys.Submit, "create"), m.keys.Back)
 
 	default:
 		if m.loading {
@@ -201,13 +241,21 @@ func (m UserModel) updateUserList(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
-		switch msg.String() {
-		case "n":
+		switch {
+		case key.Matches(msg, m.keys.New):
 			m.state = stateCreateUser
 			m.input.Focus()
 			return m, nil
 
-		case "enter":
+		case key.Matches(msg, m.keys.Delete):
+			if i, ok := m.list.SelectedItem().(userItem); ok {
+				return m, deleteUserCmd(m.store, i.ID)
+			}
+
+		case key.Matches(msg, m.keys.Trash):
+			return m, func() tea.Msg { return showTrashMsg{} }
+
+		case key.Matches(msg, m.keys.Select):
 			if i, ok := m.list.SelectedItem().(userItem); ok {
 				m.selected = &i.User
 				return m, func() tea.Msg { return userSelectedMsg{&i.User} }
@@ -225,14 +273,14 @@ func (m UserModel) updateUserForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.state = stateUserList
 			m.input.Blur()
 			m.input.Reset()
 			return m, nil
 
-		case "enter":
+		case key.Matches(msg, m.keys.Submit):
 			username := m.input.Value()
 			if username != "" {
 				m.state = stateUserList
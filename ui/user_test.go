@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+)
+
+func newUserHarness(t *testing.T, s *fakeStore) *harness {
+	t.Helper()
+
+	m := NewUserModel(s, DefaultConfig())
+	staticCursor(&m.input.Cursor)
+	staticCursor(&m.list.FilterInput.Cursor)
+
+	return newHarness(t, m)
+}
+
+func TestUserListLoads(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	s.addUser("bob")
+
+	h := newUserHarness(t, s)
+	h.expectMsgs("ui.usersLoadedMsg")
+	h.golden("user_list")
+}
+
+func TestUserListLoading(t *testing.T) {
+	//before the users arrive
+	m := NewUserModel(newFakeStore(), DefaultConfig())
+	if got := m.View(); got != " Loading users..." {
+		t.Errorf("view while loading = %q", got)
+	}
+}
+
+func TestUserCreate(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	h := newUserHarness(t, s)
+
+	h.keys("n")
+	h.golden("user_create_form")
+
+	h.clearMsgs()
+	h.keys("carol", "enter")
+	h.expectMsgs("ui.userCreatedMsg", "ui.usersLoadedMsg")
+	h.golden("user_list_after_create")
+
+	if len(s.users) != 2 || s.users[1].Username != "carol" {
+		t.Errorf("the store has users %v after creating carol", s.users)
+	}
+}
+
+func TestUserCreateEmptyName(t *testing.T) {
+	s := newFakeStore()
+	h := newUserHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("n", "enter")
+	h.expectMsgs()
+
+	if m := h.model.(UserModel); m.state != stateCreateUser {
+		t.Error("submitting an empty username left the form")
+	}
+	if len(s.users) != 0 {
+		t.Errorf("a user was created without a name: %v", s.users)
+	}
+}
+
+func TestUserCreateBack(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	h := newUserHarness(t, s)
+
+	before := h.model.View()
+	h.keys("n", "half typed", "esc")
+	if after := h.model.View(); after != before {
+		t.Errorf("esc from the form didn't go back to the list\n--- got\n%s\n--- want\n%s", after, before)
+	}
+
+	//the form starts empty next time
+	h.keys("n")
+	if v := h.model.(UserModel).input.Value(); v != "" {
+		t.Errorf("the form reopened with %q in it", v)
+	}
+}
+
+func TestUserSelect(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	bob := s.addUser("bob")
+	h := newUserHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("down", "enter")
+	h.expectMsgs("ui.userSelectedMsg")
+
+	if got := h.msgs[0].(userSelectedMsg).user; got.ID != bob.ID {
+		t.Errorf("selected user %d, want bob (%d)", got.ID, bob.ID)
+	}
+}
+
+func TestUserSelectWhileFiltering(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	s.addUser("bob")
+	h := newUserHarness(t, s)
+
+	h.keys("/", "b")
+	h.golden("user_list_filtering")
+
+	//enter accepts the filter rather than picking a user
+	h.clearMsgs()
+	h.keys("enter")
+	for _, msg := range h.msgs {
+		if _, ok := msg.(userSelectedMsg); ok {
+			t.Fatal("enter picked a user while the filter was being typed")
+		}
+	}
+
+	h.clearMsgs()
+	h.keys("enter")
+	h.expectMsgs("ui.userSelectedMsg")
+	if got := h.msgs[0].(userSelectedMsg).user.Username; got != "bob" {
+		t.Errorf("selected %s from the filtered list, want bob", got)
+	}
+}
+
+func TestUserDelete(t *testing.T) {
+	s := newFakeStore()
+	s.addUser("alice")
+	s.addUser("bob")
+	h := newUserHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("x")
+	h.expectMsgs("ui.userDeletedMsg", "ui.usersLoadedMsg")
+	h.golden("user_list_after_delete")
+}
+
+func TestUserTrash(t *testing.T) {
+	h := newUserHarness(t, newFakeStore())
+
+	h.clearMsgs()
+	h.keys("T")
+	h.expectMsgs("ui.showTrashMsg")
+}
+
+func TestUserLoadError(t *testing.T) {
+	s := newFakeStore()
+	s.fail["GetAllUsers"] = errors.New("database is locked")
+
+	h := newUserHarness(t, s)
+	h.expectMsgs("*errors.errorString")
+	h.golden("user_load_error")
+}
+
+func TestUserCreateError(t *testing.T) {
+	s := newFakeStore()
+	s.fail["CreateUser"] = errors.New("username already taken")
+	h := newUserHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("n", "alice", "enter")
+	h.expectMsgs("*errors.errorString")
+	h.golden("user_create_error")
+}
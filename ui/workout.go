@@ -2,6 +2,10 @@ package ui
 
 import (
 	"context"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -10,38 +14,68 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/ekholme/flexcreek"
+	"github.com/ekholme/flexcreek/editor"
+	"github.com/ekholme/flexcreek/load"
 )
 
 const (
 	stateWorkoutList sessionState = iota
 	stateCreateWorkout
 	stateViewWorkout
+	stateViewLoad
+	stateWorkoutHistory
+	stateCommandPalette
+)
+
+// the create form's inputs, in focus order
+const (
+	inputShortDescription = iota
+	inputLongDescription
+	inputWorkoutDate
+	inputDuration
+	inputRPE
+	inputSessionLoad
+	numWorkoutInputs
 )
 
 // defining interaces that the workout model requires
 type WorkoutProvider interface {
 	GetLatestWorkouts(ctx context.Context, n int, userID int) ([]*flexcreek.Workout, error)
 	GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error)
+	GetWorkoutsBetween(ctx context.Context, start time.Time, end time.Time, userID int) ([]*flexcreek.Workout, error)
 }
 
 type WorkoutCreator interface {
 	CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error)
 }
 
+type WorkoutUpdater interface {
+	UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error
+}
+
 type WorkoutDeleter interface {
-	DeleteWorkout(ctx context.Context, id int) error
+	DeleteWorkout(ctx context.Context, id int, userID int) error
+}
+
+type WorkoutHistoryProvider interface {
+	ListWorkoutRevisions(ctx context.Context, workoutID int, userID int) ([]*flexcreek.WorkoutRevision, error)
 }
 
 type WorkoutStore interface {
 	WorkoutProvider
 	WorkoutCreator
+	WorkoutUpdater
 	WorkoutDeleter
+	WorkoutHistoryProvider
 }
 
 type WorkoutModelInputs struct {
 	ShortDescriptionInput textinput.Model
 	LongDescriptionInput  textarea.Model
 	WorkoutDateInput      textinput.Model
+	DurationInput         textinput.Model
+	RPEInput              textinput.Model
+	SessionLoadInput      textinput.Model
 }
 
 // handles all interactions with the workout model
@@ -50,28 +84,46 @@ type WorkoutModel struct {
 	list            list.Model
 	inputs          WorkoutModelInputs
 	inputFocusIndex int
+	formErr         error // why the form couldn't be saved, until it's edited
 	state           sessionState
 	loading         bool
 	err             error
 	selectedUserID  int //i think this is the right way to handle this for now?
+	prefs           flexcreek.Preferences
 	listLength      int
 	selectedWorkout *flexcreek.Workout
+	detail          workoutDetail
+	loadDays        []load.Day
+	revisions       []*flexcreek.WorkoutRevision
+	revisionIndex   int
+	workouts        []*flexcreek.Workout // what the list was last built from
+	collapsed       map[time.Time]bool   // weeks folded down to their header, by week start
+	from            time.Time            // how far back goto has taken the list, zero for just the latest
+	jumpTo          time.Time            // the day goto is waiting on a load to select
+	palette         textinput.Model
+	paletteErr      error
+	keys            KeyMap
+	theme           Theme
 }
 
-func NewWorkoutModel(s WorkoutStore, userID int, listLength int) WorkoutModel {
-	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	l.Title = "Select a Workout"
-
-	//add an entry in the help keybinds to create a new workout
-	var createWorkoutKey = key.NewBinding(
-		key.WithKeys("n"),
-		key.WithHelp("n", "new workout"),
+func NewWorkoutModel(s WorkoutStore, userID int, prefs flexcreek.Preferences, listLength int, cfg Config) WorkoutModel {
+	//add entries in the help keybinds for the workout actions
+	keys := cfg.Keys
+	l := newList("Select a Workout", workoutDelegate{cfg.Theme}, cfg.Theme, keys,
+		relabel(keys.New, "new workout"),
+		keys.Duplicate,
+		keys.Palette,
+		keys.FoldWeek,
+		keys.TrainingLoad,
+		keys.Measurements,
+		keys.Goals,
+		keys.Delete,
+		keys.Trash,
+		keys.SwitchUser,
 	)
-	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			createWorkoutKey,
-		}
-	}
+	//the week headers carry the counts, and the status bar would count the headers too
+	l.SetShowStatusBar(false)
+	l.SetStatusBarItemName("workout", "workouts")
 
 	//short description init
 	sdi := textinput.New()
@@ -81,15 +133,33 @@ func NewWorkoutModel(s WorkoutStore, userID int, listLength int) WorkoutModel {
 	//long description init
 	ldi := textarea.New()
 	ldi.Placeholder = "Long Description (e.g. 20 min AMRAP...)"
+	//notes written in $EDITOR can run well past the textarea's usual limit
+	ldi.MaxHeight = 0
 
 	wdi := textinput.New()
-	wdi.Placeholder = "Workout Date (YYYY-MM-DD)"
-	wdi.CharLimit = 10
+	wdi.Placeholder = "Workout Date (e.g. " + prefs.FormatDate(prefs.Today()) + ")"
+	wdi.CharLimit = 12
+
+	//session load inputs -- load can be entered directly or derived from duration x RPE
+	di := textinput.New()
+	di.Placeholder = "Duration in minutes (optional)"
+	di.CharLimit = 4
+
+	ri := textinput.New()
+	ri.Placeholder = "RPE 1-10 (optional)"
+	ri.CharLimit = 2
+
+	sli := textinput.New()
+	sli.Placeholder = "Session load (optional, defaults to duration x RPE)"
+	sli.CharLimit = 6
 
 	wmi := WorkoutModelInputs{
 		ShortDescriptionInput: sdi,
 		LongDescriptionInput:  ldi,
 		WorkoutDateInput:      wdi,
+		DurationInput:         di,
+		RPEInput:              ri,
+		SessionLoadInput:      sli,
 	}
 
 	return WorkoutModel{
@@ -99,21 +169,47 @@ func NewWorkoutModel(s WorkoutStore, userID int, listLength int) WorkoutModel {
 		state:          stateWorkoutList,
 		loading:        true,
 		selectedUserID: userID,
+		prefs:          prefs,
 		listLength:     listLength,
+		collapsed:      map[time.Time]bool{},
+		palette:        newPaletteInput(),
+		detail:         newWorkoutDetail(prefs, cfg),
+		keys:           keys,
+		theme:          cfg.Theme,
 	}
 }
 
 // a command to fetch the latest workouts for a given user from the database
 // again, this is wrapped in a command so it's non-blocking
-func fetchLatestWorkoutsCmd(s WorkoutStore, n int, userID int) tea.Cmd {
+// the oldest week is filled out past n, so every week's totals in the list are whole, and from (if
+// it's set) takes the list back to that day's week however many workouts that is
+func fetchLatestWorkoutsCmd(s WorkoutStore, n int, userID int, prefs flexcreek.Preferences, from time.Time) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		workouts, err := s.GetLatestWorkouts(ctx, n, userID)
 		if err != nil {
 			return err
 		}
+		if len(workouts) == 0 {
+			return workoutsLoadedMsg{workouts}
+		}
 
-		return workoutsLoadedMsg{workouts}
+		newest := workouts[0].WorkoutDate
+		oldest := workouts[len(workouts)-1].WorkoutDate
+		if !from.IsZero() && from.Before(oldest) {
+			oldest = from
+		}
+		week, err := s.GetWorkoutsBetween(ctx, prefs.StartOfWeek(oldest), newest, userID)
+		if err != nil {
+			return err
+		}
+
+		//between comes back oldest first
+		for i, j := 0, len(week)-1; i < j; i, j = i+1, j-1 {
+			week[i], week[j] = week[j], week[i]
+		}
+
+		return workoutsLoadedMsg{week}
 	}
 }
 
@@ -129,6 +225,40 @@ func createWorkoutCmd(s WorkoutStore, w *flexcreek.Workout) tea.Cmd {
 	}
 }
 
+// suspends the TUI to write the notes in $EDITOR, then hands back whatever was saved
+func editNotesCmd(notes string) tea.Cmd {
+	path, err := editor.NewFile(notes)
+	if err != nil {
+		return func() tea.Msg { return err }
+	}
+
+	return tea.ExecProcess(editor.Command(path), func(err error) tea.Msg {
+		if err != nil {
+			os.Remove(path)
+			return err
+		}
+
+		notes, err := editor.Read(path)
+		if err != nil {
+			return err
+		}
+
+		return notesEditedMsg{notes}
+	})
+}
+
+// a command to move a workout to the trash
+func deleteWorkoutCmd(s WorkoutStore, id int, userID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := s.DeleteWorkout(ctx, id, userID); err != nil {
+			return err
+		}
+
+		return workoutDeletedMsg{}
+	}
+}
+
 // struct wrappers for messages
 type workoutsLoadedMsg struct {
 	workouts []*flexcreek.Workout
@@ -141,17 +271,39 @@ type workoutSelectedMsg struct {
 type workoutCreatedMsg struct {
 }
 
+type workoutDeletedMsg struct{}
+
+type notesEditedMsg struct {
+	notes string
+}
+
 type workoutItem struct {
 	flexcreek.Workout
+	prefs flexcreek.Preferences
 }
 
 func (i workoutItem) Title() string       { return i.ShortDescription }
-func (i workoutItem) Description() string { return i.WorkoutDate.Format("2006-01-02") }
-func (i workoutItem) FilterValue() string { return i.LongDescription }
+func (i workoutItem) FilterValue() string { return i.ShortDescription + " " + i.LongDescription }
+
+// the row's details: the date sits in the day header above it, so this is the start time and load inputs
+func (i workoutItem) Description() string {
+	var details []string
+	if !i.StartTime.IsZero() {
+		details = append(details, i.StartTime.In(i.prefs.Location()).Format("15:04"))
+	}
+	if i.DurationMinutes > 0 {
+		details = append(details, strconv.Itoa(i.DurationMinutes)+" min")
+	}
+	if i.RPE > 0 {
+		details = append(details, "RPE "+strconv.Itoa(i.RPE))
+	}
+
+	return strings.Join(details, " · ")
+}
 
 // bubbletea model requirements
 func (m WorkoutModel) Init() tea.Cmd {
-	return fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID)
+	return fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID, m.prefs, m.from)
 }
 
 func (m WorkoutModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -165,22 +317,64 @@ func (m WorkoutModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case workoutsLoadedMsg:
 		m.loading = false
-		items := make([]list.Item, len(msg.workouts))
-		for i, w := range msg.workouts {
-			items[i] = workoutItem{*w}
+		m.workouts = msg.workouts
+		m.list.SetItems(groupWorkouts(m.workouts, m.prefs, m.collapsed))
+		switch {
+		case !m.jumpTo.IsZero():
+			m = m.selectDay(m.jumpTo)
+			m.jumpTo = time.Time{}
+		//start on the newest workout rather than its week's header
+		case m.list.FilterState() == list.Unfiltered && m.list.Index() == 0 && len(m.list.Items()) > 2:
+			m.list.Select(2)
 		}
-		m.list.SetItems(items)
 
 	case workoutCreatedMsg:
 		// Reset form and go back to list
 		m.state = stateWorkoutList
 		m.loading = true
-		m.inputs.ShortDescriptionInput.Reset()
-		m.inputs.LongDescriptionInput.Reset()
-		m.inputs.WorkoutDateInput.Reset()
-		return m, fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID)
+		m.resetForm()
+		return m, fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID, m.prefs, m.from)
+
+	case notesEditedMsg:
+		//editors end the file with a newline, which isn't part of the notes
+		m.inputs.LongDescriptionInput.SetValue(strings.TrimRight(msg.notes, "\n"))
+		return m, nil
+
+	case paletteErrorMsg:
+		m.paletteErr = msg.err
+		return m, nil
+
+	case workoutDeletedMsg:
+		return m, fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID, m.prefs, m.from)
+
+	case revisionsLoadedMsg:
+		//a late answer for a workout the user has already moved on from
+		if m.selectedWorkout == nil || msg.workoutID != m.selectedWorkout.ID {
+			return m, nil
+		}
+		m.loading = false
+		m.revisions = msg.revisions
+		m.revisionIndex = 0
+		if len(msg.revisions) > 0 {
+			m.detail.setLastEdited(msg.revisions[0].ChangedAt)
+		}
+
+	case workoutRevertedMsg:
+		m.selectedWorkout = msg.workout
+		m.detail.setWorkout(msg.workout)
+		return m, tea.Batch(
+			fetchRevisionsCmd(m.store, msg.workout.ID, m.selectedUserID),
+			fetchLatestWorkoutsCmd(m.store, m.listLength, m.selectedUserID, m.prefs, m.from),
+		)
+
+	case loadLoadedMsg:
+		m.loading = false
+		m.loadDays = msg.days
 
 	case tea.WindowSizeMsg:
+		m.detail.setSize(msg.Width, msg.Height)
+		//the palette's line sits after a space and its ": " prompt, with a column left for the cursor
+		m.palette.Width = max(msg.Width-4, 0)
 		switch m.state {
 		case stateWorkoutList:
 			return m.updateWorkoutList(msg)
@@ -196,6 +390,12 @@ func (m WorkoutModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateWorkoutForm(msg)
 		case stateViewWorkout:
 			return m.updateViewWorkout(msg)
+		case stateViewLoad:
+			return m.updateViewLoad(msg)
+		case stateWorkoutHistory:
+			return m.updateWorkoutHistory(msg)
+		case stateCommandPalette:
+			return m.updatePalette(msg)
 		}
 
 	}
@@ -205,7 +405,7 @@ func (m WorkoutModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m WorkoutModel) View() string {
 	if m.err != nil {
-		return "Error: " + m.err.Error()
+		return m.theme.Error.Render("Error: " + m.err.Error())
 	}
 
 	switch m.state {
@@ -213,13 +413,17 @@ func (m WorkoutModel) View() string {
 		return m.viewWorkoutForm()
 
 	case stateViewWorkout:
-		if m.selectedWorkout == nil {
-			return "Error: No workout selected."
-		}
-		return "\n" + m.selectedWorkout.ShortDescription + "\n\n" +
-			"Date: " + m.selectedWorkout.WorkoutDate.Format("2006-01-02") + "\n\n" +
-			m.selectedWorkout.LongDescription + "\n\n" +
-			"(esc to go back)"
+		return m.detail.view()
+
+	case stateViewLoad:
+		return m.viewLoad()
+
+	case stateWorkoutHistory:
+		return m.viewWorkoutHistory()
+
+	case stateCommandPalette:
+		return m.viewPalette()
+
 	default:
 		if m.loading {
 			return " Loading workouts..."
@@ -235,14 +439,110 @@ func (m WorkoutModel) viewWorkoutForm() string {
 		m.inputs.ShortDescriptionInput.View() + "\n\n" +
 		m.inputs.LongDescriptionInput.View() + "\n\n" +
 		m.inputs.WorkoutDateInput.View() + "\n\n" +
-		"(esc to go back)"
+		m.inputs.DurationInput.View() + "\n" +
+		m.inputs.RPEInput.View() + "\n" +
+		m.inputs.SessionLoadInput.View() + "\n\n" +
+		m.viewFormErr() +
+		m.theme.helpBar(m.keys.NextField, m.keys.Submit, m.keys.EditNotes, m.keys.Back)
+}
+
+// why the last save failed, above the help
+func (m WorkoutModel) viewFormErr() string {
+	if m.formErr == nil {
+		return ""
+	}
+	return " " + m.theme.Error.Render(m.formErr.Error()) + "\n\n"
 }
 
 func (m WorkoutModel) updateViewWorkout(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
-		m.state = stateWorkoutList
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.state = stateWorkoutList
+			return m, nil
+		case key.Matches(msg, m.keys.History):
+			m.state = stateWorkoutHistory
+			m.loading = true
+			return m, fetchRevisionsCmd(m.store, m.selectedWorkout.ID, m.selectedUserID)
+		case key.Matches(msg, m.keys.Duplicate):
+			return m.duplicateWorkout(m.selectedWorkout)
+		case key.Matches(msg, m.keys.NextWorkout):
+			return m.stepWorkout(1)
+		case key.Matches(msg, m.keys.PrevWorkout):
+			return m.stepWorkout(-1)
+		}
+	}
+
+	//everything else scrolls
+	var cmd tea.Cmd
+	m.detail, cmd = m.detail.update(msg)
+	return m, cmd
+}
+
+// shows w in the detail view, fetching its history for the last edited time
+func (m WorkoutModel) openWorkout(w *flexcreek.Workout) (tea.Model, tea.Cmd) {
+	m.state = stateViewWorkout
+	m.selectedWorkout = w
+	m.detail.setWorkout(w)
+
+	return m, tea.Batch(
+		func() tea.Msg { return workoutSelectedMsg{w} },
+		fetchRevisionsCmd(m.store, w.ID, m.selectedUserID),
+	)
+}
+
+// opens the create form filled in with a copy of w dated today, for repeating a session.
+// the form has no start time, so the copy doesn't keep w's
+func (m WorkoutModel) duplicateWorkout(w *flexcreek.Workout) (tea.Model, tea.Cmd) {
+	c := w.CopyTo(m.prefs.Today())
+
+	number := func(n float64) string {
+		if n <= 0 {
+			return ""
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64)
 	}
-	return m, nil
+	m.inputs.ShortDescriptionInput.SetValue(c.ShortDescription)
+	m.inputs.LongDescriptionInput.SetValue(c.LongDescription)
+	m.inputs.WorkoutDateInput.SetValue(m.prefs.FormatDate(c.WorkoutDate))
+	m.inputs.DurationInput.SetValue(number(float64(c.DurationMinutes)))
+	m.inputs.RPEInput.SetValue(number(float64(c.RPE)))
+	//an explicit load is copied, a derived one is left to be worked out again from the copy
+	m.inputs.SessionLoadInput.SetValue(number(c.SessionLoad))
+
+	m.state = stateCreateWorkout
+	m.formErr = nil
+	return m, m.focusInput(inputShortDescription)
+}
+
+// moves the detail view delta workouts down the list (so 1 is the next older one), taking the list's
+// cursor along. a filtered list steps through just the matches; folded weeks are stepped through too
+func (m WorkoutModel) stepWorkout(delta int) (tea.Model, tea.Cmd) {
+	workouts := m.workouts
+	if m.list.FilterState() == list.FilterApplied {
+		workouts = nil
+		for _, item := range m.list.VisibleItems() {
+			if i, ok := item.(workoutItem); ok {
+				workouts = append(workouts, &i.Workout)
+			}
+		}
+	}
+
+	current := slices.IndexFunc(workouts, func(w *flexcreek.Workout) bool { return w.ID == m.selectedWorkout.ID })
+	next := current + delta
+	if current < 0 || next < 0 || next >= len(workouts) {
+		return m, nil
+	}
+	w := *workouts[next]
+
+	for i, item := range m.list.VisibleItems() {
+		if wi, ok := item.(workoutItem); ok && wi.ID == w.ID {
+			m.list.Select(i)
+			break
+		}
+	}
+
+	return m.openWorkout(&w)
 }
 
 // update helpers
@@ -257,17 +557,54 @@ func (m WorkoutModel) updateWorkoutList(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
-		switch msg.String() {
-		case "n":
+		switch {
+		case key.Matches(msg, m.keys.New):
 			m.state = stateCreateWorkout
-			m.inputs.ShortDescriptionInput.Focus()
-			return m, nil
+			m.resetForm()
+			return m, m.focusInput(inputShortDescription)
 
-		case "enter":
+		case key.Matches(msg, m.keys.Duplicate):
 			if i, ok := m.list.SelectedItem().(workoutItem); ok {
-				m.state = stateViewWorkout
-				m.selectedWorkout = &i.Workout
-				return m, func() tea.Msg { return workoutSelectedMsg{&i.Workout} }
+				return m.duplicateWorkout(&i.Workout)
+			}
+
+		case key.Matches(msg, m.keys.Palette):
+			m.state = stateCommandPalette
+			m.paletteErr = nil
+			m.palette.Reset()
+			return m, m.palette.Focus()
+
+		case key.Matches(msg, m.keys.TrainingLoad):
+			m.state = stateViewLoad
+			m.loading = true
+			return m, fetchLoadCmd(m.store, m.prefs.Today(), m.selectedUserID)
+
+		case key.Matches(msg, m.keys.Measurements):
+			return m, func() tea.Msg { return showMeasurementsMsg{} }
+
+		case key.Matches(msg, m.keys.Goals):
+			return m, func() tea.Msg { return showGoalsMsg{} }
+
+		case key.Matches(msg, m.keys.Delete):
+			if i, ok := m.list.SelectedItem().(workoutItem); ok {
+				return m, deleteWorkoutCmd(m.store, i.ID, m.selectedUserID)
+			}
+
+		case key.Matches(msg, m.keys.Trash):
+			return m, func() tea.Msg { return showTrashMsg{} }
+
+		case key.Matches(msg, m.keys.SwitchUser):
+			return m, func() tea.Msg { return showUsersMsg{} }
+
+		case key.Matches(msg, m.keys.FoldWeek):
+			return m.toggleWeek(), nil
+
+		case key.Matches(msg, m.keys.Select):
+			if _, ok := m.list.SelectedItem().(weekItem); ok {
+				return m.toggleWeek(), nil
+			}
+			if i, ok := m.list.SelectedItem().(workoutItem); ok {
+				return m.openWorkout(&i.Workout)
 			}
 		}
 	}
@@ -276,70 +613,93 @@ func (m WorkoutModel) updateWorkoutList(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// folds or unfolds the week the cursor is in, leaving the cursor on its header
+func (m WorkoutModel) toggleWeek() WorkoutModel {
+	//a filtered list shows workouts without their headers, so there's nothing to fold
+	if m.list.FilterState() != list.Unfiltered {
+		return m
+	}
+
+	start, ok := weekOf(m.list.Items(), m.list.Index())
+	if !ok {
+		return m
+	}
+
+	if m.collapsed[start] {
+		delete(m.collapsed, start)
+	} else {
+		m.collapsed[start] = true
+	}
+	m.list.SetItems(groupWorkouts(m.workouts, m.prefs, m.collapsed))
+
+	for i, item := range m.list.Items() {
+		if w, ok := item.(weekItem); ok && w.start.Equal(start) {
+			m.list.Select(i)
+			break
+		}
+	}
+
+	return m
+}
+
 func (m WorkoutModel) updateWorkoutForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
+		//a failed save is forgotten as soon as the form is touched again
+		m.formErr = nil
+
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			//leaving drops what was typed, so the next new workout starts from an empty form
 			m.state = stateWorkoutList
+			m.resetForm()
 			return m, nil
 
-		case "tab", "shift+tab", "enter", "up", "down":
-			s := msg.String()
+		case key.Matches(msg, m.keys.EditNotes):
+			return m, editNotesCmd(m.inputs.LongDescriptionInput.Value())
+
+		case key.Matches(msg, m.keys.NextField, m.keys.PrevField, m.keys.Submit):
+			submit := key.Matches(msg, m.keys.Submit)
 
 			// Did the user press enter while the submit button is focused?
 			// If so, create the workout.
-			if s == "enter" && m.inputFocusIndex == 2 { // 2 is the last input index
-				dateStr := m.inputs.WorkoutDateInput.Value()
-				t, err := time.Parse("2006-01-02", dateStr)
+			if submit && m.inputFocusIndex == numWorkoutInputs-1 {
+				//checked the same way as a workout from $EDITOR or the palette. a blank date is today
+				d := editor.Draft{
+					Title:    m.inputs.ShortDescriptionInput.Value(),
+					Notes:    m.inputs.LongDescriptionInput.Value(),
+					Date:     m.inputs.WorkoutDateInput.Value(),
+					Duration: m.inputs.DurationInput.Value(),
+					RPE:      m.inputs.RPEInput.Value(),
+					Load:     m.inputs.SessionLoadInput.Value(),
+				}
+				w, err := d.Workout(m.selectedUserID, m.prefs)
 				if err != nil {
-					// For now, we'll just use the current time if parsing fails.
-					// A better approach would be to show a validation error to the user.
-					t = time.Now()
+					m.formErr = err
+					return m, nil
 				}
 
-				w := flexcreek.Workout{
-					UserID:           m.selectedUserID,
-					ShortDescription: m.inputs.ShortDescriptionInput.Value(),
-					LongDescription:  m.inputs.LongDescriptionInput.Value(),
-					WorkoutDate:      t,
-				}
 				m.loading = true
-				return m, createWorkoutCmd(m.store, &w)
+				return m, createWorkoutCmd(m.store, w)
 			}
 
 			// Cycle focus
-			if s == "up" || s == "shift+tab" || (s == "enter" && m.inputFocusIndex == 1) { // Special case for textarea
+			if key.Matches(msg, m.keys.PrevField) || (submit && m.inputFocusIndex == inputLongDescription) { // Special case for textarea
 				m.inputFocusIndex--
 			} else {
 				m.inputFocusIndex++
 			}
 
 			// Wrap focus
-			if m.inputFocusIndex > 2 {
+			if m.inputFocusIndex > numWorkoutInputs-1 {
 				m.inputFocusIndex = 0
 			} else if m.inputFocusIndex < 0 {
-				m.inputFocusIndex = 2
+				m.inputFocusIndex = numWorkoutInputs - 1
 			}
 
-			// Blur all inputs
-			m.inputs.ShortDescriptionInput.Blur()
-			m.inputs.LongDescriptionInput.Blur()
-			m.inputs.WorkoutDateInput.Blur()
-
-			// Focus the correct input
-			switch m.inputFocusIndex {
-			case 0:
-				cmd = m.inputs.ShortDescriptionInput.Focus()
-			case 1:
-				cmd = m.inputs.LongDescriptionInput.Focus()
-			case 2:
-				cmd = m.inputs.WorkoutDateInput.Focus()
-			}
-
-			return m, cmd
+			return m, m.focusInput(m.inputFocusIndex)
 		}
 	}
 
@@ -349,16 +709,65 @@ func (m WorkoutModel) updateWorkoutForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// helper to empty the form and put its focus back on the first input
+func (m *WorkoutModel) resetForm() {
+	m.inputs.ShortDescriptionInput.Reset()
+	m.inputs.LongDescriptionInput.Reset()
+	m.inputs.WorkoutDateInput.Reset()
+	m.inputs.DurationInput.Reset()
+	m.inputs.RPEInput.Reset()
+	m.inputs.SessionLoadInput.Reset()
+	m.formErr = nil
+	m.focusInput(inputShortDescription)
+}
+
+// helper to move the form's focus to input i
+func (m *WorkoutModel) focusInput(i int) tea.Cmd {
+	m.inputFocusIndex = i
+
+	// Blur all inputs
+	m.inputs.ShortDescriptionInput.Blur()
+	m.inputs.LongDescriptionInput.Blur()
+	m.inputs.WorkoutDateInput.Blur()
+	m.inputs.DurationInput.Blur()
+	m.inputs.RPEInput.Blur()
+	m.inputs.SessionLoadInput.Blur()
+
+	// Focus the correct input
+	switch i {
+	case inputShortDescription:
+		return m.inputs.ShortDescriptionInput.Focus()
+	case inputLongDescription:
+		return m.inputs.LongDescriptionInput.Focus()
+	case inputWorkoutDate:
+		return m.inputs.WorkoutDateInput.Focus()
+	case inputDuration:
+		return m.inputs.DurationInput.Focus()
+	case inputRPE:
+		return m.inputs.RPEInput.Focus()
+	case inputSessionLoad:
+		return m.inputs.SessionLoadInput.Focus()
+	}
+
+	return nil
+}
+
 // helper to update the currently focused input field
 func (m *WorkoutModel) updateFocusedInput(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	switch m.inputFocusIndex {
-	case 0:
+	case inputShortDescription:
 		m.inputs.ShortDescriptionInput, cmd = m.inputs.ShortDescriptionInput.Update(msg)
-	case 1:
+	case inputLongDescription:
 		m.inputs.LongDescriptionInput, cmd = m.inputs.LongDescriptionInput.Update(msg)
-	case 2:
+	case inputWorkoutDate:
 		m.inputs.WorkoutDateInput, cmd = m.inputs.WorkoutDateInput.Update(msg)
+	case inputDuration:
+		m.inputs.DurationInput, cmd = m.inputs.DurationInput.Update(msg)
+	case inputRPE:
+		m.inputs.RPEInput, cmd = m.inputs.RPEInput.Update(msg)
+	case inputSessionLoad:
+		m.inputs.SessionLoadInput, cmd = m.inputs.SessionLoadInput.Update(msg)
 	}
 	return cmd
 }
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ekholme/flexcreek"
+)
+
+// the workout list reads like a training log: workouts sit under a header for their day, and days
+// under a header for their week with the week's totals. weeks can be collapsed down to their header
+
+// weekItem heads a week of workouts
+type weekItem struct {
+	start     time.Time
+	count     int
+	minutes   int
+	load      float64
+	collapsed bool
+	prefs     flexcreek.Preferences
+}
+
+func (i weekItem) FilterValue() string { return "" }
+
+func (i weekItem) String() string {
+	arrow := "▾"
+	if i.collapsed {
+		arrow = "▸"
+	}
+
+	s := arrow + " Week of " + i.prefs.FormatDate(i.start) + " · " + plural(i.count, "workout")
+	if i.minutes > 0 {
+		s += fmt.Sprintf(" · %d min", i.minutes)
+	}
+	if i.load > 0 {
+		s += fmt.Sprintf(" · load %.0f", i.load)
+	}
+
+	return s
+}
+
+// dayItem heads the workouts done on one day
+type dayItem struct {
+	date  time.Time
+	count int
+	prefs flexcreek.Preferences
+}
+
+func (i dayItem) FilterValue() string { return "" }
+
+func (i dayItem) String() string {
+	s := i.date.Format("Mon") + " " + i.prefs.FormatDate(i.date)
+	if i.count > 1 {
+		s += " · " + plural(i.count, "workout")
+	}
+
+	return s
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// groupWorkouts lays out workouts (newest first) under week and day headers.
+// the workouts of weeks in collapsed are left out, leaving just the header
+func groupWorkouts(workouts []*flexcreek.Workout, prefs flexcreek.Preferences, collapsed map[time.Time]bool) []list.Item {
+	var items []list.Item
+	var week *weekItem
+	var day *dayItem
+
+	for _, w := range workouts {
+		start := prefs.StartOfWeek(w.WorkoutDate)
+		if week == nil || !start.Equal(week.start) {
+			items = append(items, &weekItem{start: start, collapsed: collapsed[start], prefs: prefs})
+			week = items[len(items)-1].(*weekItem)
+			day = nil
+		}
+		week.count++
+		week.minutes += w.DurationMinutes
+		week.load += w.Load()
+
+		if week.collapsed {
+			continue
+		}
+
+		if day == nil || !w.WorkoutDate.Equal(day.date) {
+			items = append(items, &dayItem{date: w.WorkoutDate, prefs: prefs})
+			day = items[len(items)-1].(*dayItem)
+		}
+		day.count++
+
+		items = append(items, workoutItem{*w, prefs})
+	}
+
+	//the list holds values, the pointers were only needed to total things up
+	for i, item := range items {
+		switch h := item.(type) {
+		case *weekItem:
+			items[i] = *h
+		case *dayItem:
+			items[i] = *h
+		}
+	}
+
+	return items
+}
+
+// the week start of the item at index, for collapsing the week the cursor is in
+func weekOf(items []list.Item, index int) (time.Time, bool) {
+	for i := index; i >= 0 && i < len(items); i-- {
+		if w, ok := items[i].(weekItem); ok {
+			return w.start, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// workoutDelegate draws every row of the grouped list on a single line
+type workoutDelegate struct {
+	theme Theme
+}
+
+func (d workoutDelegate) Height() int                               { return 1 }
+func (d workoutDelegate) Spacing() int                              { return 0 }
+func (d workoutDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+
+func (d workoutDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	selected := index == m.Index() && m.FilterState() != list.Filtering
+
+	var line string
+	switch i := item.(type) {
+	case weekItem:
+		line = d.theme.Heading.Render(i.String())
+	case dayItem:
+		line = "  " + d.theme.Muted.Render(i.String())
+	case workoutItem:
+		line = "    " + i.Title()
+		if details := i.Description(); details != "" {
+			line += "  " + d.theme.Muted.Render(details)
+		}
+	}
+
+	//keep rows to the list's width so a long description can't wrap and push the list down
+	if width := m.Width() - 2; width > 0 && lipgloss.Width(line) > width {
+		line = truncate(line, width)
+	}
+
+	if selected {
+		fmt.Fprint(w, d.theme.Selected.Render("│ ")+line)
+		return
+	}
+	fmt.Fprint(w, "  "+line)
+}
+
+// cuts s down to width cells, ending it with an ellipsis
+func truncate(s string, width int) string {
+	var b strings.Builder
+	for _, r := range s {
+		if lipgloss.Width(b.String()+string(r)) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String() + "…"
+}
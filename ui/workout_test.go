@@ -0,0 +1,583 @@
+package ui
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+const testUserID = 1
+
+func newWorkoutHarness(t *testing.T, s *fakeStore) *harness {
+	t.Helper()
+
+	m := NewWorkoutModel(s, testUserID, testPrefs, 10, DefaultConfig())
+	staticCursor(&m.inputs.ShortDescriptionInput.Cursor)
+	staticCursor(&m.inputs.LongDescriptionInput.Cursor)
+	staticCursor(&m.inputs.WorkoutDateInput.Cursor)
+	staticCursor(&m.inputs.DurationInput.Cursor)
+	staticCursor(&m.inputs.RPEInput.Cursor)
+	staticCursor(&m.inputs.SessionLoadInput.Cursor)
+	staticCursor(&m.list.FilterInput.Cursor)
+	staticCursor(&m.palette.Cursor)
+
+	return newHarness(t, m)
+}
+
+// a store holding one user with a few workouts
+func workoutFixture() *fakeStore {
+	s := newFakeStore()
+	s.addUser("alice")
+	s.addWorkout(testUserID, "Easy run", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	w := s.addWorkout(testUserID, "KB ABC", time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	w.LongDescription = "5 rounds\n10 swings, 5 cleans"
+	w.StartTime = time.Date(2026, 3, 2, 6, 30, 0, 0, time.UTC)
+	w.DurationMinutes, w.RPE = 40, 8
+	w = s.addWorkout(testUserID, "Long run", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC))
+	w.DurationMinutes, w.RPE = 90, 5
+
+	return s
+}
+
+func TestWorkoutListLoads(t *testing.T) {
+	h := newWorkoutHarness(t, workoutFixture())
+	h.expectMsgs("ui.workoutsLoadedMsg")
+	h.golden("workout_list")
+}
+
+func TestWorkoutListEmpty(t *testing.T) {
+	h := newWorkoutHarness(t, newFakeStore())
+	h.golden("workout_list_empty")
+}
+
+func TestWorkoutCreate(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	h.keys("n")
+	h.golden("workout_create_form")
+
+	h.keys("Tempo run", "tab", "3 x 10 min", "tab", "2026-03-04", "tab", "50", "tab", "8", "tab")
+	h.golden("workout_create_form_filled")
+
+	h.clearMsgs()
+	h.keys("enter")
+	h.expectMsgs("ui.workoutCreatedMsg", "ui.workoutsLoadedMsg")
+	h.golden("workout_list_after_create")
+
+	created := s.workouts[len(s.workouts)-1]
+	want := flexcreek.Workout{
+		ID:               created.ID,
+		UserID:           testUserID,
+		ShortDescription: "Tempo run",
+		LongDescription:  "3 x 10 min",
+		WorkoutDate:      time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		DurationMinutes:  50,
+		RPE:              8,
+	}
+	if *created != want {
+		t.Errorf("created %+v, want %+v", *created, want)
+	}
+
+	//the form is cleared for next time
+	h.keys("n")
+	if v := h.model.(WorkoutModel).inputs.ShortDescriptionInput.Value(); v != "" {
+		t.Errorf("the form reopened with %q in it", v)
+	}
+}
+
+func TestWorkoutCreateInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string // title, notes, date, duration, rpe and load, tabbed between
+		want   string
+	}{
+		{"no title", []string{"", "", "", "30", "", ""}, "the workout has no title"},
+		{"bad date", []string{"Run", "", "someday", "", "", ""}, "date must look like"},
+		{"bad duration", []string{"Run", "", "", "3o", "", ""}, "duration must be a whole number of minutes"},
+		{"rpe out of range", []string{"Run", "", "", "", "42", ""}, "rpe must be between 1 and 10"},
+		{"negative load", []string{"Run", "", "", "", "", "-5"}, "load must be a positive number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeStore()
+			h := newWorkoutHarness(t, s)
+
+			h.keys("n")
+			for i, f := range tt.fields {
+				if f != "" {
+					h.keys(f)
+				}
+				if i < len(tt.fields)-1 {
+					h.keys("tab")
+				}
+			}
+			h.clearMsgs()
+			h.keys("enter")
+			h.expectMsgs()
+
+			if len(s.workouts) != 0 {
+				t.Errorf("the store has %d workouts, want none", len(s.workouts))
+			}
+			if view := h.model.View(); !strings.Contains(view, tt.want) {
+				t.Errorf("the form doesn't show %q:\n%s", tt.want, view)
+			}
+
+			//editing the form clears the error
+			h.keys("shift+tab")
+			if view := h.model.View(); strings.Contains(view, tt.want) {
+				t.Errorf("the error is still showing after moving field:\n%s", view)
+			}
+		})
+	}
+}
+
+func TestWorkoutFormFocusCycling(t *testing.T) {
+	h := newWorkoutHarness(t, newFakeStore())
+	h.keys("n")
+
+	focus := func() int { return h.model.(WorkoutModel).inputFocusIndex }
+
+	steps := []struct {
+		key  string
+		want int
+	}{
+		{"tab", inputLongDescription},
+		//enter in the textarea is a newline, so it moves focus back rather than on
+		{"enter", inputShortDescription},
+		{"shift+tab", inputSessionLoad},
+		{"down", inputShortDescription},
+		{"down", inputLongDescription},
+		{"down", inputWorkoutDate},
+		{"up", inputLongDescription},
+	}
+
+	for _, step := range steps {
+		h.keys(step.key)
+		if got := focus(); got != step.want {
+			t.Fatalf("after %s focus is on input %d, want %d", step.key, got, step.want)
+		}
+	}
+
+	m := h.model.(WorkoutModel)
+	if !m.inputs.LongDescriptionInput.Focused() || m.inputs.ShortDescriptionInput.Focused() || m.inputs.WorkoutDateInput.Focused() {
+		t.Error("only the long description should have focus")
+	}
+}
+
+func TestWorkoutCreateBack(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	h.keys("n", "Half typed", "esc")
+	h.golden("workout_list")
+
+	if len(s.workouts) != 3 {
+		t.Errorf("leaving the form created a workout")
+	}
+}
+
+func TestWorkoutView(t *testing.T) {
+	h := newWorkoutHarness(t, workoutFixture())
+
+	//down past the day header to KB ABC
+	h.clearMsgs()
+	h.keys("down", "down", "enter")
+	h.expectMsgs("ui.workoutSelectedMsg", "ui.revisionsLoadedMsg")
+	if got := h.msgs[0].(workoutSelectedMsg).workout.ShortDescription; got != "KB ABC" {
+		t.Errorf("selected %q, want KB ABC", got)
+	}
+	h.golden("workout_view")
+
+	h.keys("esc")
+	h.golden("workout_list_second_selected")
+}
+
+func TestWorkoutHistory(t *testing.T) {
+	s := workoutFixture()
+	before := *s.workouts[1]
+	before.ShortDescription = "KB AB"
+	before.RPE = 6
+	s.revisions[before.ID] = []*flexcreek.WorkoutRevision{
+		{ID: 1, Workout: before, ChangedBy: testUserID, ChangedAt: time.Date(2026, 3, 2, 20, 15, 0, 0, time.UTC)},
+	}
+	h := newWorkoutHarness(t, s)
+
+	h.keys("down", "down", "enter")
+	h.clearMsgs()
+	h.keys("h")
+	h.expectMsgs("ui.revisionsLoadedMsg")
+	h.golden("workout_history")
+
+	h.clearMsgs()
+	h.keys("r")
+	h.expectMsgs("ui.workoutRevertedMsg", "ui.revisionsLoadedMsg", "ui.workoutsLoadedMsg")
+	if got := s.workouts[1].ShortDescription; got != "KB AB" {
+		t.Errorf("after reverting the workout is %q, want KB AB", got)
+	}
+
+	h.keys("esc")
+	if m := h.model.(WorkoutModel); m.state != stateViewWorkout {
+		t.Errorf("esc from the history went to state %d, want the workout view", m.state)
+	}
+	h.keys("esc")
+	if m := h.model.(WorkoutModel); m.state != stateWorkoutList {
+		t.Errorf("esc from the workout went to state %d, want the list", m.state)
+	}
+}
+
+func TestWorkoutDelete(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("x")
+	h.expectMsgs("ui.workoutDeletedMsg", "ui.workoutsLoadedMsg")
+	h.golden("workout_list_after_delete")
+
+	if s.workouts[2].DeletedAt.IsZero() {
+		t.Error("the newest workout wasn't deleted")
+	}
+}
+
+func TestWorkoutNavigation(t *testing.T) {
+	cases := []struct {
+		key  string
+		want string
+	}{
+		{"s", "ui.showUsersMsg"},
+		{"m", "ui.showMeasurementsMsg"},
+		{"o", "ui.showGoalsMsg"},
+		{"T", "ui.showTrashMsg"},
+	}
+
+	for _, c := range cases {
+		h := newWorkoutHarness(t, workoutFixture())
+		h.clearMsgs()
+		h.keys(c.key)
+		h.expectMsgs(c.want)
+	}
+}
+
+func TestWorkoutLoadError(t *testing.T) {
+	s := workoutFixture()
+	s.fail["GetLatestWorkouts"] = errors.New("database is locked")
+
+	h := newWorkoutHarness(t, s)
+	h.expectMsgs("*errors.errorString")
+	h.golden("workout_load_error")
+}
+
+func TestWorkoutCreateError(t *testing.T) {
+	s := workoutFixture()
+	s.fail["CreateWorkout"] = errors.New("disk full")
+	h := newWorkoutHarness(t, s)
+
+	h.keys("n", "Run", "tab", "tab", "tab", "tab", "tab")
+	h.clearMsgs()
+	h.keys("enter")
+	h.expectMsgs("*errors.errorString")
+	h.golden("workout_create_error")
+
+	if len(s.workouts) != 3 {
+		t.Errorf("the store has %d workouts after a failed create, want 3", len(s.workouts))
+	}
+}
+
+func TestWorkoutDeleteError(t *testing.T) {
+	s := workoutFixture()
+	s.fail["DeleteWorkout"] = flexcreek.ErrNotFound
+	h := newWorkoutHarness(t, s)
+
+	h.clearMsgs()
+	h.keys("x")
+	h.expectMsgs("*errors.errorString")
+	h.golden("workout_delete_error")
+}
+
+// two weeks with a two-a-day in each
+func trainingLogFixture() *fakeStore {
+	s := newFakeStore()
+	s.addUser("alice")
+	add := func(desc string, day, hour, duration, rpe int) {
+		w := s.addWorkout(testUserID, desc, time.Date(2026, 3, day, 0, 0, 0, 0, time.UTC))
+		w.StartTime = time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+		w.DurationMinutes, w.RPE = duration, rpe
+	}
+	add("Easy run", 3, 7, 40, 4)
+	add("Squats", 3, 18, 60, 8)
+	add("Intervals", 5, 7, 50, 9)
+	add("Easy run", 10, 7, 45, 4)
+	add("Easy run", 11, 6, 30, 3)
+	add("Deadlifts", 11, 17, 60, 8)
+
+	return s
+}
+
+func TestWorkoutListGrouping(t *testing.T) {
+	h := newWorkoutHarness(t, trainingLogFixture())
+	h.golden("workout_list_grouped")
+
+	//the cursor starts on the newest workout, not a header
+	if i, ok := h.model.(WorkoutModel).list.SelectedItem().(workoutItem); !ok || i.ShortDescription != "Deadlifts" {
+		t.Errorf("selected %v, want the Deadlifts row", h.model.(WorkoutModel).list.SelectedItem())
+	}
+}
+
+func TestWorkoutListCollapse(t *testing.T) {
+	h := newWorkoutHarness(t, trainingLogFixture())
+	rows := len(h.model.(WorkoutModel).list.Items())
+
+	//space folds the week the cursor is in, from any row of it
+	h.keys(" ")
+	h.golden("workout_list_collapsed")
+	if _, ok := h.model.(WorkoutModel).list.SelectedItem().(weekItem); !ok {
+		t.Error("folding a week didn't leave the cursor on its header")
+	}
+
+	//enter on a header unfolds it rather than opening anything
+	h.clearMsgs()
+	h.keys("enter")
+	h.expectMsgs()
+	if got := len(h.model.(WorkoutModel).list.Items()); got != rows {
+		t.Errorf("after unfolding the list has %d rows, want %d", got, rows)
+	}
+	if w, ok := h.model.(WorkoutModel).list.SelectedItem().(weekItem); !ok || w.collapsed {
+		t.Error("unfolding a week didn't leave the cursor on its open header")
+	}
+}
+
+func TestWorkoutListCollapseSurvivesReload(t *testing.T) {
+	s := trainingLogFixture()
+	h := newWorkoutHarness(t, s)
+
+	h.keys(" ", "down", "down", "down", "x")
+	if s.workouts[2].DeletedAt.IsZero() {
+		t.Fatal("x on Intervals didn't delete it")
+	}
+	h.golden("workout_list_collapsed_after_delete")
+}
+
+func TestWorkoutListFilterSkipsHeaders(t *testing.T) {
+	h := newWorkoutHarness(t, trainingLogFixture())
+
+	h.keys("/", "run", "enter")
+	if n := len(h.model.(WorkoutModel).list.VisibleItems()); n != 3 {
+		t.Errorf("filtering for run shows %d rows, want the 3 runs", n)
+	}
+	for _, item := range h.model.(WorkoutModel).list.VisibleItems() {
+		if _, ok := item.(workoutItem); !ok {
+			t.Errorf("the filtered list shows header %v", item)
+		}
+	}
+}
+
+func TestWorkoutListCompletesOldestWeek(t *testing.T) {
+	s := trainingLogFixture()
+	//the latest 4 stop partway through the week of 2026-03-02
+	m := NewWorkoutModel(s, testUserID, testPrefs, 4, DefaultConfig())
+	h := newHarness(t, m)
+
+	var weeks []weekItem
+	for _, item := range h.model.(WorkoutModel).list.Items() {
+		if w, ok := item.(weekItem); ok {
+			weeks = append(weeks, w)
+		}
+	}
+
+	if len(weeks) != 2 || weeks[1].count != 3 || weeks[1].minutes != 150 {
+		t.Errorf("weeks = %+v, want the older week with all 3 workouts", weeks)
+	}
+}
+
+func TestWorkoutDetailMarkdown(t *testing.T) {
+	s := workoutFixture()
+	s.workouts[2].LongDescription = "**Steady** the whole way, which is a long enough sentence that it has to wrap at eighty columns.\n\n" +
+		"- 5 km easy\n- 20 km at marathon pace\n\n" +
+		"| split | pace |\n|---|---|\n| 1 | 5:10 |\n| 2 | 5:02 |\n"
+	h := newWorkoutHarness(t, s)
+
+	h.keys("enter")
+	h.golden("workout_detail_markdown")
+}
+
+func TestWorkoutDetailScrolls(t *testing.T) {
+	s := workoutFixture()
+	var notes []string
+	for i := 1; i <= 40; i++ {
+		notes = append(notes, "- set "+strconv.Itoa(i))
+	}
+	s.workouts[2].LongDescription = strings.Join(notes, "\n")
+	h := newWorkoutHarness(t, s)
+
+	h.keys("enter")
+	top := h.model.View()
+	if !strings.Contains(top, "set 1 ") || strings.Contains(top, "set 40") {
+		t.Fatalf("the top of the notes should show, not the end\n%s", top)
+	}
+
+	h.keys("f", "f", "f")
+	if bottom := h.model.View(); !strings.Contains(bottom, "set 40") || !strings.Contains(bottom, "100%") {
+		t.Errorf("scrolling to the bottom didn't show the end of the notes\n%s", bottom)
+	}
+	if got := strings.Count(h.model.View(), "\n") + 1; got != testSize.Height {
+		t.Errorf("the detail view is %d lines, want the terminal's %d", got, testSize.Height)
+	}
+}
+
+func TestWorkoutDetailLastEdited(t *testing.T) {
+	s := workoutFixture()
+	s.workouts[2].CreatedAt = time.Date(2026, 3, 3, 19, 0, 0, 0, time.UTC)
+	before := *s.workouts[2]
+	s.revisions[before.ID] = []*flexcreek.WorkoutRevision{
+		{ID: 2, Workout: before, ChangedBy: testUserID, ChangedAt: time.Date(2026, 3, 4, 8, 5, 0, 0, time.UTC)},
+		{ID: 1, Workout: before, ChangedBy: testUserID, ChangedAt: time.Date(2026, 3, 3, 21, 0, 0, 0, time.UTC)},
+	}
+	h := newWorkoutHarness(t, s)
+
+	h.keys("enter")
+	if view := h.model.View(); !strings.Contains(view, "logged 2026-03-03 19:00 · edited 2026-03-04 08:05") {
+		t.Errorf("the header doesn't show when the workout was logged and last edited\n%s", view)
+	}
+}
+
+func TestWorkoutDetailNextPrevious(t *testing.T) {
+	h := newWorkoutHarness(t, workoutFixture())
+	h.keys("enter")
+
+	showing := func() string { return h.model.(WorkoutModel).selectedWorkout.ShortDescription }
+
+	steps := []struct {
+		key  string
+		want string
+	}{
+		{"n", "KB ABC"},
+		{"n", "Easy run"},
+		//the oldest workout has nothing after it
+		{"n", "Easy run"},
+		{"p", "KB ABC"},
+		{"left", "Long run"},
+		{"left", "Long run"},
+		{"right", "KB ABC"},
+	}
+
+	for _, step := range steps {
+		h.keys(step.key)
+		if got := showing(); got != step.want {
+			t.Fatalf("after %s the detail shows %s, want %s", step.key, got, step.want)
+		}
+	}
+
+	//the list's cursor follows along
+	h.keys("esc")
+	h.golden("workout_list_second_selected")
+}
+
+func TestWorkoutNotesInEditor(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	s := newFakeStore()
+	h := newWorkoutHarness(t, s)
+
+	//ctrl+e hands the terminal to the editor, which the runtime does rather than a command
+	h.keys("n", "Tempo run", "tab", "draft")
+	h.clearMsgs()
+	h.keys("ctrl+e")
+	h.expectMsgs("tea.execMsg")
+
+	//what the editor saved replaces the notes, and the form carries on from there
+	h.send(notesEditedMsg{"3 x 10 min\n\n- 2 min jog between\n"})
+	h.keys("tab", "2026-03-04", "tab", "tab", "tab", "enter")
+
+	if len(s.workouts) != 1 {
+		t.Fatalf("the store has %d workouts, want 1", len(s.workouts))
+	}
+	if got, want := s.workouts[0].LongDescription, "3 x 10 min\n\n- 2 min jog between"; got != want {
+		t.Errorf("notes = %q, want %q", got, want)
+	}
+}
+
+func TestWorkoutDuplicate(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	//from the KB ABC row, into a form dated today
+	h.keys("down", "down", "d")
+	h.golden("workout_duplicate_form")
+
+	h.clearMsgs()
+	h.keys("tab", "tab", "tab", "tab", "tab", "enter")
+	h.expectMsgs("ui.workoutCreatedMsg", "ui.workoutsLoadedMsg")
+
+	original, created := s.workouts[1], s.workouts[len(s.workouts)-1]
+	want := flexcreek.Workout{
+		ID:               created.ID,
+		UserID:           testUserID,
+		ShortDescription: original.ShortDescription,
+		LongDescription:  original.LongDescription,
+		WorkoutDate:      testPrefs.Today(),
+		DurationMinutes:  original.DurationMinutes,
+		RPE:              original.RPE,
+	}
+	if *created != want {
+		t.Errorf("created %+v, want %+v", *created, want)
+	}
+}
+
+func TestWorkoutDuplicateThenNew(t *testing.T) {
+	s := workoutFixture()
+	h := newWorkoutHarness(t, s)
+
+	//duplicate KB ABC, move off the first field, then back out and start a new workout
+	h.keys("down", "down", "d", "tab", "tab", "esc", "n")
+	h.golden("workout_create_form")
+
+	m := h.model.(WorkoutModel)
+	inputs := []struct {
+		name  string
+		value string
+	}{
+		{"title", m.inputs.ShortDescriptionInput.Value()},
+		{"notes", m.inputs.LongDescriptionInput.Value()},
+		{"date", m.inputs.WorkoutDateInput.Value()},
+		{"duration", m.inputs.DurationInput.Value()},
+		{"rpe", m.inputs.RPEInput.Value()},
+		{"load", m.inputs.SessionLoadInput.Value()},
+	}
+	for _, in := range inputs {
+		if in.value != "" {
+			t.Errorf("the new form's %s is %q, want it empty", in.name, in.value)
+		}
+	}
+	if m.inputFocusIndex != inputShortDescription || !m.inputs.ShortDescriptionInput.Focused() || m.inputs.WorkoutDateInput.Focused() {
+		t.Errorf("the new form has focus on input %d, want just the title focused", m.inputFocusIndex)
+	}
+
+	//and what's typed goes into the title, not the field the duplicate was left on
+	h.keys("Swim")
+	if m := h.model.(WorkoutModel); m.inputs.ShortDescriptionInput.Value() != "Swim" || m.inputs.WorkoutDateInput.Value() != "" {
+		t.Errorf("typing filled title %q and date %q", m.inputs.ShortDescriptionInput.Value(), m.inputs.WorkoutDateInput.Value())
+	}
+}
+
+func TestWorkoutDuplicateFromDetail(t *testing.T) {
+	s := workoutFixture()
+	s.workouts[2].SessionLoad = 500
+	h := newWorkoutHarness(t, s)
+
+	h.keys("enter", "d")
+	m := h.model.(WorkoutModel)
+	if m.state != stateCreateWorkout {
+		t.Fatalf("d in the detail view left the state at %v, want the create form", m.state)
+	}
+	if got := m.inputs.ShortDescriptionInput.Value(); got != "Long run" {
+		t.Errorf("the form's title is %q, want Long run", got)
+	}
+	if got := m.inputs.SessionLoadInput.Value(); got != "500" {
+		t.Errorf("the form's load is %q, want the explicit 500", got)
+	}
+}
@@ -0,0 +1,83 @@
+// Package units converts quantities between metric and imperial units
+package units
+
+import (
+	"fmt"
+)
+
+const (
+	Kilogram   = "kg"
+	Pound      = "lb"
+	Centimeter = "cm"
+	Inch       = "in"
+	Kilometer  = "km"
+	Mile       = "mi"
+	Percent    = "%"
+)
+
+const (
+	poundsPerKilogram  = 2.20462262185
+	centimetersPerInch = 2.54
+	kilometersPerMile  = 1.609344
+)
+
+// Convert converts value from one unit to another.
+// converting a unit to itself is always allowed, so unknown units pass through untouched
+func Convert(value float64, from string, to string) (float64, error) {
+	if from == to {
+		return value, nil
+	}
+
+	switch {
+	case from == Kilogram && to == Pound:
+		return value * poundsPerKilogram, nil
+	case from == Pound && to == Kilogram:
+		return value / poundsPerKilogram, nil
+	case from == Centimeter && to == Inch:
+		return value / centimetersPerInch, nil
+	case from == Inch && to == Centimeter:
+		return value * centimetersPerInch, nil
+	case from == Kilometer && to == Mile:
+		return value / kilometersPerMile, nil
+	case from == Mile && to == Kilometer:
+		return value * kilometersPerMile, nil
+	}
+
+	return 0, fmt.Errorf("can't convert %s to %s", from, to)
+}
+
+// Counterpart returns the unit on the other side of the metric/imperial divide,
+// or the unit itself if it has none (e.g. percentages)
+func Counterpart(unit string) string {
+	switch unit {
+	case Kilogram:
+		return Pound
+	case Pound:
+		return Kilogram
+	case Centimeter:
+		return Inch
+	case Inch:
+		return Centimeter
+	case Kilometer:
+		return Mile
+	case Mile:
+		return Kilometer
+	}
+
+	return unit
+}
+
+// Canonical converts a value into the unit it's stored in (kg, cm or km).
+// units without an imperial counterpart are already canonical and come back unchanged
+func Canonical(value float64, unit string) (float64, string) {
+	switch unit {
+	case Pound:
+		return value / poundsPerKilogram, Kilogram
+	case Inch:
+		return value * centimetersPerInch, Centimeter
+	case Mile:
+		return value * kilometersPerMile, Kilometer
+	}
+
+	return value, unit
+}
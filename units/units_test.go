@@ -0,0 +1,93 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvertRoundTrips(t *testing.T) {
+	tests := []struct {
+		from, to string
+		value    float64
+		want     float64
+	}{
+		{Kilogram, Pound, 100, 220.462262185},
+		{Pound, Kilogram, 220.462262185, 100},
+		{Centimeter, Inch, 254, 100},
+		{Inch, Centimeter, 12, 30.48},
+		{Kilometer, Mile, 1.609344, 1},
+		{Mile, Kilometer, 26.2, 42.1648128},
+		{Kilogram, Kilogram, 80, 80},
+		{Percent, Percent, 15, 15},
+		{"st", "st", 12, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			got, err := Convert(tt.value, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("%v %s is %v %s, want %v", tt.value, tt.from, got, tt.to, tt.want)
+			}
+
+			back, err := Convert(got, tt.to, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(back-tt.value) > 1e-9 {
+				t.Errorf("%v %s came back from %s as %v", tt.value, tt.from, tt.to, back)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct{ from, to string }{
+		{Kilogram, Centimeter},
+		{Pound, Inch},
+		{Kilometer, Kilogram},
+		{Percent, Kilogram},
+		{"st", Kilogram},
+		{Kilogram, "st"},
+		{"", Kilogram},
+	}
+
+	for _, tt := range tests {
+		if got, err := Convert(1, tt.from, tt.to); err == nil {
+			t.Errorf("converting %s to %s gave %v, want an error", tt.from, tt.to, got)
+		}
+	}
+}
+
+func TestCounterpartAndCanonical(t *testing.T) {
+	tests := []struct {
+		unit        string
+		counterpart string
+		canonical   string
+		value       float64 // 1 of unit in the canonical unit
+	}{
+		{Kilogram, Pound, Kilogram, 1},
+		{Pound, Kilogram, Kilogram, 1 / 2.20462262185},
+		{Centimeter, Inch, Centimeter, 1},
+		{Inch, Centimeter, Centimeter, 2.54},
+		{Kilometer, Mile, Kilometer, 1},
+		{Mile, Kilometer, Kilometer, 1.609344},
+		{Percent, Percent, Percent, 1},
+		{"reps", "reps", "reps", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			if got := Counterpart(tt.unit); got != tt.counterpart {
+				t.Errorf("Counterpart(%q) = %q, want %q", tt.unit, got, tt.counterpart)
+			}
+
+			v, unit := Canonical(1, tt.unit)
+			if unit != tt.canonical || math.Abs(v-tt.value) > 1e-9 {
+				t.Errorf("Canonical(1, %q) = %v %s, want %v %s", tt.unit, v, unit, tt.value, tt.canonical)
+			}
+		})
+	}
+}
@@ -1,11 +1,204 @@
 package flexcreek
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/ekholme/flexcreek/units"
 )
 
 type User struct {
-	ID        int       `db:"id"`
-	Username  string    `db:"username"`
-	CreatedAt time.Time `db:"create_at"`
+	ID          int       `db:"id"`
+	UUID        string    `db:"uuid"` // stable across databases, unlike ID
+	Username    string    `db:"username"`
+	CreatedAt   time.Time `db:"created_at"` // UTC
+	DeletedAt   time.Time `db:"deleted_at"` // zero unless the user is in the trash
+	Preferences Preferences
+}
+
+// Preferences control how quantities and dates are displayed to a user.
+// storage is always in canonical units (kg, km, cm), these only affect presentation
+type Preferences struct {
+	WeightUnit     string       `db:"weight_unit"`
+	DistanceUnit   string       `db:"distance_unit"`
+	FirstDayOfWeek time.Weekday `db:"first_day_of_week"`
+	DateFormat     string       `db:"date_format"`
+	TimeZone       string       `db:"time_zone"`
+}
+
+// date display formats a user can pick from, as Go reference layouts
+var DateFormats = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"02/01/2006",
+	"02.01.2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+}
+
+func DefaultPreferences() Preferences {
+	return Preferences{
+		WeightUnit:     units.Kilogram,
+		DistanceUnit:   units.Kilometer,
+		FirstDayOfWeek: time.Monday,
+		DateFormat:     "2006-01-02",
+		TimeZone:       "Local",
+	}
+}
+
+// Validate checks every preference holds a supported value
+func (p Preferences) Validate() error {
+	if p.WeightUnit != units.Kilogram && p.WeightUnit != units.Pound {
+		return fmt.Errorf("weight unit must be %s or %s", units.Kilogram, units.Pound)
+	}
+
+	if p.DistanceUnit != units.Kilometer && p.DistanceUnit != units.Mile {
+		return fmt.Errorf("distance unit must be %s or %s", units.Kilometer, units.Mile)
+	}
+
+	if p.FirstDayOfWeek < time.Sunday || p.FirstDayOfWeek > time.Saturday {
+		return fmt.Errorf("invalid first day of week %d", p.FirstDayOfWeek)
+	}
+
+	validFormat := false
+	for _, f := range DateFormats {
+		if p.DateFormat == f {
+			validFormat = true
+			break
+		}
+	}
+	if !validFormat {
+		return fmt.Errorf("unsupported date format %q", p.DateFormat)
+	}
+
+	if _, err := time.LoadLocation(p.TimeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", p.TimeZone)
+	}
+
+	return nil
+}
+
+// Location returns the user's time zone, falling back to the machine's zone if it can't be loaded
+func (p Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// Today returns the current calendar date in the user's time zone, at midnight UTC
+func (p Preferences) Today() time.Time {
+	now := time.Now().In(p.Location())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// FormatDate formats a calendar date using the user's date format
+func (p Preferences) FormatDate(t time.Time) string {
+	return t.Format(p.dateFormat())
+}
+
+// ParseDate parses a calendar date written in the user's date format
+func (p Preferences) ParseDate(s string) (time.Time, error) {
+	return time.Parse(p.dateFormat(), s)
+}
+
+// ParseDay parses a calendar date the way someone would type one: today, yesterday, a weekday
+// (mon or monday, meaning the most recent one, which can be today) or a date in the user's format
+func (p Preferences) ParseDay(s string) (time.Time, error) {
+	return p.parseDay(s, p.Today())
+}
+
+func (p Preferences) parseDay(s string, today time.Time) (time.Time, error) {
+	switch word := strings.ToLower(s); word {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	default:
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if word == name || word == name[:3] {
+				return today.AddDate(0, 0, -((int(today.Weekday()) - int(d) + 7) % 7)), nil
+			}
+		}
+	}
+
+	return p.ParseDate(s)
+}
+
+// StartOfWeek returns the first day of the week containing t, respecting FirstDayOfWeek
+func (p Preferences) StartOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) - int(p.FirstDayOfWeek) + 7) % 7
+	d := t.AddDate(0, 0, -offset)
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location())
+}
+
+// FormatWeight formats a weight held in kilograms in the user's weight unit
+func (p Preferences) FormatWeight(kg float64) string {
+	return formatQuantity(kg, units.Kilogram, p.weightUnit())
+}
+
+// FormatDistance formats a distance held in kilometers in the user's distance unit
+func (p Preferences) FormatDistance(km float64) string {
+	return formatQuantity(km, units.Kilometer, p.distanceUnit())
+}
+
+// FormatLength formats a body length held in centimeters, using inches for users who prefer miles
+func (p Preferences) FormatLength(cm float64) string {
+	if p.distanceUnit() == units.Mile {
+		return formatQuantity(cm, units.Centimeter, units.Inch)
+	}
+
+	return formatQuantity(cm, units.Centimeter, units.Centimeter)
+}
+
+// DisplayUnit returns the unit a value stored in the canonical unit should be shown in
+func (p Preferences) DisplayUnit(canonical string) string {
+	switch canonical {
+	case units.Kilogram:
+		return p.weightUnit()
+	case units.Kilometer:
+		return p.distanceUnit()
+	case units.Centimeter:
+		if p.distanceUnit() == units.Mile {
+			return units.Inch
+		}
+	}
+
+	return canonical
+}
+
+// the getters below fall back to the defaults so a zero value Preferences is still usable
+func (p Preferences) dateFormat() string {
+	if p.DateFormat == "" {
+		return DefaultPreferences().DateFormat
+	}
+	return p.DateFormat
+}
+
+func (p Preferences) weightUnit() string {
+	if p.WeightUnit == "" {
+		return DefaultPreferences().WeightUnit
+	}
+	return p.WeightUnit
+}
+
+func (p Preferences) distanceUnit() string {
+	if p.DistanceUnit == "" {
+		return DefaultPreferences().DistanceUnit
+	}
+	return p.DistanceUnit
+}
+
+func formatQuantity(value float64, from string, to string) string {
+	v, err := units.Convert(value, from, to)
+	if err != nil {
+		v, to = value, from
+	}
+
+	return strconv.FormatFloat(v, 'f', 1, 64) + " " + to
 }
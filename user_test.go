@@ -0,0 +1,243 @@
+package flexcreek
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreferencesValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(p *Preferences)
+		err  string
+	}{
+		{"the defaults", func(p *Preferences) {}, ""},
+		{"imperial", func(p *Preferences) { p.WeightUnit, p.DistanceUnit = "lb", "mi" }, ""},
+		{"a named zone", func(p *Preferences) { p.TimeZone = "America/New_York" }, ""},
+		{"utc", func(p *Preferences) { p.TimeZone = "UTC" }, ""},
+		{"another date format", func(p *Preferences) { p.DateFormat = "2 Jan 2006" }, ""},
+		{"sunday", func(p *Preferences) { p.FirstDayOfWeek = time.Sunday }, ""},
+		{"saturday", func(p *Preferences) { p.FirstDayOfWeek = time.Saturday }, ""},
+		{"stone", func(p *Preferences) { p.WeightUnit = "st" }, "weight unit must be kg or lb"},
+		{"no weight unit", func(p *Preferences) { p.WeightUnit = "" }, "weight unit must be kg or lb"},
+		{"metres", func(p *Preferences) { p.DistanceUnit = "m" }, "distance unit must be km or mi"},
+		{"a day before sunday", func(p *Preferences) { p.FirstDayOfWeek = -1 }, "invalid first day of week -1"},
+		{"a day after saturday", func(p *Preferences) { p.FirstDayOfWeek = 7 }, "invalid first day of week 7"},
+		{"a layout that isn't offered", func(p *Preferences) { p.DateFormat = "2006/01/02" }, "unsupported date format"},
+		{"a zone that doesn't exist", func(p *Preferences) { p.TimeZone = "Mars/Olympus_Mons" }, `unknown time zone "Mars/Olympus_Mons"`},
+		{"an offset instead of a zone", func(p *Preferences) { p.TimeZone = "+05:00" }, "unknown time zone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultPreferences()
+			tt.edit(&p)
+
+			err := p.Validate()
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("got %v, want it to pass", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got %v, want an error saying %q", err, tt.err)
+			}
+		})
+	}
+
+	for _, f := range DateFormats {
+		p := DefaultPreferences()
+		p.DateFormat = f
+		if err := p.Validate(); err != nil {
+			t.Errorf("the offered format %q doesn't validate: %v", f, err)
+		}
+	}
+}
+
+func TestLocation(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("zone data not available: %v", err)
+	}
+
+	if got := (Preferences{TimeZone: "America/New_York"}).Location(); got.String() != ny.String() {
+		t.Errorf("got %v, want %v", got, ny)
+	}
+	if got := (Preferences{TimeZone: "Mars/Olympus_Mons"}).Location(); got != time.Local {
+		t.Errorf("an unknown zone gave %v, want the machine's zone", got)
+	}
+	if got := (Preferences{TimeZone: "Local"}).Location(); got != time.Local {
+		t.Errorf("Local gave %v, want the machine's zone", got)
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	//a Wednesday
+	today := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	date := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	us := DefaultPreferences()
+	us.DateFormat = "01/02/2006"
+
+	tests := []struct {
+		in    string
+		prefs Preferences
+		want  time.Time
+		err   bool
+	}{
+		{"today", DefaultPreferences(), today, false},
+		{"Today", DefaultPreferences(), today, false},
+		{"yesterday", DefaultPreferences(), date(3, 10), false},
+		{"wed", DefaultPreferences(), today, false},
+		{"wednesday", DefaultPreferences(), today, false},
+		{"tue", DefaultPreferences(), date(3, 10), false},
+		{"Monday", DefaultPreferences(), date(3, 9), false},
+		{"sun", DefaultPreferences(), date(3, 8), false},
+		{"thu", DefaultPreferences(), date(3, 5), false},
+		{"2026-02-28", DefaultPreferences(), date(2, 28), false},
+		{"02/28/2026", us, date(2, 28), false},
+		{"2026-02-28", us, time.Time{}, true},
+		{"28/02/2026", us, time.Time{}, true},
+		{"tomorrow", DefaultPreferences(), time.Time{}, true},
+		{"th", DefaultPreferences(), time.Time{}, true},
+		{"", DefaultPreferences(), time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := tt.prefs.parseDay(tt.in, today)
+			if tt.err {
+				if err == nil {
+					t.Errorf("parsed %q as %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	//Wednesday 11 March 2026
+	wed := time.Date(2026, 3, 11, 15, 30, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		first time.Weekday
+		want  int // day of March
+	}{
+		{time.Sunday, 8},
+		{time.Monday, 9},
+		{time.Tuesday, 10},
+		{time.Wednesday, 11},
+		{time.Thursday, 5},
+		{time.Friday, 6},
+		{time.Saturday, 7},
+	} {
+		t.Run(tt.first.String(), func(t *testing.T) {
+			p := Preferences{FirstDayOfWeek: tt.first}
+			if got, want := p.StartOfWeek(wed), time.Date(2026, 3, tt.want, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStartOfWeekAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("zone data not available: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		t     time.Time
+		first time.Weekday
+		want  time.Time
+	}{
+		//clocks went forward at 2am on Sunday 8 March 2026
+		{"spring, week starts before the change", time.Date(2026, 3, 12, 9, 0, 0, 0, ny), time.Sunday, time.Date(2026, 3, 8, 0, 0, 0, 0, ny)},
+		{"spring, week starts after it", time.Date(2026, 3, 12, 9, 0, 0, 0, ny), time.Monday, time.Date(2026, 3, 9, 0, 0, 0, 0, ny)},
+		{"spring, late on the day itself", time.Date(2026, 3, 8, 23, 30, 0, 0, ny), time.Sunday, time.Date(2026, 3, 8, 0, 0, 0, 0, ny)},
+		//and back at 2am on Sunday 1 November 2026
+		{"autumn, week starts before the change", time.Date(2026, 11, 5, 0, 30, 0, 0, ny), time.Sunday, time.Date(2026, 11, 1, 0, 0, 0, 0, ny)},
+		{"autumn, week starts the week before", time.Date(2026, 11, 1, 23, 0, 0, 0, ny), time.Monday, time.Date(2026, 10, 26, 0, 0, 0, 0, ny)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (Preferences{FirstDayOfWeek: tt.first}).StartOfWeek(tt.t)
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			//local midnight, not 23:00 or 01:00 from carrying the offset across the change
+			if got.Hour() != 0 || got.Location() != ny {
+				t.Errorf("got %v, want midnight in New York", got)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	metric := DefaultPreferences()
+	imperial := Preferences{WeightUnit: "lb", DistanceUnit: "mi", DateFormat: "Jan 2, 2006"}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"iso date", metric.FormatDate(date), "2026-03-01"},
+		{"us date", imperial.FormatDate(date), "Mar 1, 2026"},
+		{"zero value date", Preferences{}.FormatDate(date), "2026-03-01"},
+		{"kg", metric.FormatWeight(80), "80.0 kg"},
+		{"lb", imperial.FormatWeight(80), "176.4 lb"},
+		{"zero value weight", Preferences{}.FormatWeight(80), "80.0 kg"},
+		{"km", metric.FormatDistance(42.195), "42.2 km"},
+		{"mi", imperial.FormatDistance(42.195), "26.2 mi"},
+		{"cm", metric.FormatLength(84), "84.0 cm"},
+		{"in", imperial.FormatLength(84), "33.1 in"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	for _, f := range DateFormats {
+		p := Preferences{DateFormat: f}
+		parsed, err := p.ParseDate(p.FormatDate(date))
+		if err != nil || !parsed.Equal(date) {
+			t.Errorf("%q: %v came back as %v (%v)", f, date, parsed, err)
+		}
+	}
+}
+
+func TestDisplayUnit(t *testing.T) {
+	metric := DefaultPreferences()
+	imperial := Preferences{WeightUnit: "lb", DistanceUnit: "mi"}
+
+	for _, tt := range []struct {
+		canonical        string
+		metric, imperial string
+	}{
+		{"kg", "kg", "lb"},
+		{"km", "km", "mi"},
+		{"cm", "cm", "in"},
+		{"%", "%", "%"},
+	} {
+		if got := metric.DisplayUnit(tt.canonical); got != tt.metric {
+			t.Errorf("metric users see %s as %s, want %s", tt.canonical, got, tt.metric)
+		}
+		if got := imperial.DisplayUnit(tt.canonical); got != tt.imperial {
+			t.Errorf("imperial users see %s as %s, want %s", tt.canonical, got, tt.imperial)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// html date inputs always submit ISO dates regardless of the user's display format
+const dateInputLayout = "2006-01-02"
+
+// what <input type="time"> submits
+const timeInputLayout = "15:04"
+
+// page data
+
+type usersPage struct {
+	User  *flexcreek.User // always nil, the layout shows the current user when there is one
+	Users []*flexcreek.User
+	Error string
+}
+
+type workoutView struct {
+	flexcreek.Workout
+	Date  string
+	Start string // empty when the start time wasn't recorded
+	Load  float64
+}
+
+type workoutsPage struct {
+	User     *flexcreek.User
+	Workouts []workoutView
+	Query    string
+	Page     int
+	PrevPage int
+	NextPage int
+}
+
+type workoutPage struct {
+	User    *flexcreek.User
+	Workout workoutView
+}
+
+type formPage struct {
+	User   *flexcreek.User
+	Action string
+	Title  string
+	Form   workoutForm
+	Error  string
+}
+
+// the raw form values, kept as strings so they can be redisplayed after a validation error
+type workoutForm struct {
+	ShortDescription string
+	LongDescription  string
+	WorkoutDate      string
+	StartTime        string
+	DurationMinutes  string
+	RPE              string
+	SessionLoad      string
+}
+
+func newWorkoutView(w *flexcreek.Workout, p flexcreek.Preferences) workoutView {
+	v := workoutView{Workout: *w, Date: p.FormatDate(w.WorkoutDate), Load: w.Load()}
+	if !w.StartTime.IsZero() {
+		v.Start = w.StartTime.Format(timeInputLayout)
+	}
+	return v
+}
+
+func newWorkoutForm(w *flexcreek.Workout) workoutForm {
+	f := workoutForm{
+		ShortDescription: w.ShortDescription,
+		LongDescription:  w.LongDescription,
+		WorkoutDate:      w.WorkoutDate.Format(dateInputLayout),
+	}
+	if !w.StartTime.IsZero() {
+		f.StartTime = w.StartTime.Format(timeInputLayout)
+	}
+	if w.DurationMinutes > 0 {
+		f.DurationMinutes = strconv.Itoa(w.DurationMinutes)
+	}
+	if w.RPE > 0 {
+		f.RPE = strconv.Itoa(w.RPE)
+	}
+	if w.SessionLoad > 0 {
+		f.SessionLoad = strconv.FormatFloat(w.SessionLoad, 'f', -1, 64)
+	}
+	return f
+}
+
+func readWorkoutForm(r *http.Request) workoutForm {
+	return workoutForm{
+		ShortDescription: strings.TrimSpace(r.FormValue("short_description")),
+		LongDescription:  r.FormValue("long_description"),
+		WorkoutDate:      r.FormValue("workout_date"),
+		StartTime:        strings.TrimSpace(r.FormValue("start_time")),
+		DurationMinutes:  strings.TrimSpace(r.FormValue("duration_minutes")),
+		RPE:              strings.TrimSpace(r.FormValue("rpe")),
+		SessionLoad:      strings.TrimSpace(r.FormValue("session_load")),
+	}
+}
+
+// validates the form and converts it into a workout owned by u. a start time is read in u's time zone
+func (f workoutForm) toWorkout(u *flexcreek.User) (*flexcreek.Workout, error) {
+	if f.ShortDescription == "" {
+		return nil, errors.New("a short description is required")
+	}
+
+	date, err := time.Parse(dateInputLayout, f.WorkoutDate)
+	if err != nil {
+		return nil, errors.New("a workout date is required")
+	}
+
+	w := &flexcreek.Workout{
+		UserID:           u.ID,
+		ShortDescription: f.ShortDescription,
+		LongDescription:  f.LongDescription,
+		WorkoutDate:      date,
+	}
+
+	if f.StartTime != "" {
+		if w.StartTime, err = time.ParseInLocation(dateInputLayout+" "+timeInputLayout, f.WorkoutDate+" "+f.StartTime, u.Preferences.Location()); err != nil {
+			return nil, errors.New("start time must be a time of day like 07:30")
+		}
+	}
+
+	if f.DurationMinutes != "" {
+		if w.DurationMinutes, err = strconv.Atoi(f.DurationMinutes); err != nil || w.DurationMinutes < 0 {
+			return nil, errors.New("duration must be a whole number of minutes")
+		}
+	}
+
+	if f.RPE != "" {
+		if w.RPE, err = strconv.Atoi(f.RPE); err != nil || w.RPE < 1 || w.RPE > 10 {
+			return nil, errors.New("RPE must be between 1 and 10")
+		}
+	}
+
+	if f.SessionLoad != "" {
+		if w.SessionLoad, err = strconv.ParseFloat(f.SessionLoad, 64); err != nil || w.SessionLoad < 0 {
+			return nil, errors.New("session load must be a positive number")
+		}
+	}
+
+	return w, nil
+}
+
+func workoutsURL(userID int) string {
+	return fmt.Sprintf("/users/%d/workouts", userID)
+}
+
+func workoutURL(userID int, workoutID int) string {
+	return fmt.Sprintf("/users/%d/workouts/%d", userID, workoutID)
+}
+
+// user picker
+
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.store.GetAllUsers(r.Context())
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	s.render(w, http.StatusOK, "users.html", usersPage{Users: users})
+}
+
+func (s *Server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimSpace(r.FormValue("username"))
+	if username == "" {
+		s.renderUsersError(w, r, "a username is required")
+		return
+	}
+
+	id, err := s.store.CreateUser(r.Context(), username)
+	if errors.Is(err, flexcreek.ErrNameInTrash) {
+		s.renderUsersError(w, r, "that username belongs to a user in the trash, who can be restored from the terminal ui")
+		return
+	}
+	if errors.Is(err, flexcreek.ErrConflict) {
+		s.renderUsersError(w, r, "that username is already taken")
+		return
+	}
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, workoutsURL(id), http.StatusSeeOther)
+}
+
+// redisplays the user picker with a validation message
+func (s *Server) renderUsersError(w http.ResponseWriter, r *http.Request, msg string) {
+	users, err := s.store.GetAllUsers(r.Context())
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	s.render(w, http.StatusBadRequest, "users.html", usersPage{Users: users, Error: msg})
+}
+
+// workouts
+
+func (s *Server) handleWorkouts(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	//ask for one extra row to know whether there's a next page
+	f := flexcreek.WorkoutFilter{
+		Query:  query,
+		Limit:  pageSize + 1,
+		Offset: (page - 1) * pageSize,
+	}
+	workouts, err := s.store.ListWorkouts(r.Context(), f, u.ID)
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	data := workoutsPage{User: u, Query: query, Page: page}
+	if len(workouts) > pageSize {
+		workouts = workouts[:pageSize]
+		data.NextPage = page + 1
+	}
+	if page > 1 {
+		data.PrevPage = page - 1
+	}
+	for _, wo := range workouts {
+		data.Workouts = append(data.Workouts, newWorkoutView(wo, u.Preferences))
+	}
+
+	s.render(w, http.StatusOK, "workouts.html", data)
+}
+
+func (s *Server) handleWorkout(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	wo, err := s.store.GetWorkoutByID(r.Context(), id, u.ID)
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	s.render(w, http.StatusOK, "workout.html", workoutPage{User: u, Workout: newWorkoutView(wo, u.Preferences)})
+}
+
+func (s *Server) handleNewWorkout(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	s.render(w, http.StatusOK, "form.html", formPage{
+		User:   u,
+		Action: workoutsURL(u.ID),
+		Title:  "New Workout",
+		Form:   workoutForm{WorkoutDate: u.Preferences.Today().Format(dateInputLayout)},
+	})
+}
+
+func (s *Server) handleCreateWorkout(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	form := readWorkoutForm(r)
+	wo, err := form.toWorkout(u)
+	if err != nil {
+		s.render(w, http.StatusBadRequest, "form.html", formPage{
+			User:   u,
+			Action: workoutsURL(u.ID),
+			Title:  "New Workout",
+			Form:   form,
+			Error:  err.Error(),
+		})
+		return
+	}
+
+	id, err := s.store.CreateWorkout(r.Context(), wo)
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, workoutURL(u.ID, id), http.StatusSeeOther)
+}
+
+func (s *Server) handleEditWorkout(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	wo, err := s.store.GetWorkoutByID(r.Context(), id, u.ID)
+	if err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	s.render(w, http.StatusOK, "form.html", formPage{
+		User:   u,
+		Action: workoutURL(u.ID, id),
+		Title:  "Edit Workout",
+		Form:   newWorkoutForm(wo),
+	})
+}
+
+func (s *Server) handleUpdateWorkout(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	form := readWorkoutForm(r)
+	wo, err := form.toWorkout(u)
+	if err != nil {
+		s.render(w, http.StatusBadRequest, "form.html", formPage{
+			User:   u,
+			Action: workoutURL(u.ID, id),
+			Title:  "Edit Workout",
+			Form:   form,
+			Error:  err.Error(),
+		})
+		return
+	}
+	wo.ID = id
+
+	if err := s.store.UpdateWorkout(r.Context(), wo); err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, workoutURL(u.ID, id), http.StatusSeeOther)
+}
+
+func (s *Server) handleDeleteWorkout(w http.ResponseWriter, r *http.Request) {
+	u, ok := s.pathUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r, "workoutID")
+	if !ok {
+		return
+	}
+
+	if err := s.store.DeleteWorkout(r.Context(), id, u.ID); err != nil {
+		s.renderError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, workoutsURL(u.ID), http.StatusSeeOther)
+}
@@ -0,0 +1,159 @@
+// Package web serves a server-rendered HTML interface over the same store as the TUI.
+// everything is plain html/template and forms, so there's no javascript build step
+package web
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ekholme/flexcreek"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+//go:embed static
+var staticFS embed.FS
+
+// page size for the workout list
+const pageSize = 25
+
+// defining the store operations the web ui requires
+type UserStore interface {
+	CreateUser(ctx context.Context, username string) (int, error)
+	GetUserByID(ctx context.Context, id int) (*flexcreek.User, error)
+	GetAllUsers(ctx context.Context) ([]*flexcreek.User, error)
+}
+
+type WorkoutStore interface {
+	CreateWorkout(ctx context.Context, w *flexcreek.Workout) (int, error)
+	GetWorkoutByID(ctx context.Context, id int, userID int) (*flexcreek.Workout, error)
+	ListWorkouts(ctx context.Context, f flexcreek.WorkoutFilter, userID int) ([]*flexcreek.Workout, error)
+	UpdateWorkout(ctx context.Context, w *flexcreek.Workout) error
+	DeleteWorkout(ctx context.Context, id int, userID int) error
+}
+
+type Store interface {
+	UserStore
+	WorkoutStore
+}
+
+// Server is an http.Handler for the web ui. it has no logins, so it's only meant to be reached from
+// the machine it runs on, and every form post from another site is refused
+type Server struct {
+	store     Store
+	mux       *http.ServeMux
+	handler   http.Handler // the mux behind the cross-origin check
+	templates map[string]*template.Template
+}
+
+func NewServer(s Store) (*Server, error) {
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &Server{
+		store:     s,
+		mux:       http.NewServeMux(),
+		templates: templates,
+	}
+	srv.routes()
+	srv.handler = http.NewCrossOriginProtection().Handler(srv.mux)
+
+	return srv, nil
+}
+
+func (s *Server) routes() {
+	static, _ := fs.Sub(staticFS, "static")
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+
+	s.mux.HandleFunc("GET /{$}", s.handleUsers)
+	s.mux.HandleFunc("POST /users", s.handleCreateUser)
+
+	s.mux.HandleFunc("GET /users/{userID}/workouts", s.handleWorkouts)
+	s.mux.HandleFunc("GET /users/{userID}/workouts/new", s.handleNewWorkout)
+	s.mux.HandleFunc("POST /users/{userID}/workouts", s.handleCreateWorkout)
+	s.mux.HandleFunc("GET /users/{userID}/workouts/{workoutID}", s.handleWorkout)
+	s.mux.HandleFunc("GET /users/{userID}/workouts/{workoutID}/edit", s.handleEditWorkout)
+	s.mux.HandleFunc("POST /users/{userID}/workouts/{workoutID}", s.handleUpdateWorkout)
+	s.mux.HandleFunc("POST /users/{userID}/workouts/{workoutID}/delete", s.handleDeleteWorkout)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// every page is parsed alongside the shared layout so each gets its own "content" block
+func parseTemplates() (map[string]*template.Template, error) {
+	pages := []string{"users.html", "workouts.html", "workout.html", "form.html"}
+
+	templates := make(map[string]*template.Template, len(pages))
+	for _, p := range pages {
+		t, err := template.New("layout.html").ParseFS(templateFS, "templates/layout.html", "templates/"+p)
+		if err != nil {
+			return nil, err
+		}
+		templates[p] = t
+	}
+
+	return templates, nil
+}
+
+// renders a page into a buffer first so template errors don't leave a half written response
+func (s *Server) render(w http.ResponseWriter, status int, page string, data any) {
+	var buf bytes.Buffer
+	if err := s.templates[page].Execute(&buf, data); err != nil {
+		log.Printf("web: rendering %s: %v", page, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+func (s *Server) renderError(w http.ResponseWriter, err error) {
+	if errors.Is(err, flexcreek.ErrNotFound) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	log.Printf("web: %v", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
+
+// parses a positive integer path parameter, writing a 404 if it's invalid
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		http.NotFound(w, r)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// looks up the user in the path
+func (s *Server) pathUser(w http.ResponseWriter, r *http.Request) (*flexcreek.User, bool) {
+	id, ok := pathID(w, r, "userID")
+	if !ok {
+		return nil, false
+	}
+
+	u, err := s.store.GetUserByID(r.Context(), id)
+	if err != nil {
+		s.renderError(w, err)
+		return nil, false
+	}
+
+	return u, true
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ekholme/flexcreek"
+)
+
+// fakeStore records the users it's asked to create. the workout methods aren't reached by these tests
+type fakeStore struct {
+	Store
+	created []string
+}
+
+func (s *fakeStore) CreateUser(ctx context.Context, username string) (int, error) {
+	s.created = append(s.created, username)
+	return len(s.created), nil
+}
+
+func (s *fakeStore) GetAllUsers(ctx context.Context) ([]*flexcreek.User, error) {
+	return nil, nil
+}
+
+func TestCrossOriginPostsRefused(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"same origin", http.Header{"Sec-Fetch-Site": {"same-origin"}}, http.StatusSeeOther},
+		{"typed into the browser", http.Header{"Sec-Fetch-Site": {"none"}}, http.StatusSeeOther},
+		{"another site", http.Header{"Sec-Fetch-Site": {"cross-site"}}, http.StatusForbidden},
+		{"another origin without fetch metadata", http.Header{"Origin": {"http://evil.example"}}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &fakeStore{}
+			srv, err := NewServer(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			form := url.Values{"username": {"mallory"}}
+			r := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:8081/users", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d", w.Code, tt.want)
+			}
+			if created := len(s.created) > 0; created != (tt.want == http.StatusSeeOther) {
+				t.Errorf("users created: %v", s.created)
+			}
+		})
+	}
+}
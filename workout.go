@@ -6,9 +6,57 @@ import (
 
 type Workout struct {
 	ID               int       `db:"id"`
+	UUID             string    `db:"uuid"` // stable across databases, unlike ID
 	UserID           int       `db:"user_id"`
 	ShortDescription string    `db:"short_description"`
 	LongDescription  string    `db:"long_description"`
-	WorkoutDate      time.Time `db:"workout_date"`
-	CreatedAt        time.Time `db:"created_at"`
+	WorkoutDate      time.Time `db:"workout_date"` // a calendar date, see CivilDate
+	StartTime        time.Time `db:"start_time"`   // optional, in the zone the workout happened in. zero if not recorded
+	DurationMinutes  int       `db:"duration_minutes"`
+	RPE              int       `db:"rpe"`
+	SessionLoad      float64   `db:"session_load"`
+	CreatedAt        time.Time `db:"created_at"` // UTC
+	DeletedAt        time.Time `db:"deleted_at"` // zero unless the workout is in the trash
+}
+
+// CivilDate returns the calendar date of t in t's own zone, as midnight UTC.
+// workout dates are kept in this form so they never shift a day when viewed from a different zone
+func CivilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WorkoutFilter narrows and pages a workout listing.
+// zero values mean no bound, and a Limit of 0 returns every match
+type WorkoutFilter struct {
+	From   time.Time
+	To     time.Time
+	Query  string // case-insensitive match against either description
+	Limit  int
+	Offset int
+}
+
+// Load returns the session load for the workout.
+// an explicitly entered SessionLoad wins; otherwise it's derived as duration x RPE (session-RPE)
+func (w *Workout) Load() float64 {
+	if w.SessionLoad > 0 {
+		return w.SessionLoad
+	}
+
+	return float64(w.DurationMinutes * w.RPE)
+}
+
+// CopyTo returns a copy of the workout as a new, unsaved workout on date. the start time keeps its
+// time of day, and nothing tied to the original (its ID, UUID, created time or history) comes along
+func (w *Workout) CopyTo(date time.Time) *Workout {
+	c := *w
+	c.ID, c.UUID = 0, ""
+	c.CreatedAt, c.DeletedAt = time.Time{}, time.Time{}
+	c.WorkoutDate = CivilDate(date)
+
+	if !w.StartTime.IsZero() {
+		s := w.StartTime
+		c.StartTime = time.Date(date.Year(), date.Month(), date.Day(), s.Hour(), s.Minute(), s.Second(), 0, s.Location())
+	}
+
+	return &c
 }